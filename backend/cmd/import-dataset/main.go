@@ -0,0 +1,129 @@
+// Command import-dataset imports a labeled COCO or YOLO dataset from a local
+// directory or zip archive. Every image becomes a job that is queued for
+// detection, and its labels are stored as ground-truth annotations.
+//
+// Usage:
+//
+//	import-dataset -format coco|yolo [-name NAME] [-config DIR] PATH
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/dataset"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/tiling"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	format := flag.String("format", "", "dataset format: coco or yolo")
+	name := flag.String("name", "", "name of the import (default: base name of PATH)")
+	configPath := flag.String("config", ".", "directory containing app.env")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -format coco|yolo [-name NAME] [-config DIR] PATH\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 || (*format != dataset.FormatCOCO && *format != dataset.FormatYOLO) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	source := flag.Arg(0)
+	if *name == "" {
+		*name = strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	}
+
+	fsys, closeSource, err := openDataset(source)
+	if err != nil {
+		log.Fatal("Failed to open dataset:", err)
+	}
+	defer closeSource()
+
+	config, err := utils.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+	serverSource := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s", config.Database.Host, config.Database.Port, config.Database.User, config.Database.Password, config.Database.DBName, config.Database.SSLMode)
+
+	storageClient, err := storage.NewS3Client(config.Cloudflare)
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+
+	connDB, err := sql.Open(utils.DBDriver, serverSource)
+	if err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer connDB.Close()
+	store := db.NewStore(connDB)
+
+	queueClient, err := queue.NewRabbitMQClient(config.RabbitMQ)
+	if err != nil {
+		log.Fatal("Failed to initialize message queue:", err)
+	}
+	defer queueClient.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Imports from the command have no archive in storage, so the server never claims them
+	datasetImport, err := store.CreateDatasetImport(ctx, db.CreateDatasetImportParams{
+		ID:     uuid.New(),
+		Status: dataset.StatusProcessing,
+		Format: *format,
+		Name:   *name,
+	})
+	if err != nil {
+		log.Fatal("Failed to create dataset import:", err)
+	}
+
+	// Tiled images are split by the server consuming the split queue
+	var tiler *tiling.Tiler
+	if config.Tiling.Enabled {
+		tiler = tiling.NewTiler(config.Tiling, store, storageClient, queueClient)
+	}
+	detectionParams := models.DetectionParams{ModelVersion: config.Detection.ModelVersion}
+	importer := dataset.NewImporter(config.Import, detectionParams, store, storageClient, queueClient, tiler)
+	result, importErr := importer.Import(ctx, datasetImport.ID, fsys, *format)
+	importer.Finish(context.Background(), datasetImport.ID, result, importErr)
+
+	fmt.Printf("Import %s: %d images, %d annotations, %d skipped\n", datasetImport.ID, result.Images, result.Annotations, result.Skipped)
+	if importErr != nil {
+		log.Fatal("Import failed:", importErr)
+	}
+}
+
+// openDataset opens a dataset directory or zip archive
+func openDataset(source string) (fs.FS, func(), error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		return os.DirFS(source), func() {}, nil
+	}
+
+	archive, err := zip.OpenReader(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	return archive, func() { archive.Close() }, nil
+}
@@ -0,0 +1,61 @@
+// Command worker serves the detection queue with the Go template-matching
+// detector. It consumes the same job messages as the Python worker and
+// publishes the same results, so either can run, or both side by side.
+//
+// Usage:
+//
+//	worker [-config DIR]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/worker"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	configPath := flag.String("config", ".", "directory containing app.env")
+	flag.Parse()
+
+	config, err := utils.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+
+	templates, err := worker.LoadTemplates(config.Worker.TemplateDir)
+	if err != nil {
+		log.Fatal("Failed to load templates:", err)
+	}
+
+	storageClient, err := storage.NewS3Client(config.Cloudflare)
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+
+	queueClient, err := queue.NewRabbitMQClient(config.RabbitMQ)
+	if err != nil {
+		log.Fatal("Failed to initialize message queue:", err)
+	}
+	defer queueClient.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	detector := worker.NewTemplateDetector(templates, config.Worker.ModelVersion)
+	w := worker.NewWorker(config.Worker, detector, worker.CropExtractor{}, storageClient, queueClient)
+	if err := queueClient.ConsumeJobs(w.Handle); err != nil {
+		log.Fatal("Failed to consume detection jobs:", err)
+	}
+
+	logrus.WithField("templates", len(templates)).Info("Worker is ready, waiting for jobs")
+	<-ctx.Done()
+}
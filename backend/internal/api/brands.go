@@ -0,0 +1,331 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/brands"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+type brandRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type brandResponse struct {
+	ID          int64                    `json:"id"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description,omitempty"`
+	CreatedAt   string                   `json:"created_at"`
+	UpdatedAt   string                   `json:"updated_at"`
+	References  []brandReferenceResponse `json:"references,omitempty"`
+}
+
+type brandReferenceResponse struct {
+	ID        int64  `json:"id"`
+	BrandID   int64  `json:"brand_id"`
+	S3Key     string `json:"s3_key"`
+	ImageURL  string `json:"image_url,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+func newBrandResponse(brand db.Brand) brandResponse {
+	return brandResponse{
+		ID:          brand.ID,
+		Name:        brand.Name,
+		Description: brand.Description.String,
+		CreatedAt:   brand.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   brand.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (s *Server) newBrandReferenceResponse(ctx *gin.Context, reference db.BrandReference) brandReferenceResponse {
+	imageURL, err := s.storageClient.GetPresignedGetURL(ctx.Request.Context(), reference.S3Key, time.Hour)
+	if err != nil {
+		logrus.WithError(err).WithField("s3_key", reference.S3Key).Warn("Failed to get presigned URL for brand reference")
+	}
+	return brandReferenceResponse{
+		ID:        reference.ID,
+		BrandID:   reference.BrandID,
+		S3Key:     reference.S3Key,
+		ImageURL:  imageURL,
+		CreatedAt: reference.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (s *Server) CreateBrand(ctx *gin.Context) {
+	var req brandRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid request"))
+		return
+	}
+
+	brand, err := s.store.CreateBrand(ctx.Request.Context(), db.CreateBrandParams{
+		Name:        strings.TrimSpace(req.Name),
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, gin.H{"success": false, "error": "A brand with this name already exists"})
+			return
+		}
+		logrus.WithError(err).Error("Failed to create brand")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to create brand"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"success": true, "brand": newBrandResponse(brand)})
+}
+
+func (s *Server) ListBrands(ctx *gin.Context) {
+	brandRows, err := s.store.ListBrands(ctx.Request.Context())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list brands")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list brands"})
+		return
+	}
+
+	brandList := make([]brandResponse, 0, len(brandRows))
+	for _, brand := range brandRows {
+		brandList = append(brandList, newBrandResponse(brand))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "brands": brandList})
+}
+
+func (s *Server) GetBrand(ctx *gin.Context) {
+	brand, ok := s.findBrand(ctx)
+	if !ok {
+		return
+	}
+
+	references, err := s.store.ListBrandReferencesByBrand(ctx.Request.Context(), brand.ID)
+	if err != nil {
+		logrus.WithError(err).WithField("brand_id", brand.ID).Error("Failed to list brand references")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get brand"})
+		return
+	}
+
+	response := newBrandResponse(brand)
+	response.References = make([]brandReferenceResponse, 0, len(references))
+	for _, reference := range references {
+		response.References = append(response.References, s.newBrandReferenceResponse(ctx, reference))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "brand": response})
+}
+
+func (s *Server) UpdateBrand(ctx *gin.Context) {
+	brandID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid brand ID"})
+		return
+	}
+
+	var req brandRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid request"))
+		return
+	}
+
+	brand, err := s.store.UpdateBrand(ctx.Request.Context(), db.UpdateBrandParams{
+		ID:          brandID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Brand not found"})
+		case isUniqueViolation(err):
+			ctx.JSON(http.StatusConflict, gin.H{"success": false, "error": "A brand with this name already exists"})
+		default:
+			logrus.WithError(err).WithField("brand_id", brandID).Error("Failed to update brand")
+			ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to update brand"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "brand": newBrandResponse(brand)})
+}
+
+func (s *Server) DeleteBrand(ctx *gin.Context) {
+	brand, ok := s.findBrand(ctx)
+	if !ok {
+		return
+	}
+
+	references, err := s.store.ListBrandReferencesByBrand(ctx.Request.Context(), brand.ID)
+	if err != nil {
+		logrus.WithError(err).WithField("brand_id", brand.ID).Error("Failed to list brand references")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to delete brand"})
+		return
+	}
+
+	// References are removed by the cascade, logos keep their row without a brand
+	if err := s.store.DeleteBrand(ctx.Request.Context(), brand.ID); err != nil {
+		logrus.WithError(err).WithField("brand_id", brand.ID).Error("Failed to delete brand")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to delete brand"})
+		return
+	}
+
+	keys := make([]string, 0, len(references))
+	for _, reference := range references {
+		keys = append(keys, reference.S3Key)
+	}
+	s.purger.DeleteObjects(ctx.Request.Context(), keys...)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"brand_id": brand.ID,
+		"message":  "Brand deleted successfully",
+	})
+}
+
+func (s *Server) AddBrandReference(ctx *gin.Context) {
+	brand, ok := s.findBrand(ctx)
+	if !ok {
+		return
+	}
+
+	file, header, err := ctx.Request.FormFile("image")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "No image file provided"})
+		return
+	}
+	defer file.Close()
+
+	contentType := header.Header.Get("Content-Type")
+	if !slices.Contains(AllowedTypes, contentType) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid file type. Only JPEG and PNG are allowed"})
+		return
+	}
+	if header.Size > s.config.Server.MaxFileSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"success": false, "error": fmt.Sprintf("File too large. Maximum size is %d bytes", s.config.Server.MaxFileSize),
+		})
+		return
+	}
+
+	if _, err := imaging.CheckPixels(file, s.config.Server.MaxImagePixels); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid image file"))
+		return
+	}
+
+	// The image is needed twice, once for its features and once for storage
+	data, err := io.ReadAll(file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Failed to read image"})
+		return
+	}
+	img, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Failed to decode image"})
+		return
+	}
+	features := brands.ExtractFeatures(img)
+
+	s3Key := fmt.Sprintf("brands/%d/%s%s", brand.ID, uuid.New().String(), strings.ToLower(path.Ext(header.Filename)))
+	if _, err := s.storageClient.UploadFile(ctx.Request.Context(), s3Key, bytes.NewReader(data), int64(len(data))); err != nil {
+		logrus.WithError(err).Error("Failed to upload brand reference to S3")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to upload file"})
+		return
+	}
+
+	reference, err := s.store.CreateBrandReference(ctx.Request.Context(), db.CreateBrandReferenceParams{
+		BrandID:        brand.ID,
+		S3Key:          s3Key,
+		Dhash:          int64(features.DHash),
+		Phash:          int64(features.PHash),
+		ColorHistogram: features.Histogram,
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("brand_id", brand.ID).Error("Failed to create brand reference")
+		s.purger.DeleteObjects(ctx.Request.Context(), s3Key)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to add brand reference"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"success": true, "reference": s.newBrandReferenceResponse(ctx, reference)})
+}
+
+func (s *Server) DeleteBrandReference(ctx *gin.Context) {
+	brandID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid brand ID"})
+		return
+	}
+	referenceID, err := strconv.ParseInt(ctx.Param("reference_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid reference ID"})
+		return
+	}
+
+	reference, err := s.store.GetBrandReference(ctx.Request.Context(), referenceID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logrus.WithError(err).WithField("reference_id", referenceID).Error("Failed to get brand reference")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to delete brand reference"})
+		return
+	}
+	if err != nil || reference.BrandID != brandID {
+		ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Brand reference not found"})
+		return
+	}
+
+	if err := s.store.DeleteBrandReference(ctx.Request.Context(), referenceID); err != nil {
+		logrus.WithError(err).WithField("reference_id", referenceID).Error("Failed to delete brand reference")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to delete brand reference"})
+		return
+	}
+	s.purger.DeleteObjects(ctx.Request.Context(), reference.S3Key)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"reference_id": referenceID,
+		"message":      "Brand reference deleted successfully",
+	})
+}
+
+// findBrand loads the brand named by the :id parameter and writes the error
+// response when it cannot
+func (s *Server) findBrand(ctx *gin.Context) (db.Brand, bool) {
+	brandID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid brand ID"})
+		return db.Brand{}, false
+	}
+
+	brand, err := s.store.GetBrand(ctx.Request.Context(), brandID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Brand not found"})
+			return db.Brand{}, false
+		}
+		logrus.WithError(err).WithField("brand_id", brandID).Error("Failed to get brand")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get brand"})
+		return db.Brand{}, false
+	}
+
+	return brand, true
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/composition"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type placementRequest struct {
+	LogoID   int64    `json:"logo_id" binding:"required,min=1"`
+	X        *float64 `json:"x" binding:"required"`
+	Y        *float64 `json:"y" binding:"required"`
+	Scale    *float64 `json:"scale" binding:"omitempty,gt=0,lte=20"`
+	Rotation float64  `json:"rotation"`
+	Opacity  *float64 `json:"opacity" binding:"omitempty,gte=0,lte=1"`
+	Feather  *float64 `json:"feather" binding:"omitempty,gte=0"`
+}
+
+type compositionRequest struct {
+	Placements []placementRequest `binding:"required,min=1,max=20,dive"`
+}
+
+type compositionResponse struct {
+	ID          string             `json:"id"`
+	Status      string             `json:"status"`
+	Placements  []models.Placement `json:"placements"`
+	Error       string             `json:"error,omitempty"`
+	DownloadURL string             `json:"download_url,omitempty"`
+	CreatedAt   string             `json:"created_at"`
+	CompletedAt string             `json:"completed_at,omitempty"`
+}
+
+func (s *Server) newCompositionResponse(ctx *gin.Context, c db.Composition) compositionResponse {
+	response := compositionResponse{
+		ID:         c.ID.String(),
+		Status:     c.Status,
+		Placements: []models.Placement{},
+		Error:      c.ErrorMessage.String,
+		CreatedAt:  c.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if err := json.Unmarshal(c.Placements, &response.Placements); err != nil {
+		logrus.WithError(err).WithField("composition_id", c.ID).Warn("Failed to decode composition placements")
+	}
+	if c.CompletedAt.Valid {
+		response.CompletedAt = c.CompletedAt.Time.UTC().Format(time.RFC3339)
+	}
+	if c.Status == models.CompositionStatusCompleted && c.S3Key.Valid {
+		downloadURL, err := s.storageClient.GetPresignedGetURL(ctx.Request.Context(), c.S3Key.String, time.Hour)
+		if err != nil {
+			logrus.WithError(err).WithField("s3_key", c.S3Key.String).Warn("Failed to get presigned URL for composite")
+		}
+		response.DownloadURL = downloadURL
+	}
+	return response
+}
+
+// CreateComposition stores a target image and queues placing logos onto it
+func (s *Server) CreateComposition(ctx *gin.Context) {
+	file, header, err := ctx.Request.FormFile("image")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "No image file provided"})
+		return
+	}
+	defer file.Close()
+
+	if !slices.Contains(AllowedTypes, header.Header.Get("Content-Type")) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid file type. Only JPEG and PNG are allowed"})
+		return
+	}
+	if header.Size > s.config.Server.MaxFileSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"success": false, "error": fmt.Sprintf("File too large. Maximum size is %d bytes", s.config.Server.MaxFileSize),
+		})
+		return
+	}
+	if _, err := imaging.CheckPixels(file, s.config.Server.MaxImagePixels); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid image file"))
+		return
+	}
+
+	var req compositionRequest
+	if err := json.Unmarshal([]byte(ctx.PostForm("placements")), &req.Placements); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "placements must be a JSON array"})
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid placements"))
+		return
+	}
+
+	placements := make([]models.Placement, 0, len(req.Placements))
+	for _, p := range req.Placements {
+		if _, err := s.store.GetLogo(ctx.Request.Context(), p.LogoID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": fmt.Sprintf("Logo %d not found", p.LogoID)})
+				return
+			}
+			logrus.WithError(err).WithField("logo_id", p.LogoID).Error("Failed to get logo")
+			ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get logo"})
+			return
+		}
+
+		placement := models.Placement{
+			LogoID:   p.LogoID,
+			X:        *p.X,
+			Y:        *p.Y,
+			Scale:    1,
+			Rotation: p.Rotation,
+			Opacity:  1,
+			Feather:  s.config.Composition.Feather,
+		}
+		if p.Scale != nil {
+			placement.Scale = *p.Scale
+		}
+		if p.Opacity != nil {
+			placement.Opacity = *p.Opacity
+		}
+		if p.Feather != nil {
+			placement.Feather = *p.Feather
+		}
+		placements = append(placements, placement)
+	}
+	placementsJSON, err := json.Marshal(placements)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal placements")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to create composition"})
+		return
+	}
+
+	compositionID := uuid.New()
+	targetKey := composition.TargetKey(compositionID, header.Filename)
+	if _, err := s.storageClient.UploadFile(context.Background(), targetKey, file, header.Size); err != nil {
+		logrus.WithError(err).Error("Failed to upload composition target")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to upload file"})
+		return
+	}
+
+	created, err := s.store.CreateComposition(context.Background(), db.CreateCompositionParams{
+		ID:         compositionID,
+		TargetKey:  targetKey,
+		Placements: placementsJSON,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create composition")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to create composition"})
+		return
+	}
+
+	if err := s.queueClient.PublishComposition(&models.CompositionMessage{CompositionID: compositionID}); err != nil {
+		logrus.WithError(err).Error("Failed to publish composition to queue")
+		_, failErr := s.store.FailComposition(context.Background(), db.FailCompositionParams{
+			ID:           compositionID,
+			ErrorMessage: sql.NullString{String: "Failed to queue composition", Valid: true},
+		})
+		if failErr != nil {
+			logrus.WithError(failErr).Error("Failed to update composition status after queue publish failure")
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to queue composition"})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"success": true, "composition": s.newCompositionResponse(ctx, created)})
+}
+
+// ListCompositions lists compositions, newest first
+func (s *Server) ListCompositions(ctx *gin.Context) {
+	var query pageQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	compositions, err := s.store.ListCompositions(ctx.Request.Context(), db.ListCompositionsParams{
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list compositions")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list compositions"})
+		return
+	}
+
+	results := make([]compositionResponse, 0, len(compositions))
+	for _, c := range compositions {
+		results = append(results, s.newCompositionResponse(ctx, c))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "compositions": results})
+}
+
+// GetComposition returns the status of a composition and a download link
+// once it is completed
+func (s *Server) GetComposition(ctx *gin.Context) {
+	compositionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid composition ID"})
+		return
+	}
+
+	c, err := s.store.GetComposition(ctx.Request.Context(), compositionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Composition not found"})
+			return
+		}
+		logrus.WithError(err).WithField("composition_id", compositionID).Error("Failed to get composition")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get composition"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "composition": s.newCompositionResponse(ctx, c)})
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/eval"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// maxEvaluationJobs bounds the job set of a single evaluation
+const maxEvaluationJobs = 10000
+
+type evaluationRequest struct {
+	JobIDs   []uuid.UUID `json:"job_ids" binding:"omitempty,max=10000"`
+	ImportID *uuid.UUID  `json:"import_id"`
+	// GroundTruth is a COCO annotation file whose images are named after jobs
+	GroundTruth  json.RawMessage `json:"ground_truth"`
+	IoUThreshold *float64        `json:"iou_threshold" binding:"omitempty,gte=0.05,lte=0.95"`
+}
+
+// CreateEvaluation scores the stored detections of a job set against ground
+// truth, either posted as COCO JSON or imported earlier, per logo type and
+// model version
+func (s *Server) CreateEvaluation(ctx *gin.Context) {
+	var req evaluationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid request"))
+		return
+	}
+
+	sources := 0
+	for _, given := range []bool{len(req.JobIDs) > 0, req.ImportID != nil, len(req.GroundTruth) > 0} {
+		if given {
+			sources++
+		}
+	}
+	if sources != 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Provide exactly one of job_ids, import_id or ground_truth"})
+		return
+	}
+
+	iouThreshold := eval.DefaultIoUThreshold
+	if req.IoUThreshold != nil {
+		iouThreshold = *req.IoUThreshold
+	}
+
+	requestCtx := ctx.Request.Context()
+	jobIDs := req.JobIDs
+	var groundTruths []eval.GroundTruth
+	switch {
+	case len(req.GroundTruth) > 0:
+		var err error
+		groundTruths, jobIDs, err = eval.ParseCOCO(req.GroundTruth)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ground_truth: " + err.Error()})
+			return
+		}
+		if len(jobIDs) > maxEvaluationJobs {
+			ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "ground_truth has too many images"})
+			return
+		}
+	case req.ImportID != nil:
+		jobs, err := s.store.ListJobsByImport(requestCtx, uuid.NullUUID{UUID: *req.ImportID, Valid: true})
+		if err != nil {
+			logrus.WithError(err).WithField("import_id", req.ImportID).Error("Failed to list imported jobs")
+			ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list jobs"})
+			return
+		}
+		jobIDs = make([]uuid.UUID, 0, len(jobs))
+		for _, job := range jobs {
+			jobIDs = append(jobIDs, job.ID)
+		}
+	}
+	if len(jobIDs) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "No jobs to evaluate"})
+		return
+	}
+
+	if groundTruths == nil {
+		var err error
+		groundTruths, err = eval.LoadGroundTruth(requestCtx, s.store, jobIDs)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to load ground truth")
+			ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to load ground truth"})
+			return
+		}
+	}
+
+	result, err := eval.EvaluateJobs(requestCtx, s.store, jobIDs, groundTruths, iouThreshold)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to evaluate jobs")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to evaluate jobs"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"iou_threshold": iouThreshold,
+		"models":        result.Models,
+		"skipped_jobs":  result.SkippedJobs,
+	})
+}
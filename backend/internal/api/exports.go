@@ -0,0 +1,158 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/dataset"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type exportRequest struct {
+	Format string `json:"format" binding:"required,oneof=coco yolo voc"`
+	// ValFraction is the share of images in the validation split
+	ValFraction *float64 `json:"val_fraction" binding:"omitempty,gte=0,lt=1"`
+	dataset.Filters
+}
+
+type pageQuery struct {
+	Limit  int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int32 `form:"offset" binding:"omitempty,min=0"`
+}
+
+type exportResponse struct {
+	ID               string          `json:"id"`
+	Status           string          `json:"status"`
+	Format           string          `json:"format"`
+	Filters          json.RawMessage `json:"filters"`
+	ValFraction      float64         `json:"val_fraction"`
+	ImagesCount      int32           `json:"images_count"`
+	AnnotationsCount int32           `json:"annotations_count"`
+	Error            string          `json:"error,omitempty"`
+	DownloadURL      string          `json:"download_url,omitempty"`
+	CreatedAt        string          `json:"created_at"`
+	CompletedAt      string          `json:"completed_at,omitempty"`
+}
+
+func (s *Server) newExportResponse(ctx *gin.Context, export db.DatasetExport) exportResponse {
+	response := exportResponse{
+		ID:               export.ID.String(),
+		Status:           export.Status,
+		Format:           export.Format,
+		Filters:          export.Filters,
+		ValFraction:      export.ValFraction,
+		ImagesCount:      export.ImagesCount,
+		AnnotationsCount: export.AnnotationsCount,
+		Error:            export.ErrorMessage.String,
+		CreatedAt:        export.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if export.CompletedAt.Valid {
+		response.CompletedAt = export.CompletedAt.Time.UTC().Format(time.RFC3339)
+	}
+	if export.Status == dataset.StatusCompleted && export.S3Key.Valid {
+		downloadURL, err := s.storageClient.GetPresignedGetURL(ctx.Request.Context(), export.S3Key.String, s.config.Export.URLExpiry)
+		if err != nil {
+			logrus.WithError(err).WithField("s3_key", export.S3Key.String).Warn("Failed to get presigned URL for dataset export")
+		}
+		response.DownloadURL = downloadURL
+	}
+	return response
+}
+
+// CreateExport queues a dataset export of the logos matching the filters
+func (s *Server) CreateExport(ctx *gin.Context) {
+	var req exportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid request"))
+		return
+	}
+	if req.MinConfidence != nil && (*req.MinConfidence < 0 || *req.MinConfidence > 1) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "min_confidence must be between 0 and 1"})
+		return
+	}
+	if req.CreatedAfter != nil && req.CreatedBefore != nil && !req.CreatedAfter.Before(*req.CreatedBefore) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "created_after must be before created_before"})
+		return
+	}
+
+	valFraction := s.config.Export.ValFraction
+	if req.ValFraction != nil {
+		valFraction = *req.ValFraction
+	}
+	filters, err := json.Marshal(req.Filters)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid filters"))
+		return
+	}
+
+	export, err := s.store.CreateDatasetExport(ctx.Request.Context(), db.CreateDatasetExportParams{
+		ID:          uuid.New(),
+		Format:      req.Format,
+		Filters:     filters,
+		ValFraction: valFraction,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create dataset export")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to create export"})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"success": true, "export": s.newExportResponse(ctx, export)})
+}
+
+// ListExports lists dataset exports, newest first
+func (s *Server) ListExports(ctx *gin.Context) {
+	var query pageQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	exports, err := s.store.ListDatasetExports(ctx.Request.Context(), db.ListDatasetExportsParams{
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list dataset exports")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list exports"})
+		return
+	}
+
+	results := make([]exportResponse, 0, len(exports))
+	for _, export := range exports {
+		results = append(results, s.newExportResponse(ctx, export))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "exports": results})
+}
+
+// GetExport returns an export with a download link once it is completed
+func (s *Server) GetExport(ctx *gin.Context) {
+	exportID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid export ID"})
+		return
+	}
+
+	export, err := s.store.GetDatasetExport(ctx.Request.Context(), exportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Export not found"})
+			return
+		}
+		logrus.WithError(err).WithField("export_id", exportID).Error("Failed to get dataset export")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get export"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "export": s.newExportResponse(ctx, export)})
+}
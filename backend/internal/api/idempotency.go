@@ -0,0 +1,293 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyKeyPrefix      = "logo-preserve:idempotency:"
+	maxIdempotencyKeyLength   = 255
+	idempotencyStatusRunning  = "processing"
+	idempotencyStatusFinished = "completed"
+	// multipartOverhead is the room left for multipart headers and boundaries
+	// around the largest accepted file
+	multipartOverhead = 1 << 20
+)
+
+// idempotencyRecord is what is stored in Redis for every Idempotency-Key
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      string `json:"status"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// responseRecorder keeps a copy of the response so it can be replayed
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(data string) (int, error) {
+	r.body.WriteString(data)
+	return r.ResponseWriter.WriteString(data)
+}
+
+// idempotent makes mutating requests that carry an Idempotency-Key safe to
+// retry. The first request with a key runs normally and its response is
+// stored; replays get the stored response back, and reusing the key for a
+// different request is rejected with 422. Server errors are not stored so
+// the client can retry them.
+func (s *Server) idempotent() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" || isSafeMethod(ctx.Request.Method) {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"success": false, "error": fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength),
+			})
+			return
+		}
+
+		body, err := spoolBody(ctx.Request.Body, s.config.Idempotency.MaxBodySize, s.maxIdempotentBodySize())
+		if errors.Is(err, errBodyTooLarge) {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"success": false, "error": "Request body too large"})
+			return
+		}
+		if err != nil {
+			logrus.WithError(err).Error("Failed to read request body")
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"success": false, "error": "Failed to read request body"})
+			return
+		}
+		defer body.Close()
+
+		fingerprint, err := requestFingerprint(ctx.Request, body)
+		if err == nil {
+			_, err = body.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"success": false, "error": "Malformed request body"})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(body)
+
+		requestCtx := ctx.Request.Context()
+		redisKey := idempotencyKeyPrefix + key
+
+		record, reserved, err := s.reserveIdempotencyKey(requestCtx, redisKey, fingerprint)
+		if err != nil {
+			logrus.WithError(err).WithField("idempotency_key", key).Error("Failed to reserve idempotency key")
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to process Idempotency-Key"})
+			return
+		}
+		if !reserved {
+			switch {
+			case record.Fingerprint != fingerprint:
+				ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+					"success": false, "error": "Idempotency-Key was already used with a different request",
+				})
+			case record.Status != idempotencyStatusFinished:
+				ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{
+					"success": false, "error": "A request with this Idempotency-Key is still being processed",
+				})
+			default:
+				ctx.Header(IdempotentReplayedHeader, "true")
+				ctx.Data(record.StatusCode, record.ContentType, record.Body)
+				ctx.Abort()
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		// Use a fresh context so a client disconnect does not leave the key reserved
+		storeCtx := context.Background()
+		if recorder.Status() >= http.StatusInternalServerError {
+			if err := s.redisClient.Del(storeCtx, redisKey).Err(); err != nil {
+				logrus.WithError(err).WithField("idempotency_key", key).Error("Failed to release idempotency key")
+			}
+			return
+		}
+
+		err = s.saveIdempotencyRecord(storeCtx, redisKey, idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      idempotencyStatusFinished,
+			StatusCode:  recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			logrus.WithError(err).WithField("idempotency_key", key).Error("Failed to store idempotent response")
+		}
+	}
+}
+
+// reserveIdempotencyKey claims the key for this request. When the key is
+// already taken it returns the existing record instead.
+func (s *Server) reserveIdempotencyKey(ctx context.Context, redisKey, fingerprint string) (idempotencyRecord, bool, error) {
+	pending, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, Status: idempotencyStatusRunning})
+	if err != nil {
+		return idempotencyRecord{}, false, err
+	}
+
+	reserved, err := s.redisClient.SetNX(ctx, redisKey, pending, s.config.Idempotency.LockTTL).Result()
+	if err != nil || reserved {
+		return idempotencyRecord{}, reserved, err
+	}
+
+	data, err := s.redisClient.Get(ctx, redisKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// The previous request expired or failed in between, try once more
+		reserved, err = s.redisClient.SetNX(ctx, redisKey, pending, s.config.Idempotency.LockTTL).Result()
+		if err != nil || reserved {
+			return idempotencyRecord{}, reserved, err
+		}
+		data, err = s.redisClient.Get(ctx, redisKey).Bytes()
+	}
+	if err != nil {
+		return idempotencyRecord{}, false, err
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return idempotencyRecord{}, false, err
+	}
+	return record, false, nil
+}
+
+// maxIdempotentBodySize is the largest body accepted with an Idempotency-Key:
+// the largest upload any route takes, plus room for the multipart envelope
+func (s *Server) maxIdempotentBodySize() int64 {
+	return max(s.config.Idempotency.MaxBodySize, s.config.Server.MaxFileSize, s.config.Import.MaxArchiveSize) + multipartOverhead
+}
+
+// errBodyTooLarge is returned by spoolBody for bodies over the limit
+var errBodyTooLarge = errors.New("request body too large")
+
+// spooledBody is a request body read ahead of the handler so it can be
+// fingerprinted. Small bodies stay in memory, larger ones in a temp file
+// that Close removes.
+type spooledBody struct {
+	io.ReadSeeker
+	file *os.File
+}
+
+func (b *spooledBody) Close() error {
+	if b.file == nil {
+		return nil
+	}
+	b.file.Close()
+	return os.Remove(b.file.Name())
+}
+
+// spoolBody reads a body of at most maxSize bytes, keeping up to memoryLimit
+// bytes in memory and writing larger bodies to a temp file
+func spoolBody(body io.Reader, memoryLimit, maxSize int64) (*spooledBody, error) {
+	memoryLimit = min(memoryLimit, maxSize)
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(body, memoryLimit+1))
+	if err != nil {
+		return nil, err
+	}
+	if n <= memoryLimit {
+		return &spooledBody{ReadSeeker: bytes.NewReader(buf.Bytes())}, nil
+	}
+	if n > maxSize {
+		return nil, errBodyTooLarge
+	}
+
+	file, err := os.CreateTemp("", "idempotent-body-*")
+	if err != nil {
+		return nil, err
+	}
+	spooled := &spooledBody{ReadSeeker: file, file: file}
+	written, err := io.Copy(file, io.MultiReader(&buf, io.LimitReader(body, maxSize-n+1)))
+	if err == nil && written > maxSize {
+		err = errBodyTooLarge
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		spooled.Close()
+		return nil, err
+	}
+	return spooled, nil
+}
+
+func (s *Server) saveIdempotencyRecord(ctx context.Context, redisKey string, record idempotencyRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.redisClient.Set(ctx, redisKey, data, s.config.Idempotency.TTL).Err()
+}
+
+// requestFingerprint hashes what identifies a request: method, path and
+// payload. Multipart bodies are hashed part by part because clients pick a
+// new boundary on every retry. The body is streamed, never held in memory.
+func requestFingerprint(req *http.Request, body io.Reader) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n", req.Method, req.URL.RequestURI())
+
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		if _, err := io.Copy(hash, body); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(hash, "%q %q\n", part.FormName(), part.FileName())
+		partHash := sha256.New()
+		if _, err := io.Copy(partHash, part); err != nil {
+			return "", err
+		}
+		hash.Write(partHash.Sum(nil))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newMultipartRequest(t *testing.T, boundary string, content []byte) (*http.Request, []byte) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	require.NoError(t, writer.SetBoundary(boundary))
+
+	part, err := writer.CreateFormFile("image", "logo.png")
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/upload", bytes.NewReader(body.Bytes()))
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req, body.Bytes()
+}
+
+func TestRequestFingerprintIgnoresMultipartBoundary(t *testing.T) {
+	first, firstBody := newMultipartRequest(t, "boundary-one", []byte("image bytes"))
+	retry, retryBody := newMultipartRequest(t, "boundary-two", []byte("image bytes"))
+	other, otherBody := newMultipartRequest(t, "boundary-one", []byte("other bytes"))
+
+	firstFingerprint, err := requestFingerprint(first, bytes.NewReader(firstBody))
+	require.NoError(t, err)
+	retryFingerprint, err := requestFingerprint(retry, bytes.NewReader(retryBody))
+	require.NoError(t, err)
+	otherFingerprint, err := requestFingerprint(other, bytes.NewReader(otherBody))
+	require.NoError(t, err)
+
+	require.Equal(t, firstFingerprint, retryFingerprint)
+	require.NotEqual(t, firstFingerprint, otherFingerprint)
+}
+
+func TestRequestFingerprintIncludesPath(t *testing.T) {
+	body := []byte(`{"filename":"logo.png"}`)
+	upload := httptest.NewRequest(http.MethodPost, "/api/v1/upload/presigned-url", bytes.NewReader(body))
+	cancel := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/1/cancel", bytes.NewReader(body))
+
+	uploadFingerprint, err := requestFingerprint(upload, bytes.NewReader(body))
+	require.NoError(t, err)
+	cancelFingerprint, err := requestFingerprint(cancel, bytes.NewReader(body))
+	require.NoError(t, err)
+
+	require.NotEqual(t, uploadFingerprint, cancelFingerprint)
+}
+
+// newIdempotentRouter serves POST /echo behind the idempotency middleware.
+// The handler answers with the body it received and counts its calls.
+func newIdempotentRouter(t *testing.T, config utils.Config, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	redisServer := miniredis.RunT(t)
+	server := &Server{
+		config:      config,
+		redisClient: redis.NewClient(&redis.Options{Addr: redisServer.Addr()}),
+	}
+
+	router := gin.New()
+	router.Use(server.idempotent())
+	router.POST("/echo", handler)
+	return router
+}
+
+func idempotencyConfig() utils.Config {
+	var config utils.Config
+	config.Idempotency = utils.IdempotencyConfig{TTL: time.Hour, LockTTL: time.Minute, MaxBodySize: 1 << 10}
+	config.Server.MaxFileSize = 1 << 10
+	config.Import.MaxArchiveSize = 1 << 10
+	return config
+}
+
+func echo(t *testing.T, calls *int32) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		atomic.AddInt32(calls, 1)
+		body, err := io.ReadAll(ctx.Request.Body)
+		require.NoError(t, err)
+		ctx.JSON(http.StatusCreated, gin.H{"body": string(body)})
+	}
+}
+
+func idempotentRequest(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestIdempotentReplay(t *testing.T) {
+	var calls int32
+	router := newIdempotentRouter(t, idempotencyConfig(), echo(t, &calls))
+
+	first := idempotentRequest(router, "key-1", `{"a":1}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+	replay := idempotentRequest(router, "key-1", `{"a":1}`)
+	require.Equal(t, http.StatusCreated, replay.Code)
+	require.Equal(t, "true", replay.Header().Get(IdempotentReplayedHeader))
+	require.JSONEq(t, first.Body.String(), replay.Body.String())
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotentKeyReusedWithDifferentBody(t *testing.T) {
+	var calls int32
+	router := newIdempotentRouter(t, idempotencyConfig(), echo(t, &calls))
+
+	require.Equal(t, http.StatusCreated, idempotentRequest(router, "key-1", `{"a":1}`).Code)
+	require.Equal(t, http.StatusUnprocessableEntity, idempotentRequest(router, "key-1", `{"a":2}`).Code)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotentRequestInFlight(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	router := newIdempotentRouter(t, idempotencyConfig(), func(ctx *gin.Context) {
+		close(started)
+		<-release
+		ctx.JSON(http.StatusCreated, gin.H{})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- idempotentRequest(router, "key-1", `{"a":1}`) }()
+	<-started
+
+	require.Equal(t, http.StatusConflict, idempotentRequest(router, "key-1", `{"a":1}`).Code)
+	close(release)
+	require.Equal(t, http.StatusCreated, (<-done).Code)
+}
+
+func TestIdempotentLargeBody(t *testing.T) {
+	var calls int32
+	config := idempotencyConfig()
+	config.Idempotency.MaxBodySize = 16
+	router := newIdempotentRouter(t, config, echo(t, &calls))
+
+	// Bodies above the in-memory size are spooled to disk and still reach the handler whole
+	body := strings.Repeat("x", 4<<10)
+	response := idempotentRequest(router, "key-1", body)
+	require.Equal(t, http.StatusCreated, response.Code)
+	require.Contains(t, response.Body.String(), body)
+	require.Equal(t, "true", idempotentRequest(router, "key-1", body).Header().Get(IdempotentReplayedHeader))
+
+	// Only bodies larger than any route accepts are rejected
+	tooLarge := strings.Repeat("x", int(config.Import.MaxArchiveSize+multipartOverhead)+1)
+	require.Equal(t, http.StatusRequestEntityTooLarge, idempotentRequest(router, "key-2", tooLarge).Code)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/dataset"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type importResponse struct {
+	ID               string `json:"id"`
+	Status           string `json:"status"`
+	Format           string `json:"format"`
+	Name             string `json:"name"`
+	ImagesCount      int32  `json:"images_count"`
+	AnnotationsCount int32  `json:"annotations_count"`
+	SkippedCount     int32  `json:"skipped_count"`
+	RequeuedCount    int32  `json:"requeued_count"`
+	// InvalidAnnotationsCount are labels left out because their box is outside the image
+	InvalidAnnotationsCount int32  `json:"invalid_annotations_count"`
+	Error                   string `json:"error,omitempty"`
+	CreatedAt               string `json:"created_at"`
+	CompletedAt             string `json:"completed_at,omitempty"`
+}
+
+type groundTruthResponse struct {
+	ID          int64           `json:"id"`
+	LogoType    string          `json:"logo_type"`
+	BoundingBox json.RawMessage `json:"bounding_box"`
+	CreatedAt   string          `json:"created_at"`
+}
+
+func newImportResponse(datasetImport db.DatasetImport) importResponse {
+	response := importResponse{
+		ID:                      datasetImport.ID.String(),
+		Status:                  datasetImport.Status,
+		Format:                  datasetImport.Format,
+		Name:                    datasetImport.Name,
+		ImagesCount:             datasetImport.ImagesCount,
+		AnnotationsCount:        datasetImport.AnnotationsCount,
+		SkippedCount:            datasetImport.SkippedCount,
+		RequeuedCount:           datasetImport.RequeuedCount,
+		InvalidAnnotationsCount: datasetImport.InvalidAnnotationsCount,
+		Error:                   datasetImport.ErrorMessage.String,
+		CreatedAt:               datasetImport.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if datasetImport.CompletedAt.Valid {
+		response.CompletedAt = datasetImport.CompletedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return response
+}
+
+// CreateImport stores an uploaded dataset archive and queues it for import
+func (s *Server) CreateImport(ctx *gin.Context) {
+	format := ctx.PostForm("format")
+	if format != dataset.FormatCOCO && format != dataset.FormatYOLO {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "format must be coco or yolo"})
+		return
+	}
+
+	file, header, err := ctx.Request.FormFile("archive")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "No archive file provided"})
+		return
+	}
+	defer file.Close()
+
+	if !strings.EqualFold(path.Ext(header.Filename), ".zip") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid file type. Only zip archives are allowed"})
+		return
+	}
+	if header.Size > s.config.Import.MaxArchiveSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"success": false, "error": fmt.Sprintf("Archive too large. Maximum size is %d bytes", s.config.Import.MaxArchiveSize),
+		})
+		return
+	}
+
+	name := strings.TrimSpace(ctx.PostForm("name"))
+	if name == "" {
+		name = strings.TrimSuffix(header.Filename, path.Ext(header.Filename))
+	}
+
+	importID := uuid.New()
+	s3Key := fmt.Sprintf("imports/%s/%s", importID, path.Base(header.Filename))
+	if _, err := s.storageClient.UploadFile(context.Background(), s3Key, file, header.Size); err != nil {
+		logrus.WithError(err).Error("Failed to upload dataset archive")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to upload archive"})
+		return
+	}
+
+	datasetImport, err := s.store.CreateDatasetImport(ctx.Request.Context(), db.CreateDatasetImportParams{
+		ID:     importID,
+		Status: dataset.StatusPending,
+		Format: format,
+		Name:   name,
+		S3Key:  sql.NullString{String: s3Key, Valid: true},
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create dataset import")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to create import"})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"success": true, "import": newImportResponse(datasetImport)})
+}
+
+// ListImports lists dataset imports, newest first
+func (s *Server) ListImports(ctx *gin.Context) {
+	var query pageQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	imports, err := s.store.ListDatasetImports(ctx.Request.Context(), db.ListDatasetImportsParams{
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list dataset imports")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list imports"})
+		return
+	}
+
+	results := make([]importResponse, 0, len(imports))
+	for _, datasetImport := range imports {
+		results = append(results, newImportResponse(datasetImport))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "imports": results})
+}
+
+// GetImport returns the status and counts of a dataset import
+func (s *Server) GetImport(ctx *gin.Context) {
+	importID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid import ID"})
+		return
+	}
+
+	datasetImport, err := s.store.GetDatasetImport(ctx.Request.Context(), importID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Import not found"})
+			return
+		}
+		logrus.WithError(err).WithField("import_id", importID).Error("Failed to get dataset import")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get import"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "import": newImportResponse(datasetImport)})
+}
+
+// GetJobGroundTruth returns the imported labels of a job
+func (s *Server) GetJobGroundTruth(ctx *gin.Context) {
+	jobID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid job ID"})
+		return
+	}
+
+	job, err := s.store.GetJob(ctx.Request.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Job not found"})
+			return
+		}
+		logrus.WithError(err).WithField("job_id", jobID).Error("Failed to get job")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get job"})
+		return
+	}
+
+	annotations, err := s.store.ListGroundTruthAnnotations(ctx.Request.Context(), jobID)
+	if err != nil {
+		logrus.WithError(err).WithField("job_id", jobID).Error("Failed to list ground truth annotations")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list ground truth"})
+		return
+	}
+
+	results := make([]groundTruthResponse, 0, len(annotations))
+	for _, annotation := range annotations {
+		results = append(results, groundTruthResponse{
+			ID:          annotation.ID,
+			LogoType:    annotation.LogoType,
+			BoundingBox: json.RawMessage(annotation.BoundingBox),
+			CreatedAt:   annotation.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	response := gin.H{"success": true, "job_id": jobID, "annotations": results}
+	if job.ImportID.Valid {
+		response["import_id"] = job.ImportID.UUID
+	}
+	ctx.JSON(http.StatusOK, response)
+}
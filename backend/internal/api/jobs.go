@@ -0,0 +1,182 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type jobEventResponse struct {
+	FromStatus string `json:"from_status,omitempty"`
+	ToStatus   string `json:"to_status"`
+	Actor      string `json:"actor"`
+	Reason     string `json:"reason,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func (s *Server) DeleteJob(ctx *gin.Context) {
+	jobID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid job ID"})
+		return
+	}
+
+	_, err = s.purger.PurgeJob(ctx.Request.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Job not found"})
+			return
+		}
+		logrus.WithError(err).WithField("job_id", jobID).Error("Failed to delete job")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to delete job"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"job_id":  jobID,
+		"message": "Job deleted successfully",
+	})
+}
+
+func (s *Server) CancelJob(ctx *gin.Context) {
+	jobID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid job ID"})
+		return
+	}
+
+	job, err := s.store.TransitionJobTx(ctx.Request.Context(), db.TransitionJobTxParams{
+		JobID:    jobID,
+		ToStatus: models.JobStatusCancelled,
+		Actor:    "api",
+		Reason:   "Cancelled by client",
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Job not found"})
+		case errors.Is(err, db.ErrInvalidTransition):
+			ctx.JSON(http.StatusConflict, gin.H{"success": false, "error": "Job can no longer be cancelled", "status": job.Status})
+		default:
+			logrus.WithError(err).WithField("job_id", jobID).Error("Failed to cancel job")
+			ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to cancel job"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"job_id":  jobID,
+		"status":  job.Status,
+		"message": "Job cancelled successfully",
+	})
+}
+
+func (s *Server) GetJobEvents(ctx *gin.Context) {
+	jobID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid job ID"})
+		return
+	}
+
+	job, err := s.store.GetJob(ctx.Request.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Job not found"})
+			return
+		}
+		logrus.WithError(err).WithField("job_id", jobID).Error("Failed to get job")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get job"})
+		return
+	}
+
+	events, err := s.store.ListJobEvents(ctx.Request.Context(), jobID)
+	if err != nil {
+		logrus.WithError(err).WithField("job_id", jobID).Error("Failed to list job events")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list job events"})
+		return
+	}
+
+	timeline := make([]jobEventResponse, 0, len(events))
+	for _, event := range events {
+		timeline = append(timeline, jobEventResponse{
+			FromStatus: event.FromStatus.String,
+			ToStatus:   event.ToStatus,
+			Actor:      event.Actor,
+			Reason:     event.Reason.String,
+			CreatedAt:  event.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"job_id":  jobID,
+		"status":  job.Status,
+		"events":  timeline,
+	})
+}
+
+// GetJobResult returns the logos of a completed job together with their
+// geometry and a presigned link to the annotated result image
+func (s *Server) GetJobResult(ctx *gin.Context) {
+	jobID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid job ID"})
+		return
+	}
+
+	job, err := s.store.GetJob(ctx.Request.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Job not found"})
+			return
+		}
+		logrus.WithError(err).WithField("job_id", jobID).Error("Failed to get job")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get job"})
+		return
+	}
+	if job.Status != models.JobStatusCompleted {
+		ctx.JSON(http.StatusConflict, gin.H{"success": false, "error": "Job has not completed", "status": job.Status})
+		return
+	}
+
+	logos, err := s.store.GetLogosByJobID(ctx.Request.Context(), jobID)
+	if err != nil {
+		logrus.WithError(err).WithField("job_id", jobID).Error("Failed to get logos")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get logos"})
+		return
+	}
+
+	var resultURL string
+	if job.ResultUrl.Valid {
+		resultURL, err = s.storageClient.GetPresignedGetURL(ctx.Request.Context(), job.ResultUrl.String, time.Hour)
+		if err != nil {
+			logrus.WithError(err).WithField("s3_key", job.ResultUrl.String).Warn("Failed to get presigned URL for result")
+		}
+	}
+
+	results := make([]logoResponse, 0, len(logos))
+	for _, logo := range logos {
+		results = append(results, s.newLogoResponse(ctx, logo))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":           true,
+		"job_id":            jobID,
+		"status":            job.Status,
+		"result_url":        resultURL,
+		"logos_found":       len(results),
+		"logos":             results,
+		"detection_options": models.DetectionOptions(job.DetectionOptions),
+		"created_at":        job.CreatedAt.UTC().Format(time.RFC3339),
+		"completed_at":      job.CompletedAt.UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,471 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/extraction"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/similarity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type similarityQuery struct {
+	Algorithm   string `form:"algorithm" binding:"omitempty,oneof=phash dhash"`
+	MaxDistance *int32 `form:"max_distance" binding:"omitempty,min=0,max=16"`
+	Limit       int32  `form:"limit" binding:"omitempty,min=1"`
+}
+
+type rectifyRequest struct {
+	// Corners are in top-left, top-right, bottom-right, bottom-left order
+	Corners []models.Point `json:"corners" binding:"required,len=4"`
+}
+
+type logoFilterQuery struct {
+	JobID          string   `form:"job_id" binding:"omitempty,uuid"`
+	LogoType       string   `form:"logo_type"`
+	MinConfidence  *float64 `form:"min_confidence" binding:"omitempty,min=0,max=1"`
+	MinArea        *int64   `form:"min_area" binding:"omitempty,min=0"`
+	MaxArea        *int64   `form:"max_area" binding:"omitempty,min=0"`
+	MinAspectRatio *float64 `form:"min_aspect_ratio" binding:"omitempty,gt=0"`
+	MaxAspectRatio *float64 `form:"max_aspect_ratio" binding:"omitempty,gt=0"`
+	Limit          int32    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset         int32    `form:"offset" binding:"omitempty,min=0"`
+}
+
+type paletteCompareRequest struct {
+	// Reference colors as #rrggbb, for example from a brand guide
+	Reference []string `json:"reference" binding:"required,min=1,dive,hexcolor"`
+	// Tolerance overrides PALETTE_DELTA_E_TOLERANCE
+	Tolerance *float64 `json:"tolerance" binding:"omitempty,gt=0"`
+}
+
+type referenceMatchResponse struct {
+	Hex     string  `json:"hex"`
+	Nearest string  `json:"nearest"`
+	DeltaE  float64 `json:"delta_e"`
+}
+
+type paletteMatchResponse struct {
+	Hex        string  `json:"hex"`
+	Proportion float64 `json:"proportion"`
+	Nearest    string  `json:"nearest"`
+	DeltaE     float64 `json:"delta_e"`
+}
+
+type logoResponse struct {
+	ID           int64                 `json:"id"`
+	JobID        string                `json:"job_id"`
+	LogoType     string                `json:"logo_type"`
+	Confidence   float32               `json:"confidence"`
+	BoundingBox  models.BBox           `json:"bounding_box"`
+	Geometry     *models.Geometry      `json:"geometry,omitempty"`
+	Palette      []models.PaletteColor `json:"palette,omitempty"`
+	S3Key        string                `json:"s3_key"`
+	ImageURL     string                `json:"image_url,omitempty"`
+	ReviewStatus string                `json:"review_status"`
+}
+
+type similarLogoResponse struct {
+	LogoID     int64   `json:"logo_id"`
+	JobID      string  `json:"job_id"`
+	LogoType   string  `json:"logo_type"`
+	Confidence float32 `json:"confidence"`
+	S3Key      string  `json:"s3_key"`
+	ImageURL   string  `json:"image_url,omitempty"`
+	Distance   int32   `json:"distance"`
+}
+
+func (s *Server) newLogoResponse(ctx *gin.Context, logo db.Logo) logoResponse {
+	imageURL, err := s.storageClient.GetPresignedGetURL(ctx.Request.Context(), logo.S3Key, time.Hour)
+	if err != nil {
+		logrus.WithError(err).WithField("s3_key", logo.S3Key).Warn("Failed to get presigned URL for logo")
+	}
+	var geometry *models.Geometry
+	if g := models.Geometry(logo.Geometry); !g.IsZero() {
+		geometry = &g
+	}
+	return logoResponse{
+		ID:           logo.ID,
+		JobID:        logo.JobID.String(),
+		LogoType:     logo.LogoType,
+		Confidence:   logo.Confidence,
+		BoundingBox:  models.BBox(logo.BoundingBox),
+		Geometry:     geometry,
+		Palette:      logo.Palette,
+		S3Key:        logo.S3Key,
+		ImageURL:     imageURL,
+		ReviewStatus: logo.ReviewStatus,
+	}
+}
+
+// ListLogos lists detected logos by confidence, optionally filtered by
+// bounding box area and aspect ratio, most confident first
+func (s *Server) ListLogos(ctx *gin.Context) {
+	var query logoFilterQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid query parameters"))
+		return
+	}
+	if query.MinArea != nil && query.MaxArea != nil && *query.MinArea > *query.MaxArea {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "min_area must not exceed max_area"})
+		return
+	}
+	if query.MinAspectRatio != nil && query.MaxAspectRatio != nil && *query.MinAspectRatio > *query.MaxAspectRatio {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "min_aspect_ratio must not exceed max_aspect_ratio"})
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	params := db.FilterLogosParams{
+		LogoType:     sql.NullString{String: query.LogoType, Valid: query.LogoType != ""},
+		ResultLimit:  query.Limit,
+		ResultOffset: query.Offset,
+	}
+	if query.JobID != "" {
+		params.JobID = uuid.NullUUID{UUID: uuid.MustParse(query.JobID), Valid: true}
+	}
+	if query.MinConfidence != nil {
+		params.MinConfidence = sql.NullFloat64{Float64: *query.MinConfidence, Valid: true}
+	}
+	if query.MinArea != nil {
+		params.MinArea = sql.NullInt64{Int64: *query.MinArea, Valid: true}
+	}
+	if query.MaxArea != nil {
+		params.MaxArea = sql.NullInt64{Int64: *query.MaxArea, Valid: true}
+	}
+	if query.MinAspectRatio != nil {
+		params.MinAspectRatio = sql.NullFloat64{Float64: *query.MinAspectRatio, Valid: true}
+	}
+	if query.MaxAspectRatio != nil {
+		params.MaxAspectRatio = sql.NullFloat64{Float64: *query.MaxAspectRatio, Valid: true}
+	}
+
+	logos, err := s.store.FilterLogos(ctx.Request.Context(), params)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to filter logos")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list logos"})
+		return
+	}
+
+	results := make([]logoResponse, 0, len(logos))
+	for _, logo := range logos {
+		results = append(results, s.newLogoResponse(ctx, logo))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+		"logos":   results,
+	})
+}
+
+func (s *Server) GetSimilarLogos(ctx *gin.Context) {
+	logoID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid logo ID"})
+		return
+	}
+
+	var query similarityQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid query parameters"))
+		return
+	}
+
+	hash, err := s.store.GetLogoHash(ctx.Request.Context(), logoID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logrus.WithError(err).WithField("logo_id", logoID).Error("Failed to get logo hash")
+			ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get logo"})
+			return
+		}
+		if _, err := s.store.GetLogo(ctx.Request.Context(), logoID); errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Logo not found"})
+			return
+		}
+		ctx.JSON(http.StatusConflict, gin.H{"success": false, "error": "Logo has not been indexed yet"})
+		return
+	}
+
+	hashes := similarity.Hashes{DHash: uint64(hash.Dhash), PHash: uint64(hash.Phash)}
+	s.respondWithSimilarLogos(ctx, query, hashes, logoID)
+}
+
+func (s *Server) SearchLogos(ctx *gin.Context) {
+	var query similarityQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid query parameters"))
+		return
+	}
+
+	file, header, err := ctx.Request.FormFile("image")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "No image file provided"})
+		return
+	}
+	defer file.Close()
+
+	if !slices.Contains(AllowedTypes, header.Header.Get("Content-Type")) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid file type. Only JPEG and PNG are allowed"})
+		return
+	}
+	if header.Size > s.config.Server.MaxFileSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"success": false, "error": fmt.Sprintf("File too large. Maximum size is %d bytes", s.config.Server.MaxFileSize),
+		})
+		return
+	}
+	if _, err := imaging.CheckPixels(file, s.config.Server.MaxImagePixels); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid image file"))
+		return
+	}
+
+	img, err := imaging.Decode(file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Failed to decode image"})
+		return
+	}
+
+	// Logo IDs start at 1, so nothing is excluded
+	s.respondWithSimilarLogos(ctx, query, similarity.HashImage(img), 0)
+}
+
+func (s *Server) respondWithSimilarLogos(ctx *gin.Context, query similarityQuery, hashes similarity.Hashes, excludeLogoID int64) {
+	algorithm := query.Algorithm
+	if algorithm == "" {
+		algorithm = similarity.AlgorithmPHash
+	}
+	maxDistance := s.config.Similarity.MaxDistance
+	if query.MaxDistance != nil {
+		maxDistance = *query.MaxDistance
+	}
+	maxDistance = min(maxDistance, similarity.MaxSearchDistance)
+	limit := min(query.Limit, s.config.Similarity.MaxResults)
+	if limit == 0 {
+		limit = min(20, s.config.Similarity.MaxResults)
+	}
+
+	matches, err := s.store.SearchSimilarLogos(ctx.Request.Context(), db.SearchSimilarLogosParams{
+		Algorithm:     algorithm,
+		Hash:          hashes.For(algorithm),
+		BandKeys:      similarity.BandKeys(hashes.For(algorithm), maxDistance),
+		ExcludeLogoID: excludeLogoID,
+		MaxDistance:   maxDistance,
+		ResultLimit:   limit,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to search similar logos")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to search similar logos"})
+		return
+	}
+
+	results := make([]similarLogoResponse, 0, len(matches))
+	for _, match := range matches {
+		imageURL, err := s.storageClient.GetPresignedGetURL(ctx.Request.Context(), match.S3Key, time.Hour)
+		if err != nil {
+			logrus.WithError(err).WithField("s3_key", match.S3Key).Warn("Failed to get presigned URL for logo")
+		}
+		results = append(results, similarLogoResponse{
+			LogoID:     match.ID,
+			JobID:      match.JobID.String(),
+			LogoType:   match.LogoType,
+			Confidence: match.Confidence,
+			S3Key:      match.S3Key,
+			ImageURL:   imageURL,
+			Distance:   match.Distance,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"algorithm":    algorithm,
+		"max_distance": maxDistance,
+		"matches":      results,
+	})
+}
+
+// RectifyLogo unwarps a logo from its original image using four corners
+// supplied by the user, replacing any earlier rectification
+func (s *Server) RectifyLogo(ctx *gin.Context) {
+	logoID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid logo ID"})
+		return
+	}
+
+	var req rectifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid request"))
+		return
+	}
+
+	logo, err := s.store.GetLogo(ctx.Request.Context(), logoID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Logo not found"})
+			return
+		}
+		logrus.WithError(err).WithField("logo_id", logoID).Error("Failed to get logo")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get logo"})
+		return
+	}
+
+	logo, err = s.rectifier.Rectify(ctx.Request.Context(), logo, req.Corners)
+	if err != nil {
+		if errors.Is(err, imaging.ErrDegenerateCorners) || errors.Is(err, imaging.ErrCornersOutOfBounds) ||
+			errors.Is(err, extraction.ErrRectifiedTooLarge) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		logrus.WithError(err).WithField("logo_id", logoID).Error("Failed to rectify logo")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to rectify logo"})
+		return
+	}
+
+	rectifiedURL, err := s.storageClient.GetPresignedGetURL(ctx.Request.Context(), logo.RectifiedS3Key.String, time.Hour)
+	if err != nil {
+		logrus.WithError(err).WithField("s3_key", logo.RectifiedS3Key.String).Warn("Failed to get presigned URL for rectified logo")
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":          true,
+		"logo_id":          logo.ID,
+		"corners":          req.Corners,
+		"rectified_s3_key": logo.RectifiedS3Key.String,
+		"rectified_url":    rectifiedURL,
+	})
+}
+
+// ComparePalette reports the CIEDE2000 distance between a logo's dominant
+// colors and a reference palette
+func (s *Server) ComparePalette(ctx *gin.Context) {
+	logoID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid logo ID"})
+		return
+	}
+
+	var req paletteCompareRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid request"))
+		return
+	}
+	tolerance := s.config.Palette.DeltaETolerance
+	if req.Tolerance != nil {
+		tolerance = *req.Tolerance
+	}
+
+	reference := make([]imaging.Lab, 0, len(req.Reference))
+	for _, hex := range req.Reference {
+		color, err := imaging.ParseHexColor(hex)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		reference = append(reference, color)
+	}
+
+	logo, err := s.store.GetLogo(ctx.Request.Context(), logoID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Logo not found"})
+			return
+		}
+		logrus.WithError(err).WithField("logo_id", logoID).Error("Failed to get logo")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get logo"})
+		return
+	}
+	if len(logo.Palette) == 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"success": false, "error": "Logo palette has not been extracted yet"})
+		return
+	}
+
+	comparison := imaging.ComparePalettes(extraction.ImagingPalette(logo.Palette), reference)
+
+	withinTolerance := comparison.MeanDeltaE <= tolerance
+	referenceMatches := make([]referenceMatchResponse, 0, len(reference))
+	for i, match := range comparison.ReferenceMatches {
+		// A brand color missing from the logo fails the check even when it is small
+		withinTolerance = withinTolerance && match.DeltaE <= tolerance
+		referenceMatches = append(referenceMatches, referenceMatchResponse{
+			Hex:     reference[i].Hex(),
+			Nearest: logo.Palette[match.Nearest].Hex,
+			DeltaE:  match.DeltaE,
+		})
+	}
+	paletteMatches := make([]paletteMatchResponse, 0, len(logo.Palette))
+	for i, match := range comparison.PaletteMatches {
+		paletteMatches = append(paletteMatches, paletteMatchResponse{
+			Hex:        logo.Palette[i].Hex,
+			Proportion: logo.Palette[i].Proportion,
+			Nearest:    reference[match.Nearest].Hex(),
+			DeltaE:     match.DeltaE,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":          true,
+		"logo_id":          logo.ID,
+		"tolerance":        tolerance,
+		"mean_delta_e":     comparison.MeanDeltaE,
+		"max_delta_e":      comparison.MaxDeltaE,
+		"within_tolerance": withinTolerance,
+		"reference":        referenceMatches,
+		"palette":          paletteMatches,
+	})
+}
+
+// GetLogoVector downloads the SVG tracing of a logo, tracing it first when
+// that did not happen during processing
+func (s *Server) GetLogoVector(ctx *gin.Context) {
+	logoID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid logo ID"})
+		return
+	}
+
+	logo, err := s.store.GetLogo(ctx.Request.Context(), logoID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Logo not found"})
+			return
+		}
+		logrus.WithError(err).WithField("logo_id", logoID).Error("Failed to get logo")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get logo"})
+		return
+	}
+
+	if !logo.VectorS3Key.Valid {
+		logo, err = s.vectorizer.Vectorize(ctx.Request.Context(), logo)
+		if err != nil {
+			logrus.WithError(err).WithField("logo_id", logoID).Error("Failed to vectorize logo")
+			ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to vectorize logo"})
+			return
+		}
+	}
+
+	body, err := s.storageClient.DownloadFile(ctx.Request.Context(), logo.VectorS3Key.String)
+	if err != nil {
+		logrus.WithError(err).WithField("s3_key", logo.VectorS3Key.String).Error("Failed to download logo vector")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to download logo vector"})
+		return
+	}
+	defer body.Close()
+
+	ctx.DataFromReader(http.StatusOK, -1, "image/svg+xml", body, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="logo_%d.svg"`, logo.ID),
+	})
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	dbtypes "github.com/Viczdera/ai-logo-preserve/backend/internal/db"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type reviewQueueQuery struct {
+	JobID  string `form:"job_id" binding:"omitempty,uuid"`
+	Limit  int32  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int32  `form:"offset" binding:"omitempty,min=0"`
+}
+
+type reviewRequest struct {
+	Reviewer    string       `json:"reviewer" binding:"required"`
+	Decision    string       `json:"decision" binding:"required,oneof=accept reject relabel adjust"`
+	LogoType    string       `json:"logo_type"`
+	BoundingBox *models.BBox `json:"bounding_box"`
+	Comment     string       `json:"comment"`
+}
+
+type reviewLogoResponse struct {
+	ID           int64       `json:"id"`
+	JobID        string      `json:"job_id"`
+	LogoType     string      `json:"logo_type"`
+	Confidence   float32     `json:"confidence"`
+	BoundingBox  models.BBox `json:"bounding_box"`
+	S3Key        string      `json:"s3_key"`
+	ImageURL     string      `json:"image_url,omitempty"`
+	ReviewStatus string      `json:"review_status"`
+}
+
+type logoReviewResponse struct {
+	ID                  int64       `json:"id"`
+	LogoID              int64       `json:"logo_id"`
+	Reviewer            string      `json:"reviewer"`
+	Decision            string      `json:"decision"`
+	PreviousLogoType    string      `json:"previous_logo_type"`
+	LogoType            string      `json:"logo_type"`
+	PreviousBoundingBox models.BBox `json:"previous_bounding_box"`
+	BoundingBox         models.BBox `json:"bounding_box"`
+	Comment             string      `json:"comment,omitempty"`
+	CreatedAt           string      `json:"created_at"`
+}
+
+func (s *Server) newReviewLogoResponse(ctx *gin.Context, logo db.Logo) reviewLogoResponse {
+	imageURL, err := s.storageClient.GetPresignedGetURL(ctx.Request.Context(), logo.S3Key, time.Hour)
+	if err != nil {
+		logrus.WithError(err).WithField("s3_key", logo.S3Key).Warn("Failed to get presigned URL for logo")
+	}
+	return reviewLogoResponse{
+		ID:           logo.ID,
+		JobID:        logo.JobID.String(),
+		LogoType:     logo.LogoType,
+		Confidence:   logo.Confidence,
+		BoundingBox:  models.BBox(logo.BoundingBox),
+		S3Key:        logo.S3Key,
+		ImageURL:     imageURL,
+		ReviewStatus: logo.ReviewStatus,
+	}
+}
+
+func newLogoReviewResponse(review db.LogoReview) logoReviewResponse {
+	return logoReviewResponse{
+		ID:                  review.ID,
+		LogoID:              review.LogoID,
+		Reviewer:            review.Reviewer,
+		Decision:            review.Decision,
+		PreviousLogoType:    review.PreviousLogoType,
+		LogoType:            review.LogoType,
+		PreviousBoundingBox: models.BBox(review.PreviousBoundingBox),
+		BoundingBox:         models.BBox(review.BoundingBox),
+		Comment:             review.Comment.String,
+		CreatedAt:           review.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// GetReviewQueue lists the logos still waiting for review, least confident first
+func (s *Server) GetReviewQueue(ctx *gin.Context) {
+	var query reviewQueueQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	var jobID uuid.NullUUID
+	if query.JobID != "" {
+		jobID = uuid.NullUUID{UUID: uuid.MustParse(query.JobID), Valid: true}
+	}
+
+	logos, err := s.store.ListLogosForReview(ctx.Request.Context(), db.ListLogosForReviewParams{
+		JobID:        jobID,
+		ResultLimit:  query.Limit,
+		ResultOffset: query.Offset,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list logos for review")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list review queue"})
+		return
+	}
+
+	results := make([]reviewLogoResponse, 0, len(logos))
+	for _, logo := range logos {
+		results = append(results, s.newReviewLogoResponse(ctx, logo))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+		"logos":   results,
+	})
+}
+
+// ReviewLogo records a reviewer's decision about a detected logo
+func (s *Server) ReviewLogo(ctx *gin.Context) {
+	logoID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid logo ID"})
+		return
+	}
+
+	var req reviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid request"))
+		return
+	}
+
+	params := db.ReviewLogoTxParams{
+		LogoID:   logoID,
+		Reviewer: strings.TrimSpace(req.Reviewer),
+		Decision: req.Decision,
+		Comment:  sql.NullString{String: req.Comment, Valid: req.Comment != ""},
+	}
+	switch req.Decision {
+	case models.ReviewDecisionRelabel:
+		params.LogoType = strings.TrimSpace(req.LogoType)
+		if params.LogoType == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "logo_type is required to relabel a logo"})
+			return
+		}
+	case models.ReviewDecisionAdjust:
+		box := req.BoundingBox
+		if box == nil || box.Width <= 0 || box.Height <= 0 || box.X < 0 || box.Y < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"success": false, "error": "A bounding_box with a positive width and height is required to adjust a logo",
+			})
+			return
+		}
+		params.BoundingBox = dbtypes.BoundingBox(*box)
+	}
+
+	result, err := s.store.ReviewLogoTx(ctx.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Logo not found"})
+			return
+		}
+		logrus.WithError(err).WithField("logo_id", logoID).Error("Failed to review logo")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to review logo"})
+		return
+	}
+
+	// Reviewing a copy reviews its source, answer with the logo that was asked for
+	logo := result.Logo
+	for _, copied := range result.Copies {
+		if copied.ID == logoID {
+			logo = copied
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"logo":    s.newReviewLogoResponse(ctx, logo),
+		"review":  newLogoReviewResponse(result.Review),
+	})
+}
+
+// GetLogoReviews returns the review history of a logo, oldest first
+func (s *Server) GetLogoReviews(ctx *gin.Context) {
+	logoID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid logo ID"})
+		return
+	}
+
+	logo, err := s.store.GetLogo(ctx.Request.Context(), logoID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Logo not found"})
+			return
+		}
+		logrus.WithError(err).WithField("logo_id", logoID).Error("Failed to get logo")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get logo"})
+		return
+	}
+
+	reviews, err := s.store.ListLogoReviews(ctx.Request.Context(), logoID)
+	if err != nil {
+		logrus.WithError(err).WithField("logo_id", logoID).Error("Failed to list logo reviews")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list logo reviews"})
+		return
+	}
+
+	history := make([]logoReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		history = append(history, newLogoReviewResponse(review))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"logo_id":       logo.ID,
+		"review_status": logo.ReviewStatus,
+		"reviews":       history,
+	})
+}
+
+// GetJobReview returns the review state of a job derived from its logos
+func (s *Server) GetJobReview(ctx *gin.Context) {
+	jobID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid job ID"})
+		return
+	}
+
+	if _, err := s.store.GetJob(ctx.Request.Context(), jobID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Job not found"})
+			return
+		}
+		logrus.WithError(err).WithField("job_id", jobID).Error("Failed to get job")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get job"})
+		return
+	}
+
+	rows, err := s.store.CountLogosByReviewStatus(ctx.Request.Context(), jobID)
+	if err != nil {
+		logrus.WithError(err).WithField("job_id", jobID).Error("Failed to count logos by review status")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get job review"})
+		return
+	}
+
+	counts := map[string]int64{
+		models.ReviewStatusPending:   0,
+		models.ReviewStatusAccepted:  0,
+		models.ReviewStatusRejected:  0,
+		models.ReviewStatusCorrected: 0,
+	}
+	for _, row := range rows {
+		counts[row.ReviewStatus] = row.Count
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"job_id":       jobID,
+		"review_state": models.JobReviewState(counts),
+		"counts":       counts,
+	})
+}
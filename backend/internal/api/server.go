@@ -4,12 +4,16 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/cleanup"
 	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/extraction"
 	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
 	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/tiling"
 	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -21,19 +25,30 @@ var (
 type Server struct {
 	config        utils.Config
 	storageClient storage.Client
-	store         db.Querier
+	store         db.Store
 	redisClient   *redis.Client
 	queueClient   queue.Client
-	router        *gin.Engine
+	purger        *cleanup.Purger
+	rectifier     *extraction.Rectifier
+	vectorizer    *extraction.Vectorizer
+	// tiler is nil when tiled detection is disabled
+	tiler  *tiling.Tiler
+	router *gin.Engine
 }
 
-func NewServer(cfg utils.Config, storageClient storage.Client, store db.Querier, redisClient *redis.Client, queueClient queue.Client) *Server {
+func NewServer(cfg utils.Config, storageClient storage.Client, store db.Store, redisClient *redis.Client, queueClient queue.Client, purger *cleanup.Purger) *Server {
 	server := &Server{
 		config:        cfg,
 		storageClient: storageClient,
 		store:         store,
 		redisClient:   redisClient,
 		queueClient:   queueClient,
+		purger:        purger,
+		rectifier:     extraction.NewRectifier(cfg.Rectify, store, storageClient),
+		vectorizer:    extraction.NewVectorizer(cfg.Vector, store, storageClient),
+	}
+	if cfg.Tiling.Enabled {
+		server.tiler = tiling.NewTiler(cfg.Tiling, store, storageClient, queueClient)
 	}
 
 	server.setupRouter()
@@ -49,8 +64,8 @@ func (s *Server) setupRouter() {
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", IdempotencyKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", IdempotentReplayedHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -66,13 +81,46 @@ func (s *Server) setupRouter() {
 	// )))
 
 	router.GET("/health", s.healthCheck)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	api := router.Group("/api/v1")
+	api.Use(s.idempotent())
 	{
 		api.POST("/upload", s.UploadImage)
 		api.POST("/upload/presigned-url", s.GetPresignedUrl)
+		api.DELETE("/jobs/:id", s.DeleteJob)
+		api.POST("/jobs/:id/cancel", s.CancelJob)
+		api.GET("/jobs/:id/events", s.GetJobEvents)
+		api.GET("/jobs/:id/result", s.GetJobResult)
+		api.GET("/logos", s.ListLogos)
+		api.GET("/logos/:id/similar", s.GetSimilarLogos)
+		api.POST("/logos/search", s.SearchLogos)
+		api.POST("/logos/:id/rectify", s.RectifyLogo)
+		api.POST("/logos/:id/palette/compare", s.ComparePalette)
+		api.GET("/logos/:id/vector", s.GetLogoVector)
+		api.GET("/logos/:id/reviews", s.GetLogoReviews)
+		api.POST("/logos/:id/review", s.ReviewLogo)
+		api.GET("/reviews/queue", s.GetReviewQueue)
+		api.GET("/jobs/:id/review", s.GetJobReview)
+		api.POST("/exports", s.CreateExport)
+		api.GET("/exports", s.ListExports)
+		api.GET("/exports/:id", s.GetExport)
+		api.POST("/imports", s.CreateImport)
+		api.GET("/imports", s.ListImports)
+		api.GET("/imports/:id", s.GetImport)
+		api.GET("/jobs/:id/ground-truth", s.GetJobGroundTruth)
+		api.POST("/evaluations", s.CreateEvaluation)
+		api.POST("/compositions", s.CreateComposition)
+		api.GET("/compositions", s.ListCompositions)
+		api.GET("/compositions/:id", s.GetComposition)
+		api.POST("/brands", s.CreateBrand)
+		api.GET("/brands", s.ListBrands)
+		api.GET("/brands/:id", s.GetBrand)
+		api.PUT("/brands/:id", s.UpdateBrand)
+		api.DELETE("/brands/:id", s.DeleteBrand)
+		api.POST("/brands/:id/references", s.AddBrandReference)
+		api.DELETE("/brands/:id/references/:reference_id", s.DeleteBrandReference)
 		// api.GET("/jobs/:id", s.getJobStatus)
-		// api.GET("/jobs/:id/result", s.getJobResult)
 	}
 
 	s.router = router
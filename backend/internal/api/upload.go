@@ -3,15 +3,23 @@ package api
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	dbtypes "github.com/Viczdera/ai-logo-preserve/backend/internal/db"
 	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
 	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
@@ -20,6 +28,44 @@ var (
 	AllowedTypes = []string{"image/jpeg", "image/png"}
 )
 
+// detectionOptionsRequest is the JSON of the upload's options field
+type detectionOptionsRequest struct {
+	ConfidenceThreshold float64  `json:"confidence_threshold" binding:"omitempty,gt=0,lte=1"`
+	ModelVariant        string   `json:"model_variant" binding:"omitempty,oneof=n s m l x"`
+	Classes             []string `json:"classes" binding:"omitempty,max=100,dive,required,max=100"`
+	MaxDetections       int      `json:"max_detections" binding:"omitempty,min=1,max=1000"`
+	Tiling              *bool    `json:"tiling"`
+}
+
+// parseDetectionOptions reads and validates the options of an upload, which
+// are optional
+func parseDetectionOptions(raw string) (models.DetectionOptions, error) {
+	if raw == "" {
+		return models.DetectionOptions{}, nil
+	}
+
+	var req detectionOptionsRequest
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return models.DetectionOptions{}, fmt.Errorf("options must be a JSON object: %w", err)
+	}
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return models.DetectionOptions{}, errors.New("options must be a single JSON object")
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return models.DetectionOptions{}, err
+	}
+
+	return models.DetectionOptions{
+		ConfidenceThreshold: req.ConfidenceThreshold,
+		ModelVariant:        req.ModelVariant,
+		Classes:             req.Classes,
+		MaxDetections:       req.MaxDetections,
+		Tiling:              req.Tiling,
+	}, nil
+}
+
 func (s *Server) UploadImage(ctx *gin.Context) {
 	file, header, err := ctx.Request.FormFile("image")
 	if err != nil {
@@ -41,11 +87,43 @@ func (s *Server) UploadImage(ctx *gin.Context) {
 		return
 	}
 
+	force, err := strconv.ParseBool(ctx.DefaultPostForm("force", ctx.DefaultQuery("force", "false")))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid force flag"})
+		return
+	}
+
+	options, err := parseDetectionOptions(ctx.PostForm("options"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid detection options"))
+		return
+	}
+	if options.Tiling != nil && *options.Tiling && s.tiler == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Tiled detection is disabled"})
+		return
+	}
+
+	// The declared size is checked before anything decodes the pixels
+	imageConfig, err := imaging.CheckPixels(file, s.config.Server.MaxImagePixels)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err, "Invalid image file"))
+		return
+	}
+
+	// Oversized images are detected tile by tile, which changes their result
+	tiled := s.tiler != nil && s.tiler.NeedsTiling(imageConfig.Width, imageConfig.Height, options.Tiling)
+	detectionParams := s.detectionParams()
+	detectionParams.ModelVersion = options.ModelVersion(detectionParams.ModelVersion)
+	detectionParams = detectionParams.WithOptions(options)
+	if tiled {
+		detectionParams.Tiling = s.tiler.Params()
+	}
+
 	jobID := uuid.New()
 	s3Key := fmt.Sprintf("original/%s/%s", jobID.String(), header.Filename)
 
 	// Upload file to S3
-	_, err = s.storageClient.UploadFile(context.Background(), s3Key, file, header.Size)
+	upload, err := s.storageClient.UploadFile(context.Background(), s3Key, file, header.Size)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to upload file to S3")
 		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to upload file"})
@@ -60,12 +138,38 @@ func (s *Server) UploadImage(ctx *gin.Context) {
 		return
 	}
 
+	params := db.CreateJobParams{
+		ID:                jobID,
+		Status:            models.JobStatusPending,
+		S3Key:             s3Key,
+		UploadUrl:         uploadURL,
+		ContentSha256:     sql.NullString{String: upload.SHA256, Valid: true},
+		ParamsFingerprint: sql.NullString{String: detectionParams.Fingerprint(), Valid: true},
+		ModelVersion:      sql.NullString{String: detectionParams.ModelVersion, Valid: true},
+		DetectionOptions:  dbtypes.DetectionOptions(options),
+		Tiled:             tiled,
+	}
+
+	// Reuse the result of an identical image detected with the same parameters
+	if !force {
+		cached, err := s.store.GetCachedJob(context.Background(), db.GetCachedJobParams{
+			ContentSha256:     params.ContentSha256,
+			ParamsFingerprint: params.ParamsFingerprint,
+			ModelVersion:      params.ModelVersion,
+		})
+		switch {
+		case err == nil:
+			s.completeFromCache(ctx, params, cached)
+			return
+		case !errors.Is(err, sql.ErrNoRows):
+			logrus.WithError(err).Warn("Failed to look up cached detection result")
+		}
+	}
+
 	// Create job record in database
-	job, err := s.store.CreateJob(context.Background(), db.CreateJobParams{
-		ID:        int64(jobID.ID()),
-		Status:    "pending",
-		S3Key:     s3Key,
-		UploadUrl: uploadURL,
+	job, err := s.store.CreateJobTx(context.Background(), db.CreateJobTxParams{
+		CreateJobParams: params,
+		Actor:           "api",
 	})
 	if err != nil {
 		logrus.WithError(err).Error("Failed to create job in database")
@@ -73,35 +177,23 @@ func (s *Server) UploadImage(ctx *gin.Context) {
 		return
 	}
 
-	// Publish job to queue for processing
-	jobModel := &models.Job{
-		ID:        strconv.FormatInt(job.ID, 10),
-		Status:    job.Status,
-		S3Key:     job.S3Key,
-		UploadURL: job.UploadUrl,
-		CreatedAt: job.CreatedAt,
-		UpdatedAt: job.UpdatedAt,
-	}
-	fmt.Printf("Job model: %+v", jobModel)
+	// Publish job to queue for processing, tiled images are split by a consumer
+	if tiled {
+		err = s.queueClient.PublishSplit(&models.SplitMessage{JobID: job.ID})
+	} else {
+		jobModel := queue.NewJobMessage(job)
+		fmt.Printf("Job model: %+v", jobModel)
 
-	// Set optional fields if they exist
-	if job.ResultUrl.Valid {
-		jobModel.ResultURL = job.ResultUrl.String
+		err = s.queueClient.PublishJob(jobModel)
 	}
-	if !job.CompletedAt.IsZero() {
-		jobModel.CompletedAt = &job.CompletedAt
-	}
-	if job.ErrorMessage.Valid {
-		jobModel.Error = job.ErrorMessage.String
-	}
-
-	err = s.queueClient.PublishJob(jobModel)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to publish job to queue")
 		// Update job status to failed
-		_, updateErr := s.store.UpdateJobError(context.Background(), db.UpdateJobErrorParams{
-			ID:           job.ID,
-			Status:       "failed",
+		_, updateErr := s.store.TransitionJobTx(context.Background(), db.TransitionJobTxParams{
+			JobID:        job.ID,
+			ToStatus:     models.JobStatusFailed,
+			Actor:        "api",
+			Reason:       "Failed to queue job for processing",
 			ErrorMessage: sql.NullString{String: "Failed to queue job for processing", Valid: true},
 		})
 		if updateErr != nil {
@@ -111,11 +203,46 @@ func (s *Server) UploadImage(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusAccepted, gin.H{
+	response := gin.H{
 		"success":    true,
 		"job_id":     jobID.String(),
 		"status":     "pending",
+		"cached":     false,
 		"message":    "Image uploaded successfully. Processing started.",
 		"upload_url": uploadURL,
+	}
+	if tiled {
+		response["tiles"] = s.tiler.TileCount(imageConfig.Width, imageConfig.Height)
+	}
+	ctx.JSON(http.StatusAccepted, response)
+}
+
+// completeFromCache creates a completed job that shares the logos of an
+// earlier job for the same image, without queueing a new detection
+func (s *Server) completeFromCache(ctx *gin.Context, params db.CreateJobParams, source db.Job) {
+	result, err := s.store.CreateCachedJobTx(context.Background(), db.CreateCachedJobTxParams{
+		CreateJobParams: params,
+		Actor:           "api",
+		Source:          source,
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("source_job_id", source.ID).Error("Failed to create job from cached result")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to create job"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"job_id":        result.Job.ID.String(),
+		"status":        result.Job.Status,
+		"cached":        true,
+		"source_job_id": source.ID.String(),
+		"logos_found":   len(result.Logos),
+		"message":       "Identical image already processed. Reusing existing result.",
+		"upload_url":    params.UploadUrl,
 	})
 }
+
+func (s *Server) detectionParams() models.DetectionParams {
+	return models.DetectionParams{ModelVersion: s.config.Detection.ModelVersion}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDetectionOptions(t *testing.T) {
+	options, err := parseDetectionOptions("")
+	require.NoError(t, err)
+	require.True(t, options.IsZero())
+
+	options, err = parseDetectionOptions(`{"confidence_threshold": 0.4, "model_variant": "s", "classes": ["nike"], "max_detections": 5, "tiling": false}`)
+	require.NoError(t, err)
+	tiling := false
+	require.Equal(t, models.DetectionOptions{
+		ConfidenceThreshold: 0.4,
+		ModelVariant:        "s",
+		Classes:             []string{"nike"},
+		MaxDetections:       5,
+		Tiling:              &tiling,
+	}, options)
+
+	invalid := []string{
+		`not json`,
+		`{"confidence_threshold": 1.5}`,
+		`{"confidence_threshold": -0.1}`,
+		`{"model_variant": "xl"}`,
+		`{"classes": [""]}`,
+		`{"max_detections": 5000}`,
+		`{"confidence": 0.5}`,
+		`{"max_detections": 5}garbage`,
+		`{"max_detections": 5} {"max_detections": 6}`,
+	}
+	for _, raw := range invalid {
+		_, err := parseDetectionOptions(raw)
+		require.Error(t, err, raw)
+	}
+}
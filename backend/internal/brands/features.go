@@ -0,0 +1,51 @@
+package brands
+
+import (
+	"image"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+)
+
+const (
+	// hashWeight and colorWeight balance shape against color in a match score
+	hashWeight  = 0.7
+	colorWeight = 0.3
+	// unrelatedHashDistance is the typical Hamming distance of unrelated images
+	unrelatedHashDistance = 32
+)
+
+// Features describe what a logo image is compared on
+type Features struct {
+	DHash     uint64
+	PHash     uint64
+	Histogram []float64
+}
+
+// ExtractFeatures computes the features of a logo image
+func ExtractFeatures(img image.Image) Features {
+	return Features{
+		DHash:     imaging.DHash(img),
+		PHash:     imaging.PHash(img),
+		Histogram: imaging.ColorHistogram(img),
+	}
+}
+
+// ReferenceFeatures returns the stored features of a brand reference logo
+func ReferenceFeatures(reference db.BrandReference) Features {
+	return Features{
+		DHash:     uint64(reference.Dhash),
+		PHash:     uint64(reference.Phash),
+		Histogram: reference.ColorHistogram,
+	}
+}
+
+// Score rates how likely two images show the same logo, from 0 to 1. Shape
+// is compared with perceptual hashes and color with histogram intersection.
+func Score(a, b Features) float64 {
+	distance := float64(imaging.Distance(a.PHash, b.PHash)+imaging.Distance(a.DHash, b.DHash)) / 2
+	hashScore := max(0, 1-distance/unrelatedHashDistance)
+	colorScore := imaging.HistogramIntersection(a.Histogram, b.Histogram)
+
+	return hashWeight*hashScore + colorWeight*colorScore
+}
@@ -0,0 +1,100 @@
+package brands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Match is the best brand found for a logo
+type Match struct {
+	BrandID     int64
+	ReferenceID int64
+	Score       float64
+}
+
+// Matcher assigns extracted logos to the brand whose reference logos they
+// resemble most
+type Matcher struct {
+	config        utils.BrandConfig
+	store         db.Store
+	storageClient storage.Client
+}
+
+func NewMatcher(config utils.BrandConfig, store db.Store, storageClient storage.Client) *Matcher {
+	return &Matcher{
+		config:        config,
+		store:         store,
+		storageClient: storageClient,
+	}
+}
+
+// MatchLogos compares every logo with the reference logos of all brands and
+// stores the best brand on logos that score at least the configured minimum.
+// Logos that cannot be matched are logged and skipped.
+func (m *Matcher) MatchLogos(ctx context.Context, logos []db.Logo) error {
+	if len(logos) == 0 {
+		return nil
+	}
+
+	references, err := m.store.ListBrandReferences(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list brand references: %w", err)
+	}
+	if len(references) == 0 {
+		return nil
+	}
+
+	for _, logo := range logos {
+		if err := m.matchLogo(ctx, logo, references); err != nil {
+			logrus.WithError(err).WithField("logo_id", logo.ID).Warn("Failed to match logo to a brand")
+		}
+	}
+	return nil
+}
+
+func (m *Matcher) matchLogo(ctx context.Context, logo db.Logo, references []db.BrandReference) error {
+	body, err := m.storageClient.DownloadFile(ctx, logo.S3Key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	img, err := imaging.Decode(body)
+	if err != nil {
+		return err
+	}
+
+	match, ok := BestMatch(ExtractFeatures(img), references)
+	if !ok || match.Score < m.config.MatchMinScore {
+		return nil
+	}
+
+	return m.store.UpdateLogoBrand(ctx, db.UpdateLogoBrandParams{
+		ID:         logo.ID,
+		BrandID:    sql.NullInt64{Int64: match.BrandID, Valid: true},
+		BrandScore: sql.NullFloat64{Float64: match.Score, Valid: true},
+	})
+}
+
+// BestMatch returns the reference logo that scores highest against features
+func BestMatch(features Features, references []db.BrandReference) (Match, bool) {
+	var (
+		best  Match
+		found bool
+	)
+	for _, reference := range references {
+		score := Score(features, ReferenceFeatures(reference))
+		if !found || score > best.Score {
+			best = Match{BrandID: reference.BrandID, ReferenceID: reference.ID, Score: score}
+			found = true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,77 @@
+package brands
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/draw"
+)
+
+// circleLogo draws a filled circle of the given color on white
+func circleLogo(size int, fill color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	center, radius := size/2, size/3
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := x-center, y-center
+			if dx*dx+dy*dy < radius*radius {
+				img.Set(x, y, fill)
+			}
+		}
+	}
+	return img
+}
+
+// barsLogo draws horizontal bars of the given color on white
+func barsLogo(size int, fill color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for y := 0; y < size; y++ {
+		if (y/(size/6))%2 == 1 {
+			draw.Draw(img, image.Rect(0, y, size, y+1), image.NewUniform(fill), image.Point{}, draw.Src)
+		}
+	}
+	return img
+}
+
+func reference(id, brandID int64, img image.Image) db.BrandReference {
+	features := ExtractFeatures(img)
+	return db.BrandReference{
+		ID:             id,
+		BrandID:        brandID,
+		Dhash:          int64(features.DHash),
+		Phash:          int64(features.PHash),
+		ColorHistogram: features.Histogram,
+	}
+}
+
+func TestScore(t *testing.T) {
+	red := color.RGBA{R: 220, G: 20, B: 20, A: 255}
+	logo := ExtractFeatures(circleLogo(128, red))
+
+	require.InDelta(t, 1.0, Score(logo, logo), 1e-9)
+	require.Greater(t, Score(logo, ExtractFeatures(circleLogo(64, red))), 0.9)
+	require.Less(t, Score(logo, ExtractFeatures(barsLogo(128, color.RGBA{B: 200, A: 255}))), 0.6)
+}
+
+func TestBestMatch(t *testing.T) {
+	red := color.RGBA{R: 220, G: 20, B: 20, A: 255}
+	blue := color.RGBA{R: 20, G: 20, B: 200, A: 255}
+	references := []db.BrandReference{
+		reference(1, 10, barsLogo(128, blue)),
+		reference(2, 20, circleLogo(128, red)),
+		reference(3, 30, circleLogo(128, blue)),
+	}
+
+	match, ok := BestMatch(ExtractFeatures(circleLogo(80, red)), references)
+	require.True(t, ok)
+	require.Equal(t, int64(20), match.BrandID)
+	require.Equal(t, int64(2), match.ReferenceID)
+
+	_, ok = BestMatch(ExtractFeatures(circleLogo(80, red)), nil)
+	require.False(t, ok)
+}
@@ -0,0 +1,122 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Purger deletes a job's database rows and every object it owns in storage
+type Purger struct {
+	store         db.Store
+	storageClient storage.Client
+	retrier       *DeleteRetrier
+}
+
+func NewPurger(store db.Store, storageClient storage.Client, retrier *DeleteRetrier) *Purger {
+	return &Purger{
+		store:         store,
+		storageClient: storageClient,
+		retrier:       retrier,
+	}
+}
+
+// PurgeJob removes the job and its logos in one transaction, then deletes the
+// uploaded original, its tiles, extracted crops and overlay. Objects still used by other
+// jobs that reused this job's result are kept. Storage failures do not fail
+// the purge; they are handed to the retrier instead.
+func (p *Purger) PurgeJob(ctx context.Context, jobID uuid.UUID) (db.Job, error) {
+	deleted, err := p.store.DeleteJobTx(ctx, jobID)
+	if err != nil {
+		return deleted.Job, err
+	}
+
+	// A cached job points at crops stored under its source job's prefix
+	keys := []string{}
+	for _, logo := range deleted.Logos {
+		keys = append(keys, logo.S3Key)
+	}
+	prefixes := append(JobPrefixes(deleted.Job), TilePrefixes(deleted.Tiles)...)
+	for _, prefix := range prefixes {
+		listed, err := p.storageClient.ListFiles(ctx, prefix)
+		if err != nil {
+			logrus.WithError(err).WithField("prefix", prefix).Warn("Failed to list objects, scheduling retry")
+			p.enqueue(ctx, prefix)
+			continue
+		}
+		keys = append(keys, listed...)
+	}
+
+	p.deleteUnreferenced(ctx, keys)
+	return deleted.Job, nil
+}
+
+// JobPrefixes returns the storage prefixes holding the objects of a job
+func JobPrefixes(job db.Job) []string {
+	return []string{
+		path.Dir(job.S3Key) + "/",
+		fmt.Sprintf("extracted/%s/", job.ID),
+		fmt.Sprintf("results/%s/", job.ID),
+	}
+}
+
+// TilePrefixes returns the storage prefixes holding the crops workers
+// extracted from the tiles of a tiled job. The tile images themselves are
+// stored under the original's prefix.
+func TilePrefixes(tiles []db.JobTile) []string {
+	prefixes := make([]string, 0, len(tiles))
+	for _, tile := range tiles {
+		prefixes = append(prefixes, fmt.Sprintf("extracted/%s/", tile.ID))
+	}
+	return prefixes
+}
+
+// DeleteObjects deletes stored objects that no database row points to
+// anymore, retrying failed deletions in the background
+func (p *Purger) DeleteObjects(ctx context.Context, keys ...string) {
+	failed := []string{}
+	for _, key := range keys {
+		if err := p.storageClient.DeleteFile(ctx, key); err != nil {
+			logrus.WithError(err).WithField("key", key).Warn("Failed to delete object, scheduling retry")
+			failed = append(failed, key)
+		}
+	}
+	p.enqueue(ctx, failed...)
+}
+
+func (p *Purger) deleteUnreferenced(ctx context.Context, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	referenced, err := p.store.ListReferencedLogoKeys(ctx, keys)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to check for shared logo crops, keeping objects")
+		return
+	}
+	keep := map[string]bool{}
+	for _, key := range referenced {
+		keep[key] = true
+	}
+
+	unreferenced := []string{}
+	for _, key := range keys {
+		if keep[key] {
+			continue
+		}
+		keep[key] = true // skip duplicates
+		unreferenced = append(unreferenced, key)
+	}
+	p.DeleteObjects(ctx, unreferenced...)
+}
+
+func (p *Purger) enqueue(ctx context.Context, keys ...string) {
+	if err := p.retrier.Enqueue(ctx, keys...); err != nil {
+		logrus.WithError(err).WithField("keys", keys).Error("Failed to schedule storage deletion retry")
+	}
+}
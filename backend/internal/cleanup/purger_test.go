@@ -0,0 +1,144 @@
+package cleanup
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// purgeStore deletes one job and reports which keys other jobs still use
+type purgeStore struct {
+	db.Store
+	deleted    db.DeleteJobTxResult
+	referenced []string
+}
+
+func (s *purgeStore) DeleteJobTx(ctx context.Context, jobID uuid.UUID) (db.DeleteJobTxResult, error) {
+	return s.deleted, nil
+}
+
+func (s *purgeStore) ListReferencedLogoKeys(ctx context.Context, keys []string) ([]string, error) {
+	referenced := []string{}
+	for _, key := range keys {
+		for _, ref := range s.referenced {
+			if key == ref {
+				referenced = append(referenced, key)
+			}
+		}
+	}
+	return referenced, nil
+}
+
+func TestJobPrefixes(t *testing.T) {
+	jobID := uuid.MustParse("6f1c7c52-2b8e-4c1e-9a55-0f3f2b9c8d11")
+	tileIDs := []uuid.UUID{uuid.MustParse("0a5e7c52-2b8e-4c1e-9a55-0f3f2b9c8d11"), uuid.MustParse("1b5e7c52-2b8e-4c1e-9a55-0f3f2b9c8d11")}
+
+	tests := []struct {
+		name     string
+		job      db.Job
+		tiles    []db.JobTile
+		prefixes []string
+	}{
+		{
+			name: "job",
+			job:  db.Job{ID: jobID, S3Key: "original/" + jobID.String() + "/image.png"},
+			prefixes: []string{
+				"original/" + jobID.String() + "/",
+				"extracted/" + jobID.String() + "/",
+				"results/" + jobID.String() + "/",
+			},
+		},
+		{
+			name:  "tiled job",
+			job:   db.Job{ID: jobID, S3Key: "original/" + jobID.String() + "/image.png"},
+			tiles: []db.JobTile{{ID: tileIDs[0], JobID: jobID}, {ID: tileIDs[1], JobID: jobID}},
+			prefixes: []string{
+				"original/" + jobID.String() + "/",
+				"extracted/" + jobID.String() + "/",
+				"results/" + jobID.String() + "/",
+				"extracted/" + tileIDs[0].String() + "/",
+				"extracted/" + tileIDs[1].String() + "/",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.prefixes, append(JobPrefixes(test.job), TilePrefixes(test.tiles)...))
+		})
+	}
+}
+
+func TestPurgeJob(t *testing.T) {
+	source := uuid.New()
+	job := db.Job{ID: source, S3Key: "original/" + source.String() + "/image.png"}
+	tile := db.JobTile{ID: uuid.New(), JobID: source}
+	shared := "extracted/" + source.String() + "/logo_0.png"
+	own := "extracted/" + source.String() + "/logo_1.png"
+
+	tests := []struct {
+		name       string
+		referenced []string
+		left       []string
+	}{
+		{
+			name: "unshared",
+			left: []string{"original/other/image.png"},
+		},
+		{
+			// A cached copy of the job still shows the first crop
+			name:       "crop used by a cached copy",
+			referenced: []string{shared},
+			left:       []string{"original/other/image.png", shared},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storageClient := newMemoryStorage(
+				job.S3Key,
+				"original/"+source.String()+"/tiles/tile_0.png",
+				shared,
+				own,
+				"results/"+source.String()+"/overlay.png",
+				"extracted/"+tile.ID.String()+"/logo_0.png",
+				"original/other/image.png",
+			)
+			store := &purgeStore{
+				deleted: db.DeleteJobTxResult{
+					Job:   job,
+					Logos: []db.Logo{{S3Key: shared}, {S3Key: own}},
+					Tiles: []db.JobTile{tile},
+				},
+				referenced: test.referenced,
+			}
+			retrier, redisClient := newTestRetrier(t, storageClient)
+			purger := NewPurger(store, storageClient, retrier)
+
+			deleted, err := purger.PurgeJob(context.Background(), job.ID)
+			require.NoError(t, err)
+			require.Equal(t, job.ID, deleted.ID)
+
+			left, err := storageClient.ListFiles(context.Background(), "")
+			require.NoError(t, err)
+			require.ElementsMatch(t, test.left, left)
+			require.Empty(t, pending(t, redisClient))
+		})
+	}
+}
+
+func TestPurgeJobSchedulesFailedDeletes(t *testing.T) {
+	jobID := uuid.New()
+	job := db.Job{ID: jobID, S3Key: "original/" + jobID.String() + "/image.png"}
+	storageClient := newMemoryStorage(job.S3Key)
+	storageClient.failing[job.S3Key] = true
+	retrier, redisClient := newTestRetrier(t, storageClient)
+	purger := NewPurger(&purgeStore{deleted: db.DeleteJobTxResult{Job: job}}, storageClient, retrier)
+
+	_, err := purger.PurgeJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{job.S3Key}, pending(t, redisClient))
+}
@@ -0,0 +1,139 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pendingDeletesKey = "logo-preserve:storage:pending-deletes"
+	deleteAttemptsKey = "logo-preserve:storage:delete-attempts"
+	maxDeleteAttempts = 10
+	baseRetryBackoff  = 30 * time.Second
+	retryPollInterval = 30 * time.Second
+	retryBatchSize    = 100
+)
+
+// DeleteRetrier keeps storage deletions that failed in Redis and retries them
+// in the background with exponential backoff. Entries ending in "/" are
+// treated as prefixes and expanded with ListFiles before deleting.
+type DeleteRetrier struct {
+	redisClient   *redis.Client
+	storageClient storage.Client
+}
+
+func NewDeleteRetrier(redisClient *redis.Client, storageClient storage.Client) *DeleteRetrier {
+	return &DeleteRetrier{
+		redisClient:   redisClient,
+		storageClient: storageClient,
+	}
+}
+
+// Enqueue schedules keys (or prefixes) for a later deletion attempt
+func (r *DeleteRetrier) Enqueue(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	next := float64(time.Now().Add(baseRetryBackoff).Unix())
+	members := make([]redis.Z, 0, len(keys))
+	for _, key := range keys {
+		members = append(members, redis.Z{Score: next, Member: key})
+	}
+
+	return r.redisClient.ZAddNX(ctx, pendingDeletesKey, members...).Err()
+}
+
+// Run retries due deletions until ctx is cancelled
+func (r *DeleteRetrier) Run(ctx context.Context) {
+	ticker := time.NewTicker(retryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.retryDue(ctx); err != nil {
+				logrus.WithError(err).Error("Failed to retry pending storage deletions")
+			}
+		}
+	}
+}
+
+func (r *DeleteRetrier) retryDue(ctx context.Context) error {
+	keys, err := r.redisClient.ZRangeByScore(ctx, pendingDeletesKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().Unix(), 10),
+		Count: retryBatchSize,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to load pending deletions: %w", err)
+	}
+
+	for _, key := range keys {
+		if err := r.delete(ctx, key); err != nil {
+			r.reschedule(ctx, key, err)
+			continue
+		}
+
+		r.redisClient.ZRem(ctx, pendingDeletesKey, key)
+		r.redisClient.HDel(ctx, deleteAttemptsKey, key)
+	}
+
+	return nil
+}
+
+func (r *DeleteRetrier) delete(ctx context.Context, key string) error {
+	if !strings.HasSuffix(key, "/") {
+		return r.storageClient.DeleteFile(ctx, key)
+	}
+
+	objects, err := r.storageClient.ListFiles(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	// Expand the prefix so only the objects that still fail stay queued
+	failed := []string{}
+	for _, object := range objects {
+		if err := r.storageClient.DeleteFile(ctx, object); err != nil {
+			failed = append(failed, object)
+		}
+	}
+
+	return r.Enqueue(ctx, failed...)
+}
+
+func (r *DeleteRetrier) reschedule(ctx context.Context, key string, cause error) {
+	attempts, err := r.redisClient.HIncrBy(ctx, deleteAttemptsKey, key, 1).Result()
+	if err != nil {
+		logrus.WithError(err).WithField("key", key).Error("Failed to record storage deletion attempt")
+		return
+	}
+
+	if attempts >= maxDeleteAttempts {
+		logrus.WithError(cause).WithField("key", key).Error("Giving up on storage deletion")
+		r.redisClient.ZRem(ctx, pendingDeletesKey, key)
+		r.redisClient.HDel(ctx, deleteAttemptsKey, key)
+		return
+	}
+
+	backoff := baseRetryBackoff * time.Duration(math.Pow(2, float64(attempts)))
+	r.redisClient.ZAdd(ctx, pendingDeletesKey, redis.Z{
+		Score:  float64(time.Now().Add(backoff).Unix()),
+		Member: key,
+	})
+	logrus.WithError(cause).WithFields(logrus.Fields{
+		"key":      key,
+		"attempts": attempts,
+	}).Warn("Storage deletion failed, rescheduled")
+}
@@ -0,0 +1,173 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStorage keeps objects by key, deleting a key in failing errors
+type memoryStorage struct {
+	storage.Client
+	files   map[string]bool
+	failing map[string]bool
+}
+
+func newMemoryStorage(keys ...string) *memoryStorage {
+	files := map[string]bool{}
+	for _, key := range keys {
+		files[key] = true
+	}
+	return &memoryStorage{files: files, failing: map[string]bool{}}
+}
+
+func (s *memoryStorage) ListFiles(ctx context.Context, prefix string) ([]string, error) {
+	keys := []string{}
+	for key := range s.files {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s *memoryStorage) DeleteFile(ctx context.Context, key string) error {
+	if s.failing[key] {
+		return errors.New("service unavailable")
+	}
+	delete(s.files, key)
+	return nil
+}
+
+func newTestRetrier(t *testing.T, storageClient storage.Client) (*DeleteRetrier, *redis.Client) {
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	return NewDeleteRetrier(redisClient, storageClient), redisClient
+}
+
+// makeDue moves every pending deletion to now
+func makeDue(t *testing.T, redisClient *redis.Client) {
+	keys, err := redisClient.ZRange(context.Background(), pendingDeletesKey, 0, -1).Result()
+	require.NoError(t, err)
+	for _, key := range keys {
+		require.NoError(t, redisClient.ZAdd(context.Background(), pendingDeletesKey, redis.Z{Score: 0, Member: key}).Err())
+	}
+}
+
+func pending(t *testing.T, redisClient *redis.Client) []string {
+	keys, err := redisClient.ZRange(context.Background(), pendingDeletesKey, 0, -1).Result()
+	require.NoError(t, err)
+	return keys
+}
+
+func TestRetryDue(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		failing []string
+		enqueue string
+		left    []string
+		pending []string
+	}{
+		{
+			name:    "key",
+			files:   []string{"extracted/1/logo_0.png"},
+			enqueue: "extracted/1/logo_0.png",
+			left:    []string{},
+			pending: []string{},
+		},
+		{
+			name:    "prefix",
+			files:   []string{"extracted/1/logo_0.png", "extracted/1/logo_1.png", "extracted/2/logo_0.png"},
+			enqueue: "extracted/1/",
+			left:    []string{"extracted/2/logo_0.png"},
+			pending: []string{},
+		},
+		{
+			// Only the object that still fails stays queued, not the prefix
+			name:    "prefix with failing object",
+			files:   []string{"extracted/1/logo_0.png", "extracted/1/logo_1.png"},
+			failing: []string{"extracted/1/logo_1.png"},
+			enqueue: "extracted/1/",
+			left:    []string{"extracted/1/logo_1.png"},
+			pending: []string{"extracted/1/logo_1.png"},
+		},
+		{
+			name:    "failing key",
+			files:   []string{"extracted/1/logo_0.png"},
+			failing: []string{"extracted/1/logo_0.png"},
+			enqueue: "extracted/1/logo_0.png",
+			left:    []string{"extracted/1/logo_0.png"},
+			pending: []string{"extracted/1/logo_0.png"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storageClient := newMemoryStorage(test.files...)
+			for _, key := range test.failing {
+				storageClient.failing[key] = true
+			}
+			retrier, redisClient := newTestRetrier(t, storageClient)
+
+			require.NoError(t, retrier.Enqueue(context.Background(), test.enqueue))
+			makeDue(t, redisClient)
+			require.NoError(t, retrier.retryDue(context.Background()))
+
+			left, err := storageClient.ListFiles(context.Background(), "")
+			require.NoError(t, err)
+			require.ElementsMatch(t, test.left, left)
+			require.ElementsMatch(t, test.pending, pending(t, redisClient))
+		})
+	}
+}
+
+func TestRetryDueIsNotEarly(t *testing.T) {
+	storageClient := newMemoryStorage("extracted/1/logo_0.png")
+	retrier, redisClient := newTestRetrier(t, storageClient)
+
+	require.NoError(t, retrier.Enqueue(context.Background(), "extracted/1/logo_0.png"))
+	require.NoError(t, retrier.retryDue(context.Background()))
+	require.True(t, storageClient.files["extracted/1/logo_0.png"])
+	require.Equal(t, []string{"extracted/1/logo_0.png"}, pending(t, redisClient))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	const key = "extracted/1/logo_0.png"
+	storageClient := newMemoryStorage(key)
+	storageClient.failing[key] = true
+	retrier, redisClient := newTestRetrier(t, storageClient)
+	ctx := context.Background()
+
+	require.NoError(t, retrier.Enqueue(ctx, key))
+	for attempt := 1; attempt < maxDeleteAttempts; attempt++ {
+		makeDue(t, redisClient)
+		require.NoError(t, retrier.retryDue(ctx))
+
+		attempts, err := redisClient.HGet(ctx, deleteAttemptsKey, key).Int()
+		require.NoError(t, err)
+		require.Equal(t, attempt, attempts)
+
+		// Each attempt doubles the wait
+		score, err := redisClient.ZScore(ctx, pendingDeletesKey, key).Result()
+		require.NoError(t, err)
+		backoff := baseRetryBackoff << attempt
+		require.WithinDuration(t, time.Now().Add(backoff), time.Unix(int64(score), 0), 2*time.Second)
+	}
+
+	// The last attempt gives up and forgets the key
+	makeDue(t, redisClient)
+	require.NoError(t, retrier.retryDue(ctx))
+	require.Empty(t, pending(t, redisClient))
+	exists, err := redisClient.HExists(ctx, deleteAttemptsKey, key).Result()
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.True(t, storageClient.files[key])
+}
@@ -0,0 +1,115 @@
+// Package composition places extracted logos onto target images. Logos are
+// scaled, rotated and alpha-blended over the target with feathered edges.
+package composition
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+)
+
+// Layer is a logo and where to place it
+type Layer struct {
+	Logo      image.Image
+	Placement models.Placement
+}
+
+// Composite returns a copy of target with the layers blended over it in order
+func Composite(target image.Image, layers []Layer) *image.RGBA {
+	bounds := target.Bounds()
+	canvas := image.NewRGBA(bounds)
+	draw.Draw(canvas, bounds, target, bounds.Min, draw.Src)
+
+	for _, layer := range layers {
+		blend(canvas, layer)
+	}
+	return canvas
+}
+
+// blend maps every target pixel the placed logo covers back into the logo
+// and draws the bilinear sample over it
+func blend(canvas *image.RGBA, layer Layer) {
+	p := layer.Placement
+	logoBounds := layer.Logo.Bounds()
+	if p.Scale <= 0 || p.Opacity <= 0 || logoBounds.Empty() {
+		return
+	}
+
+	logo := image.NewRGBA(image.Rect(0, 0, logoBounds.Dx(), logoBounds.Dy()))
+	draw.Draw(logo, logo.Bounds(), layer.Logo, logoBounds.Min, draw.Src)
+
+	halfWidth := float64(logoBounds.Dx()) * p.Scale / 2
+	halfHeight := float64(logoBounds.Dy()) * p.Scale / 2
+	sin, cos := math.Sincos(p.Rotation * math.Pi / 180)
+
+	// The rotated logo fits in a circle around its center
+	radius := math.Hypot(halfWidth, halfHeight)
+	centerX := float64(canvas.Rect.Min.X) + p.X
+	centerY := float64(canvas.Rect.Min.Y) + p.Y
+	area := image.Rect(
+		int(math.Floor(centerX-radius)), int(math.Floor(centerY-radius)),
+		int(math.Ceil(centerX+radius)), int(math.Ceil(centerY+radius)),
+	).Intersect(canvas.Rect)
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			dx, dy := float64(x)+0.5-centerX, float64(y)+0.5-centerY
+			// Undo the clockwise rotation to get logo-aligned offsets
+			u := cos*dx + sin*dy
+			v := -sin*dx + cos*dy
+			if math.Abs(u) >= halfWidth || math.Abs(v) >= halfHeight {
+				continue
+			}
+
+			weight := p.Opacity
+			if p.Feather > 0 {
+				edge := min(halfWidth-math.Abs(u), halfHeight-math.Abs(v))
+				weight *= min(edge/p.Feather, 1)
+			}
+
+			r, g, b, a := sample(logo, (u+halfWidth)/p.Scale, (v+halfHeight)/p.Scale)
+			r, g, b, a = r*weight, g*weight, b*weight, a*weight
+			if a <= 0 {
+				continue
+			}
+
+			// Porter-Duff "over" with premultiplied colors
+			i := canvas.PixOffset(x, y)
+			pix := canvas.Pix[i : i+4 : i+4]
+			pix[0] = uint8(math.Round(r + float64(pix[0])*(1-a/255)))
+			pix[1] = uint8(math.Round(g + float64(pix[1])*(1-a/255)))
+			pix[2] = uint8(math.Round(b + float64(pix[2])*(1-a/255)))
+			pix[3] = uint8(math.Round(a + float64(pix[3])*(1-a/255)))
+		}
+	}
+}
+
+// sample interpolates the premultiplied color of img at the continuous
+// position x, y, clamping to the edge pixels
+func sample(img *image.RGBA, x, y float64) (r, g, b, a float64) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	x, y = x-0.5, y-0.5
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	var channels [4]float64
+	for _, corner := range [4]struct {
+		dx, dy int
+		weight float64
+	}{
+		{0, 0, (1 - fx) * (1 - fy)},
+		{1, 0, fx * (1 - fy)},
+		{0, 1, (1 - fx) * fy},
+		{1, 1, fx * fy},
+	} {
+		px := min(max(x0+corner.dx, 0), width-1)
+		py := min(max(y0+corner.dy, 0), height-1)
+		i := img.PixOffset(px, py)
+		for c := range channels {
+			channels[c] += float64(img.Pix[i+c]) * corner.weight
+		}
+	}
+	return channels[0], channels[1], channels[2], channels[3]
+}
@@ -0,0 +1,73 @@
+package composition
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func uniform(width, height int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+var (
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red   = color.RGBA{R: 255, A: 255}
+)
+
+func TestCompositePlacesOpaqueLogo(t *testing.T) {
+	target := uniform(100, 100, white)
+	layer := Layer{
+		Logo:      uniform(20, 10, red),
+		Placement: models.Placement{X: 50, Y: 50, Scale: 2, Opacity: 1},
+	}
+
+	composite := Composite(target, []Layer{layer})
+
+	// Scaled to 40x20 around the center
+	require.Equal(t, red, composite.RGBAAt(31, 41))
+	require.Equal(t, red, composite.RGBAAt(68, 58))
+	require.Equal(t, white, composite.RGBAAt(29, 50))
+	require.Equal(t, white, composite.RGBAAt(50, 39))
+	require.Equal(t, white, target.RGBAAt(50, 50))
+}
+
+func TestCompositeRotatesLogo(t *testing.T) {
+	target := uniform(100, 100, white)
+	layer := Layer{
+		Logo:      uniform(40, 10, red),
+		Placement: models.Placement{X: 50, Y: 50, Scale: 1, Rotation: 90, Opacity: 1},
+	}
+
+	composite := Composite(target, []Layer{layer})
+
+	// A quarter turn makes the wide logo tall
+	require.Equal(t, red, composite.RGBAAt(50, 32))
+	require.Equal(t, white, composite.RGBAAt(32, 50))
+}
+
+func TestCompositeBlendsOpacityAndFeather(t *testing.T) {
+	target := uniform(100, 100, white)
+	layer := Layer{
+		Logo:      uniform(60, 60, red),
+		Placement: models.Placement{X: 50, Y: 50, Scale: 1, Opacity: 0.5, Feather: 10},
+	}
+
+	composite := Composite(target, []Layer{layer})
+
+	center := composite.RGBAAt(50, 50)
+	require.Equal(t, uint8(255), center.R)
+	require.InDelta(t, 128, int(center.G), 1)
+	require.Equal(t, uint8(255), center.A)
+
+	// Closer to the edge, more of the target shows through
+	edge := composite.RGBAAt(21, 50)
+	require.Greater(t, edge.G, center.G)
+	require.Less(t, edge.G, uint8(255))
+}
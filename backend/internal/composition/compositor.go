@@ -0,0 +1,147 @@
+package composition
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"path"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// TargetKey is where the uploaded target image of a composition is stored
+func TargetKey(compositionID uuid.UUID, filename string) string {
+	return fmt.Sprintf("composite/%s/target%s", compositionID, path.Ext(filename))
+}
+
+// ResultKey is where the finished composite is stored
+func ResultKey(compositionID uuid.UUID) string {
+	return fmt.Sprintf("composite/%s/composite.png", compositionID)
+}
+
+// ErrInvalidComposition is returned by Render for compositions that can never
+// be rendered, e.g. a placed logo that no longer exists or an image that does
+// not decode
+var ErrInvalidComposition = errors.New("invalid composition")
+
+// Compositor renders queued compositions
+type Compositor struct {
+	store         db.Store
+	storageClient storage.Client
+}
+
+func NewCompositor(store db.Store, storageClient storage.Client) *Compositor {
+	return &Compositor{
+		store:         store,
+		storageClient: storageClient,
+	}
+}
+
+// Handle is the queue handler for CompositionMessage messages. An invalid
+// composition is marked failed; any other error is returned, which requeues
+// the message so the composition is claimed and rendered again.
+func (c *Compositor) Handle(message *models.CompositionMessage) error {
+	ctx := context.Background()
+
+	composition, err := c.store.ClaimComposition(ctx, message.CompositionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		logrus.WithField("composition_id", message.CompositionID).Warn("Ignoring unknown or finished composition")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	key, err := c.Render(ctx, composition)
+	if err != nil && !errors.Is(err, ErrInvalidComposition) {
+		return err
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("composition_id", composition.ID).Warn("Composition failed")
+		_, err = c.store.FailComposition(ctx, db.FailCompositionParams{
+			ID:           composition.ID,
+			ErrorMessage: sql.NullString{String: err.Error(), Valid: true},
+		})
+		return err
+	}
+
+	_, err = c.store.CompleteComposition(ctx, db.CompleteCompositionParams{
+		ID:    composition.ID,
+		S3Key: sql.NullString{String: key, Valid: true},
+	})
+	if err == nil {
+		logrus.WithField("composition_id", composition.ID).Info("Composition completed")
+	}
+	return err
+}
+
+// Render blends the placed logos over the target image and uploads the
+// composite, returning its key
+func (c *Compositor) Render(ctx context.Context, composition db.Composition) (string, error) {
+	var placements []models.Placement
+	if err := json.Unmarshal(composition.Placements, &placements); err != nil {
+		return "", fmt.Errorf("%w: invalid placements: %w", ErrInvalidComposition, err)
+	}
+
+	target, err := c.download(ctx, composition.TargetKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to load target image: %w", err)
+	}
+
+	layers := make([]Layer, 0, len(placements))
+	for _, placement := range placements {
+		logo, err := c.store.GetLogo(ctx, placement.LogoID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%w: logo %d not found", ErrInvalidComposition, placement.LogoID)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get logo %d: %w", placement.LogoID, err)
+		}
+		// The masked crop leaves the surface behind the logo out
+		key := logo.S3Key
+		if logo.MaskedS3Key.Valid {
+			key = logo.MaskedS3Key.String
+		}
+		img, err := c.download(ctx, key)
+		if err != nil {
+			return "", fmt.Errorf("failed to load logo %d: %w", placement.LogoID, err)
+		}
+		layers = append(layers, Layer{Logo: img, Placement: placement})
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, Composite(target, layers)); err != nil {
+		return "", fmt.Errorf("%w: failed to encode composite: %w", ErrInvalidComposition, err)
+	}
+
+	key := ResultKey(composition.ID)
+	if _, err := c.storageClient.UploadFile(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// download fetches and decodes an image, an image that does not decode is
+// invalid while storage errors are worth retrying
+func (c *Compositor) download(ctx context.Context, key string) (image.Image, error) {
+	body, err := c.storageClient.DownloadFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	img, err := imaging.Decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidComposition, err)
+	}
+	return img, nil
+}
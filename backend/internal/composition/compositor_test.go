@@ -0,0 +1,162 @@
+package composition
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"image/png"
+	"io"
+	"testing"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type compositionStore struct {
+	db.Store
+	composition db.Composition
+	logos       map[int64]db.Logo
+	getLogoErr  error
+	failed      bool
+	completed   bool
+}
+
+func (s *compositionStore) ClaimComposition(ctx context.Context, id uuid.UUID) (db.Composition, error) {
+	return s.composition, nil
+}
+
+func (s *compositionStore) GetLogo(ctx context.Context, id int64) (db.Logo, error) {
+	if s.getLogoErr != nil {
+		return db.Logo{}, s.getLogoErr
+	}
+	logo, ok := s.logos[id]
+	if !ok {
+		return db.Logo{}, sql.ErrNoRows
+	}
+	return logo, nil
+}
+
+func (s *compositionStore) FailComposition(ctx context.Context, arg db.FailCompositionParams) (db.Composition, error) {
+	s.failed = true
+	return s.composition, nil
+}
+
+func (s *compositionStore) CompleteComposition(ctx context.Context, arg db.CompleteCompositionParams) (db.Composition, error) {
+	s.completed = true
+	return s.composition, nil
+}
+
+type compositionStorage struct {
+	storage.Client
+	files       map[string][]byte
+	downloadErr error
+	uploadErr   error
+}
+
+func (s *compositionStorage) DownloadFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	if s.downloadErr != nil {
+		return nil, s.downloadErr
+	}
+	return io.NopCloser(bytes.NewReader(s.files[key])), nil
+}
+
+func (s *compositionStorage) UploadFile(ctx context.Context, key string, file io.Reader, size int64) (*storage.UploadOutput, error) {
+	if s.uploadErr != nil {
+		return nil, s.uploadErr
+	}
+	return &storage.UploadOutput{}, nil
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, uniform(width, height, red)))
+	return buf.Bytes()
+}
+
+func TestHandleFailsOnlyInvalidCompositions(t *testing.T) {
+	unavailable := errors.New("connection reset")
+
+	tests := []struct {
+		name       string
+		placements string
+		files      map[string][]byte
+		store      func(*compositionStore)
+		storage    func(*compositionStorage)
+		wantErr    bool
+		wantFailed bool
+	}{
+		{name: "completed"},
+		{
+			name:    "download fails",
+			storage: func(s *compositionStorage) { s.downloadErr = unavailable },
+			wantErr: true,
+		},
+		{
+			name:    "upload fails",
+			storage: func(s *compositionStorage) { s.uploadErr = unavailable },
+			wantErr: true,
+		},
+		{
+			name:    "logo lookup fails",
+			store:   func(s *compositionStore) { s.getLogoErr = unavailable },
+			wantErr: true,
+		},
+		{
+			name:       "logo deleted",
+			store:      func(s *compositionStore) { s.logos = nil },
+			wantFailed: true,
+		},
+		{
+			name:       "target does not decode",
+			files:      map[string][]byte{"target.png": []byte("not an image")},
+			wantFailed: true,
+		},
+		{
+			name:       "invalid placements",
+			placements: `{"logo_id": 1}`,
+			wantFailed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placements := tt.placements
+			if placements == "" {
+				encoded, err := json.Marshal([]models.Placement{{LogoID: 1, X: 10, Y: 10, Scale: 1, Opacity: 1}})
+				require.NoError(t, err)
+				placements = string(encoded)
+			}
+			store := &compositionStore{
+				composition: db.Composition{ID: uuid.New(), TargetKey: "target.png", Placements: json.RawMessage(placements)},
+				logos:       map[int64]db.Logo{1: {ID: 1, S3Key: "logo.png"}},
+			}
+			files := &compositionStorage{files: map[string][]byte{
+				"target.png": encodePNG(t, 40, 40),
+				"logo.png":   encodePNG(t, 4, 4),
+			}}
+			for key, data := range tt.files {
+				files.files[key] = data
+			}
+			if tt.store != nil {
+				tt.store(store)
+			}
+			if tt.storage != nil {
+				tt.storage(files)
+			}
+
+			err := NewCompositor(store, files).Handle(&models.CompositionMessage{CompositionID: store.composition.ID})
+			if tt.wantErr {
+				require.ErrorIs(t, err, unavailable)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantFailed, store.failed)
+			require.Equal(t, !tt.wantErr && !tt.wantFailed, store.completed)
+		})
+	}
+}
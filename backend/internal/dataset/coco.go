@@ -0,0 +1,215 @@
+package dataset
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+type cocoFile struct {
+	Info        cocoInfo         `json:"info"`
+	Images      []cocoImage      `json:"images"`
+	Annotations []cocoAnnotation `json:"annotations"`
+	Categories  []cocoCategory   `json:"categories"`
+}
+
+type cocoInfo struct {
+	Description string `json:"description"`
+}
+
+type cocoImage struct {
+	ID       int    `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type cocoAnnotation struct {
+	ID         int    `json:"id"`
+	ImageID    int    `json:"image_id"`
+	CategoryID int    `json:"category_id"`
+	BBox       [4]int `json:"bbox"`
+	Area       int    `json:"area"`
+	IsCrowd    int    `json:"iscrowd"`
+}
+
+type cocoCategory struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Supercategory string `json:"supercategory"`
+}
+
+// cocoWriter writes images/<split>/ and one annotations/instances_<split>.json per split
+type cocoWriter struct{}
+
+func (cocoWriter) ImagePath(img Image) string {
+	return fmt.Sprintf("images/%s/%s", img.Split, img.Name)
+}
+
+func (cocoWriter) WriteAnnotations(archive *zip.Writer, ds Dataset) error {
+	categories := make([]cocoCategory, 0, len(ds.Categories))
+	for i, name := range ds.Categories {
+		// COCO category IDs start at 1
+		categories = append(categories, cocoCategory{ID: i + 1, Name: name, Supercategory: "logo"})
+	}
+
+	// Image and annotation IDs are unique across splits
+	imageID, annotationID := 0, 0
+	for _, split := range []string{SplitTrain, SplitVal} {
+		file := cocoFile{
+			Info:        cocoInfo{Description: fmt.Sprintf("Logo detection dataset (%s)", split)},
+			Images:      []cocoImage{},
+			Annotations: []cocoAnnotation{},
+			Categories:  categories,
+		}
+		for _, img := range ds.Images {
+			if img.Split != split {
+				continue
+			}
+			imageID++
+			file.Images = append(file.Images, cocoImage{ID: imageID, FileName: img.Name, Width: img.Width, Height: img.Height})
+			for _, annotation := range img.Annotations {
+				annotationID++
+				box := annotation.Box
+				file.Annotations = append(file.Annotations, cocoAnnotation{
+					ID:         annotationID,
+					ImageID:    imageID,
+					CategoryID: ds.CategoryIndex(annotation.Category) + 1,
+					BBox:       [4]int{box.X, box.Y, box.Width, box.Height},
+					Area:       box.Width * box.Height,
+				})
+			}
+		}
+
+		data, err := json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeFile(archive, fmt.Sprintf("annotations/instances_%s.json", split), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type cocoInput struct {
+	Images      []cocoImage           `json:"images"`
+	Annotations []cocoInputAnnotation `json:"annotations"`
+	Categories  []cocoCategory        `json:"categories"`
+}
+
+type cocoInputAnnotation struct {
+	ImageID    int       `json:"image_id"`
+	CategoryID int       `json:"category_id"`
+	BBox       []float64 `json:"bbox"`
+	IsCrowd    int       `json:"iscrowd"`
+}
+
+// readCOCO reads every COCO annotation file in fsys. Images are looked up by
+// their file_name, either as a path from the root or by base name.
+func readCOCO(fsys fs.FS) ([]Image, error) {
+	files, err := walkFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	imagesByBase := map[string][]string{}
+	for _, name := range files {
+		if isImageFile(name) {
+			imagesByBase[path.Base(name)] = append(imagesByBase[path.Base(name)], name)
+		}
+	}
+
+	var images []Image
+	for _, name := range files {
+		if path.Ext(name) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var input cocoInput
+		if err := json.Unmarshal(data, &input); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if len(input.Images) == 0 {
+			// Not an annotation file
+			continue
+		}
+
+		categories := map[int]string{}
+		for _, category := range input.Categories {
+			categories[category.ID] = category.Name
+		}
+
+		byID := map[int]int{}
+		start := len(images)
+		for _, img := range input.Images {
+			imagePath, err := resolveCOCOImage(fsys, imagesByBase, img.FileName)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			byID[img.ID] = len(images)
+			images = append(images, Image{Name: imagePath, Width: img.Width, Height: img.Height})
+		}
+
+		for _, annotation := range input.Annotations {
+			if annotation.IsCrowd != 0 {
+				continue
+			}
+			index, ok := byID[annotation.ImageID]
+			if !ok {
+				return nil, fmt.Errorf("%s: annotation references unknown image %d", name, annotation.ImageID)
+			}
+			category, ok := categories[annotation.CategoryID]
+			if !ok {
+				return nil, fmt.Errorf("%s: annotation references unknown category %d", name, annotation.CategoryID)
+			}
+			if len(annotation.BBox) != 4 {
+				return nil, fmt.Errorf("%s: annotation bbox must have 4 values", name)
+			}
+			x, y, w, h := annotation.BBox[0], annotation.BBox[1], annotation.BBox[2], annotation.BBox[3]
+			images[index].Annotations = append(images[index].Annotations, Annotation{
+				Category: category,
+				Box:      pixelBox(x, y, x+w, y+h),
+			})
+		}
+
+		// Some exports omit the size, fall back to the image itself
+		for i := start; i < len(images); i++ {
+			if images[i].Width > 0 && images[i].Height > 0 {
+				continue
+			}
+			images[i].Width, images[i].Height, err = imageSize(fsys, images[i].Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(images) == 0 {
+		return nil, errors.New("no COCO annotation file found")
+	}
+	return images, nil
+}
+
+func resolveCOCOImage(fsys fs.FS, imagesByBase map[string][]string, fileName string) (string, error) {
+	if _, err := fs.Stat(fsys, fileName); err == nil {
+		return fileName, nil
+	}
+	candidates := imagesByBase[path.Base(fileName)]
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("image %q not found", fileName)
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("image %q is ambiguous, found %s", fileName, strings.Join(candidates, ", "))
+	}
+}
@@ -0,0 +1,163 @@
+package dataset
+
+import (
+	"archive/zip"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"path"
+	"strings"
+	"time"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Export formats
+const (
+	FormatCOCO = "coco"
+	FormatYOLO = "yolo"
+	FormatVOC  = "voc"
+)
+
+// Export statuses
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+)
+
+// Dataset splits
+const (
+	SplitTrain = "train"
+	SplitVal   = "val"
+)
+
+// Filters select the jobs and logos that go into an export. Only completed
+// jobs are exported and logos rejected during review are always left out.
+type Filters struct {
+	JobIDs        []uuid.UUID `json:"job_ids,omitempty"`
+	CreatedAfter  *time.Time  `json:"created_after,omitempty"`
+	CreatedBefore *time.Time  `json:"created_before,omitempty"`
+	MinConfidence *float64    `json:"min_confidence,omitempty"`
+	// ReviewedOnly only exports logos a reviewer accepted or corrected
+	ReviewedOnly bool `json:"reviewed_only,omitempty"`
+}
+
+// QueryParams converts the filters to the parameters of ListLogosForExport
+func (f Filters) QueryParams() db.ListLogosForExportParams {
+	params := db.ListLogosForExportParams{
+		JobIds:         f.JobIDs,
+		ReviewStatuses: []string{models.ReviewStatusAccepted, models.ReviewStatusCorrected},
+	}
+	if params.JobIds == nil {
+		params.JobIds = []uuid.UUID{}
+	}
+	if f.CreatedAfter != nil {
+		params.CreatedAfter.Time, params.CreatedAfter.Valid = *f.CreatedAfter, true
+	}
+	if f.CreatedBefore != nil {
+		params.CreatedBefore.Time, params.CreatedBefore.Valid = *f.CreatedBefore, true
+	}
+	if f.MinConfidence != nil {
+		params.MinConfidence.Float64, params.MinConfidence.Valid = *f.MinConfidence, true
+	}
+	if !f.ReviewedOnly {
+		params.ReviewStatuses = append(params.ReviewStatuses, models.ReviewStatusPending)
+	}
+	return params
+}
+
+// Dataset is everything that goes into an export archive
+type Dataset struct {
+	// Categories are the exported logo types, sorted by name. Their position
+	// is the class index in the archive.
+	Categories []string
+	Images     []Image
+}
+
+// Image is one source image and the logos annotated on it
+type Image struct {
+	// Name is the file name of the image in the archive
+	Name        string
+	Width       int
+	Height      int
+	Split       string
+	Annotations []Annotation
+}
+
+// Stem is the image name without its extension
+func (img Image) Stem() string {
+	return strings.TrimSuffix(img.Name, path.Ext(img.Name))
+}
+
+// Annotation is a labeled bounding box in pixels
+type Annotation struct {
+	Category string
+	Box      models.BBox
+}
+
+// CategoryIndex returns the position of category in the dataset's categories
+func (ds Dataset) CategoryIndex(category string) int {
+	for i, name := range ds.Categories {
+		if name == category {
+			return i
+		}
+	}
+	return -1
+}
+
+// Writer lays out a dataset archive in one format
+type Writer interface {
+	// ImagePath is where an image is stored in the archive
+	ImagePath(img Image) string
+	// WriteAnnotations writes the annotation files once all images are added
+	WriteAnnotations(archive *zip.Writer, ds Dataset) error
+}
+
+// NewWriter returns the writer for an export format
+func NewWriter(format string) (Writer, error) {
+	switch format {
+	case FormatCOCO:
+		return cocoWriter{}, nil
+	case FormatYOLO:
+		return yoloWriter{}, nil
+	case FormatVOC:
+		return vocWriter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// SplitFor assigns an image to the train or val split. The split only depends
+// on the job ID, so a job stays in the same split across exports.
+func SplitFor(jobID uuid.UUID, valFraction float64) string {
+	hash := fnv.New32a()
+	hash.Write(jobID[:])
+	if float64(hash.Sum32())/math.MaxUint32 < valFraction {
+		return SplitVal
+	}
+	return SplitTrain
+}
+
+// ClampBox fits a box inside an image of the given size. It reports false
+// when nothing of the box is left.
+func ClampBox(box models.BBox, width, height int) (models.BBox, bool) {
+	x0, y0 := max(box.X, 0), max(box.Y, 0)
+	x1, y1 := min(box.X+box.Width, width), min(box.Y+box.Height, height)
+	if x1 <= x0 || y1 <= y0 {
+		return models.BBox{}, false
+	}
+	return models.BBox{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}, true
+}
+
+func writeFile(archive *zip.Writer, name string, data []byte) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	return err
+}
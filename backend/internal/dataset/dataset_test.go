@@ -0,0 +1,151 @@
+package dataset
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func testDataset() Dataset {
+	return Dataset{
+		Categories: []string{"icon", "wordmark"},
+		Images: []Image{
+			{
+				Name: "first.jpg", Width: 200, Height: 100, Split: SplitTrain,
+				Annotations: []Annotation{
+					{Category: "wordmark", Box: models.BBox{X: 50, Y: 25, Width: 100, Height: 50}},
+					{Category: "icon", Box: models.BBox{X: 0, Y: 0, Width: 20, Height: 10}},
+				},
+			},
+			{
+				Name: "second.png", Width: 100, Height: 100, Split: SplitVal,
+				Annotations: []Annotation{
+					{Category: "icon", Box: models.BBox{X: 10, Y: 10, Width: 30, Height: 30}},
+				},
+			},
+		},
+	}
+}
+
+// writeArchive writes ds with the format's writer and returns the archive's files
+func writeArchive(t *testing.T, format string, ds Dataset) map[string][]byte {
+	writer, err := NewWriter(format)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	require.NoError(t, writer.WriteAnnotations(archive, ds))
+	require.NoError(t, archive.Close())
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[file.Name] = data
+	}
+	return files
+}
+
+func TestCOCOWriter(t *testing.T) {
+	files := writeArchive(t, FormatCOCO, testDataset())
+	require.Contains(t, files, "annotations/instances_val.json")
+
+	var train cocoFile
+	require.NoError(t, json.Unmarshal(files["annotations/instances_train.json"], &train))
+	require.Len(t, train.Images, 1)
+	require.Equal(t, "first.jpg", train.Images[0].FileName)
+	require.Len(t, train.Annotations, 2)
+	require.Equal(t, [4]int{50, 25, 100, 50}, train.Annotations[0].BBox)
+	require.Equal(t, 5000, train.Annotations[0].Area)
+	require.Equal(t, 2, train.Annotations[0].CategoryID)
+	require.Equal(t, []cocoCategory{{1, "icon", "logo"}, {2, "wordmark", "logo"}}, train.Categories)
+
+	var val cocoFile
+	require.NoError(t, json.Unmarshal(files["annotations/instances_val.json"], &val))
+	require.Equal(t, 2, val.Images[0].ID)
+	require.Equal(t, 3, val.Annotations[0].ID)
+
+	writer, err := NewWriter(FormatCOCO)
+	require.NoError(t, err)
+	require.Equal(t, "images/val/second.png", writer.ImagePath(testDataset().Images[1]))
+}
+
+func TestYOLOWriter(t *testing.T) {
+	files := writeArchive(t, FormatYOLO, testDataset())
+
+	require.Equal(t, "1 0.500000 0.500000 0.500000 0.500000\n0 0.050000 0.050000 0.100000 0.100000\n",
+		string(files["labels/train/first.txt"]))
+	require.Equal(t, "0 0.250000 0.250000 0.300000 0.300000\n", string(files["labels/val/second.txt"]))
+	require.Equal(t, "path: .\ntrain: images/train\nval: images/val\nnc: 2\nnames:\n  0: \"icon\"\n  1: \"wordmark\"\n",
+		string(files["data.yaml"]))
+}
+
+func TestVOCWriter(t *testing.T) {
+	files := writeArchive(t, FormatVOC, testDataset())
+
+	var annotation vocAnnotation
+	require.NoError(t, xml.Unmarshal(files["Annotations/first.xml"], &annotation))
+	require.Equal(t, "first.jpg", annotation.Filename)
+	require.Equal(t, vocSize{Width: 200, Height: 100, Depth: 3}, annotation.Size)
+	require.Len(t, annotation.Objects, 2)
+	require.Equal(t, "wordmark", annotation.Objects[0].Name)
+	require.Equal(t, vocBndBox{XMin: 51, YMin: 26, XMax: 150, YMax: 75}, annotation.Objects[0].BndBox)
+
+	require.Equal(t, "first\n", string(files["ImageSets/Main/train.txt"]))
+	require.Equal(t, "second\n", string(files["ImageSets/Main/val.txt"]))
+}
+
+func TestNewWriterRejectsUnknownFormat(t *testing.T) {
+	_, err := NewWriter("csv")
+	require.Error(t, err)
+}
+
+func TestSplitFor(t *testing.T) {
+	val := 0
+	for i := 0; i < 1000; i++ {
+		id := uuid.New()
+		split := SplitFor(id, 0.2)
+		require.Equal(t, split, SplitFor(id, 0.2))
+		if split == SplitVal {
+			val++
+		}
+	}
+	require.InDelta(t, 200, val, 60)
+
+	require.Equal(t, SplitTrain, SplitFor(uuid.New(), 0))
+}
+
+func TestClampBox(t *testing.T) {
+	box, ok := ClampBox(models.BBox{X: -10, Y: 90, Width: 50, Height: 50}, 100, 100)
+	require.True(t, ok)
+	require.Equal(t, models.BBox{X: 0, Y: 90, Width: 40, Height: 10}, box)
+
+	_, ok = ClampBox(models.BBox{X: 120, Y: 0, Width: 10, Height: 10}, 100, 100)
+	require.False(t, ok)
+}
+
+func TestFiltersQueryParams(t *testing.T) {
+	confidence := 0.5
+	params := Filters{MinConfidence: &confidence}.QueryParams()
+	require.Equal(t, []uuid.UUID{}, params.JobIds)
+	require.True(t, params.MinConfidence.Valid)
+	require.False(t, params.CreatedAfter.Valid)
+	require.Contains(t, params.ReviewStatuses, models.ReviewStatusPending)
+	require.NotContains(t, params.ReviewStatuses, models.ReviewStatusRejected)
+
+	params = Filters{ReviewedOnly: true}.QueryParams()
+	require.ElementsMatch(t, []string{models.ReviewStatusAccepted, models.ReviewStatusCorrected}, params.ReviewStatuses)
+}
@@ -0,0 +1,235 @@
+package dataset
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNoLogos is returned when no logo matches an export's filters
+var ErrNoLogos = errors.New("no logos match the export filters")
+
+// Exporter builds the dataset archives of pending exports. Exports are
+// claimed with SKIP LOCKED, so every replica can run one.
+type Exporter struct {
+	config        utils.ExportConfig
+	store         db.Store
+	storageClient storage.Client
+}
+
+func NewExporter(config utils.ExportConfig, store db.Store, storageClient storage.Client) *Exporter {
+	return &Exporter{
+		config:        config,
+		store:         store,
+		storageClient: storageClient,
+	}
+}
+
+// Run processes pending exports on every interval until ctx is cancelled
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for ctx.Err() == nil && e.ExportNext(ctx) {
+			}
+		}
+	}
+}
+
+// ExportNext claims and builds one export. It reports whether an export was claimed.
+func (e *Exporter) ExportNext(ctx context.Context) bool {
+	export, err := e.store.ClaimDatasetExport(ctx, time.Now().Add(-e.config.Timeout))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logrus.WithError(err).Error("Failed to claim dataset export")
+		}
+		return false
+	}
+
+	log := logrus.WithFields(logrus.Fields{"export_id": export.ID, "format": export.Format})
+	if err := e.Export(ctx, export); err != nil {
+		log.WithError(err).Error("Dataset export failed")
+		_, err = e.store.FailDatasetExport(ctx, db.FailDatasetExportParams{
+			ID:           export.ID,
+			ErrorMessage: sql.NullString{String: err.Error(), Valid: true},
+		})
+		if err != nil {
+			log.WithError(err).Error("Failed to mark dataset export failed")
+		}
+		return true
+	}
+
+	log.Info("Dataset export completed")
+	return true
+}
+
+// ArchiveKey is where the archive of an export is stored
+func ArchiveKey(exportID uuid.UUID, format string) string {
+	return fmt.Sprintf("exports/%s/dataset-%s.zip", exportID, format)
+}
+
+// Export writes the archive of an export, uploads it and marks the export completed
+func (e *Exporter) Export(ctx context.Context, export db.DatasetExport) error {
+	writer, err := NewWriter(export.Format)
+	if err != nil {
+		return err
+	}
+
+	var filters Filters
+	if err := json.Unmarshal(export.Filters, &filters); err != nil {
+		return fmt.Errorf("invalid export filters: %w", err)
+	}
+
+	rows, err := e.store.ListLogosForExport(ctx, filters.QueryParams())
+	if err != nil {
+		return fmt.Errorf("failed to list logos: %w", err)
+	}
+	if len(rows) == 0 {
+		return ErrNoLogos
+	}
+
+	file, err := os.CreateTemp("", "dataset-export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	ds, err := e.writeImages(ctx, archive, writer, rows, export.ValFraction)
+	if err != nil {
+		return err
+	}
+	if len(ds.Images) == 0 {
+		return ErrNoLogos
+	}
+	if err := writer.WriteAnnotations(archive, ds); err != nil {
+		return fmt.Errorf("failed to write annotations: %w", err)
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := ArchiveKey(export.ID, export.Format)
+	if _, err := e.storageClient.UploadFile(ctx, key, file, size); err != nil {
+		return err
+	}
+
+	annotations := 0
+	for _, img := range ds.Images {
+		annotations += len(img.Annotations)
+	}
+	_, err = e.store.CompleteDatasetExport(ctx, db.CompleteDatasetExportParams{
+		ID:               export.ID,
+		S3Key:            sql.NullString{String: key, Valid: true},
+		ImagesCount:      int32(len(ds.Images)),
+		AnnotationsCount: int32(annotations),
+	})
+	return err
+}
+
+// writeImages adds the source image of every job to the archive and collects
+// its annotations. Rows must be grouped by job.
+func (e *Exporter) writeImages(ctx context.Context, archive *zip.Writer, writer Writer, rows []db.ListLogosForExportRow, valFraction float64) (Dataset, error) {
+	var ds Dataset
+
+	for start := 0; start < len(rows); {
+		end := start + 1
+		for end < len(rows) && rows[end].JobID == rows[start].JobID {
+			end++
+		}
+		job := rows[start:end]
+		start = end
+
+		img, data, err := e.loadImage(ctx, job[0])
+		if err != nil {
+			// A missing original should not fail the whole export
+			logrus.WithError(err).WithField("job_id", job[0].JobID).Warn("Skipping job in dataset export")
+			continue
+		}
+		img.Split = SplitFor(job[0].JobID, valFraction)
+
+		for _, row := range job {
+			box, ok := ClampBox(models.BBox(row.BoundingBox), img.Width, img.Height)
+			if !ok {
+				continue
+			}
+			img.Annotations = append(img.Annotations, Annotation{Category: row.LogoType, Box: box})
+			if !slices.Contains(ds.Categories, row.LogoType) {
+				ds.Categories = append(ds.Categories, row.LogoType)
+			}
+		}
+		if len(img.Annotations) == 0 {
+			continue
+		}
+
+		// Images are already compressed, so they are stored as is
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: writer.ImagePath(img), Method: zip.Store})
+		if err != nil {
+			return ds, err
+		}
+		if _, err := entry.Write(data); err != nil {
+			return ds, err
+		}
+		ds.Images = append(ds.Images, img)
+	}
+
+	slices.Sort(ds.Categories)
+	return ds, nil
+}
+
+func (e *Exporter) loadImage(ctx context.Context, row db.ListLogosForExportRow) (Image, []byte, error) {
+	body, err := e.storageClient.DownloadFile(ctx, row.ImageKey)
+	if err != nil {
+		return Image{}, nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return Image{}, nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	return Image{
+		Name:   row.JobID.String() + path.Ext(row.ImageKey),
+		Width:  config.Width,
+		Height: config.Height,
+	}, data, nil
+}
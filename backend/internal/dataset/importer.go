@@ -0,0 +1,355 @@
+package dataset
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/tiling"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ImportActor is recorded as the actor of jobs created by an import
+const ImportActor = "import"
+
+var errImageTooLarge = errors.New("image is too large")
+
+// ImportResult counts what an import did
+type ImportResult struct {
+	Images      int
+	Annotations int
+	// Skipped are images that an earlier import already created a job for
+	Skipped int
+	// Requeued are images whose earlier job failed, queued for detection again
+	Requeued int
+	// InvalidAnnotations are labels left out because their box is outside the image
+	InvalidAnnotations int
+}
+
+// Importer turns labeled datasets into jobs with ground-truth annotations.
+// Every image is queued for detection so its results can be compared with
+// the labels. Archives uploaded through the API are claimed with SKIP LOCKED,
+// so every replica can run one.
+type Importer struct {
+	config          utils.ImportConfig
+	detectionParams models.DetectionParams
+	store           db.Store
+	storageClient   storage.Client
+	queueClient     queue.Client
+	// tiler is nil when tiled detection is disabled
+	tiler *tiling.Tiler
+}
+
+func NewImporter(config utils.ImportConfig, detectionParams models.DetectionParams, store db.Store, storageClient storage.Client, queueClient queue.Client, tiler *tiling.Tiler) *Importer {
+	return &Importer{
+		config:          config,
+		detectionParams: detectionParams,
+		store:           store,
+		storageClient:   storageClient,
+		queueClient:     queueClient,
+		tiler:           tiler,
+	}
+}
+
+// Run processes uploaded archives on every interval until ctx is cancelled
+func (i *Importer) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for ctx.Err() == nil && i.ImportNext(ctx) {
+			}
+		}
+	}
+}
+
+// ImportNext claims and imports one uploaded archive. It reports whether an import was claimed.
+func (i *Importer) ImportNext(ctx context.Context) bool {
+	datasetImport, err := i.store.ClaimDatasetImport(ctx, time.Now().Add(-i.config.Timeout))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logrus.WithError(err).Error("Failed to claim dataset import")
+		}
+		return false
+	}
+
+	result, err := i.importArchive(ctx, datasetImport)
+	i.Finish(ctx, datasetImport.ID, result, err)
+	return true
+}
+
+// Finish records the outcome of an import
+func (i *Importer) Finish(ctx context.Context, importID uuid.UUID, result ImportResult, importErr error) {
+	log := logrus.WithField("import_id", importID)
+
+	if importErr != nil {
+		log.WithError(importErr).Error("Dataset import failed")
+		_, err := i.store.FailDatasetImport(ctx, db.FailDatasetImportParams{
+			ID:           importID,
+			ErrorMessage: sql.NullString{String: importErr.Error(), Valid: true},
+		})
+		if err != nil {
+			log.WithError(err).Error("Failed to mark dataset import failed")
+		}
+		return
+	}
+
+	_, err := i.store.CompleteDatasetImport(ctx, db.CompleteDatasetImportParams{
+		ID:                      importID,
+		ImagesCount:             int32(result.Images),
+		AnnotationsCount:        int32(result.Annotations),
+		SkippedCount:            int32(result.Skipped),
+		RequeuedCount:           int32(result.Requeued),
+		InvalidAnnotationsCount: int32(result.InvalidAnnotations),
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to mark dataset import completed")
+		return
+	}
+	log.WithFields(logrus.Fields{
+		"images":              result.Images,
+		"annotations":         result.Annotations,
+		"skipped":             result.Skipped,
+		"requeued":            result.Requeued,
+		"invalid_annotations": result.InvalidAnnotations,
+	}).Info("Dataset import completed")
+}
+
+func (i *Importer) importArchive(ctx context.Context, datasetImport db.DatasetImport) (ImportResult, error) {
+	body, err := i.storageClient.DownloadFile(ctx, datasetImport.S3Key.String)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer body.Close()
+
+	file, err := os.CreateTemp("", "dataset-import-*.zip")
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	size, err := io.Copy(file, body)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to download archive: %w", err)
+	}
+
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	return i.Import(ctx, datasetImport.ID, archive, datasetImport.Format)
+}
+
+// Import creates a job with ground-truth annotations for every image of the
+// dataset in fsys. Images an earlier import already created a job for are
+// skipped, and queued again if that job failed, so a failed import can be
+// run again. Labels outside their image are left out and counted.
+func (i *Importer) Import(ctx context.Context, importID uuid.UUID, fsys fs.FS, format string) (ImportResult, error) {
+	var result ImportResult
+
+	images, err := Read(fsys, format)
+	if err != nil {
+		return result, err
+	}
+
+	for _, img := range images {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if err := i.importImage(ctx, importID, fsys, img, &result); err != nil {
+			return result, fmt.Errorf("failed to import %s: %w", img.Name, err)
+		}
+	}
+
+	return result, nil
+}
+
+// importImage creates the job of one image, or requeues the failed job an
+// earlier import created for it, and adds what it did to result. Images are
+// held to the same limits as uploads, and tiled the same way.
+func (i *Importer) importImage(ctx context.Context, importID uuid.UUID, fsys fs.FS, img Image, result *ImportResult) error {
+	data, err := readImage(fsys, img.Name, i.config.MaxImageSize)
+	if err != nil {
+		return err
+	}
+	imageConfig, err := imaging.CheckPixels(bytes.NewReader(data), i.config.MaxImagePixels)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	contentSha256 := sql.NullString{String: hex.EncodeToString(sum[:]), Valid: true}
+	existing, err := i.store.GetImportedJob(ctx, contentSha256)
+	if err == nil {
+		if existing.Status != models.JobStatusFailed {
+			result.Skipped++
+			return nil
+		}
+		if err := i.requeue(ctx, existing); err != nil {
+			return err
+		}
+		result.Requeued++
+		return nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	annotations := make([]db.CreateGroundTruthAnnotationParams, 0, len(img.Annotations))
+	for _, annotation := range img.Annotations {
+		box, ok := ClampBox(annotation.Box, img.Width, img.Height)
+		if !ok {
+			logrus.WithFields(logrus.Fields{
+				"image":     img.Name,
+				"logo_type": annotation.Category,
+				"box":       annotation.Box,
+			}).Warn("Skipping label outside its image")
+			result.InvalidAnnotations++
+			continue
+		}
+		boundingBox, err := json.Marshal(box)
+		if err != nil {
+			return err
+		}
+		annotations = append(annotations, db.CreateGroundTruthAnnotationParams{
+			LogoType:    annotation.Category,
+			BoundingBox: string(boundingBox),
+		})
+	}
+
+	detectionParams := i.detectionParams
+	tiled := i.tiler != nil && i.tiler.NeedsTiling(imageConfig.Width, imageConfig.Height, nil)
+	if tiled {
+		detectionParams.Tiling = i.tiler.Params()
+	}
+
+	jobID := uuid.New()
+	s3Key := fmt.Sprintf("original/%s/%s", jobID, path.Base(img.Name))
+	if _, err := i.storageClient.UploadFile(ctx, s3Key, bytes.NewReader(data), int64(len(data))); err != nil {
+		return err
+	}
+	uploadURL, err := i.storageClient.GetPresignedGetURL(ctx, s3Key, 24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	created, err := i.store.ImportJobTx(ctx, db.ImportJobTxParams{
+		CreateJobParams: db.CreateJobParams{
+			ID:                jobID,
+			Status:            models.JobStatusPending,
+			S3Key:             s3Key,
+			UploadUrl:         uploadURL,
+			ContentSha256:     contentSha256,
+			ParamsFingerprint: sql.NullString{String: detectionParams.Fingerprint(), Valid: true},
+			ImportID:          uuid.NullUUID{UUID: importID, Valid: true},
+			ModelVersion:      sql.NullString{String: detectionParams.ModelVersion, Valid: true},
+			Tiled:             tiled,
+		},
+		Actor:       ImportActor,
+		Annotations: annotations,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := i.publish(ctx, created.Job); err != nil {
+		return err
+	}
+	result.Images++
+	result.Annotations += len(annotations)
+	return nil
+}
+
+// requeue moves a failed job back to pending and queues it again, its
+// annotations were stored when it was created
+func (i *Importer) requeue(ctx context.Context, job db.Job) error {
+	job, err := i.store.TransitionJobTx(ctx, db.TransitionJobTxParams{
+		JobID:    job.ID,
+		ToStatus: models.JobStatusPending,
+		Actor:    ImportActor,
+		Reason:   "Queued again by a new import run",
+	})
+	if err != nil {
+		return err
+	}
+	if job.Tiled {
+		// Its tiles, if any, are queued again like the reaper does
+		if err := tiling.Requeue(ctx, i.store, i.queueClient, job); err != nil {
+			return i.failPublish(ctx, job, err)
+		}
+		return nil
+	}
+	return i.publish(ctx, job)
+}
+
+// publish queues a job for detection, or its split when it is tiled,
+// marking it failed when that fails so the next run queues it again
+func (i *Importer) publish(ctx context.Context, job db.Job) error {
+	var err error
+	if job.Tiled {
+		err = i.queueClient.PublishSplit(&models.SplitMessage{JobID: job.ID})
+	} else {
+		err = i.queueClient.PublishJob(queue.NewJobMessage(job))
+	}
+	if err != nil {
+		return i.failPublish(ctx, job, err)
+	}
+	return nil
+}
+
+// failPublish marks a job that could not be queued failed
+func (i *Importer) failPublish(ctx context.Context, job db.Job, err error) error {
+	_, updateErr := i.store.TransitionJobTx(ctx, db.TransitionJobTxParams{
+		JobID:        job.ID,
+		ToStatus:     models.JobStatusFailed,
+		Actor:        ImportActor,
+		Reason:       "Failed to queue job for processing",
+		ErrorMessage: sql.NullString{String: "Failed to queue job for processing", Valid: true},
+	})
+	if updateErr != nil {
+		logrus.WithError(updateErr).WithField("job_id", job.ID).Error("Failed to update job status after queue publish failure")
+	}
+	return fmt.Errorf("failed to queue job: %w", err)
+}
+
+// readImage reads an image of the dataset, refusing entries that unpack to
+// more than maxSize bytes whatever their archive header claims
+func readImage(fsys fs.FS, name string, maxSize int64) ([]byte, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: more than %d bytes", errImageTooLarge, maxSize)
+	}
+	return data, nil
+}
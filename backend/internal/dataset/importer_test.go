@@ -0,0 +1,195 @@
+package dataset
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/tiling"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// importStore keeps imported jobs by content hash
+type importStore struct {
+	db.Store
+	jobs        map[string]db.Job
+	annotations int
+}
+
+func (s *importStore) GetImportedJob(ctx context.Context, contentSha256 sql.NullString) (db.Job, error) {
+	job, ok := s.jobs[contentSha256.String]
+	if !ok {
+		return db.Job{}, sql.ErrNoRows
+	}
+	return job, nil
+}
+
+func (s *importStore) ImportJobTx(ctx context.Context, arg db.ImportJobTxParams) (db.ImportJobTxResult, error) {
+	job := db.Job{ID: arg.ID, Status: arg.Status, S3Key: arg.S3Key, ContentSha256: arg.ContentSha256, ImportID: arg.ImportID, Tiled: arg.Tiled}
+	s.jobs[arg.ContentSha256.String] = job
+	s.annotations += len(arg.Annotations)
+	return db.ImportJobTxResult{Job: job}, nil
+}
+
+func (s *importStore) TransitionJobTx(ctx context.Context, arg db.TransitionJobTxParams) (db.Job, error) {
+	for sum, job := range s.jobs {
+		if job.ID == arg.JobID {
+			job.Status = arg.ToStatus
+			s.jobs[sum] = job
+			return job, nil
+		}
+	}
+	return db.Job{}, sql.ErrNoRows
+}
+
+type discardStorage struct {
+	storage.Client
+}
+
+func (discardStorage) UploadFile(ctx context.Context, key string, file io.Reader, size int64) (*storage.UploadOutput, error) {
+	return &storage.UploadOutput{}, nil
+}
+
+func (discardStorage) GetPresignedGetURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "https://storage/" + key, nil
+}
+
+type flakyQueue struct {
+	queue.Client
+	down      bool
+	published []uuid.UUID
+	splits    []uuid.UUID
+}
+
+func (q *flakyQueue) PublishSplit(message *models.SplitMessage) error {
+	if q.down {
+		return errors.New("connection refused")
+	}
+	q.splits = append(q.splits, message.JobID)
+	return nil
+}
+
+func (q *flakyQueue) PublishJob(job *models.Job) error {
+	if q.down {
+		return errors.New("connection refused")
+	}
+	q.published = append(q.published, job.ID)
+	return nil
+}
+
+func TestImportRunsAgain(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.png": testPNG(t, 200, 100),
+		"b.png": testPNG(t, 100, 100),
+		"instances.json": {Data: []byte(`{
+			"images": [{"id": 1, "file_name": "a.png"}, {"id": 2, "file_name": "b.png"}],
+			"categories": [{"id": 1, "name": "wordmark"}],
+			"annotations": [
+				{"image_id": 1, "category_id": 1, "bbox": [10, 10, 50, 30]},
+				{"image_id": 2, "category_id": 1, "bbox": [10, 10, 20, 20]},
+				{"image_id": 2, "category_id": 1, "bbox": [300, 300, 20, 20]}
+			]
+		}`)},
+	}
+	store := &importStore{jobs: map[string]db.Job{}}
+	queueClient := &flakyQueue{down: true}
+	importer := NewImporter(utils.ImportConfig{MaxImageSize: 1 << 20, MaxImagePixels: 1 << 20}, models.DetectionParams{}, store, discardStorage{}, queueClient, nil)
+
+	// The queue is down, the first job is created but fails
+	_, err := importer.Import(context.Background(), uuid.New(), fsys, FormatCOCO)
+	require.ErrorContains(t, err, "failed to queue job")
+	require.Len(t, store.jobs, 1)
+	for _, job := range store.jobs {
+		require.Equal(t, models.JobStatusFailed, job.Status)
+	}
+
+	// The failed job is queued again, the label outside b.png is left out
+	queueClient.down = false
+	result, err := importer.Import(context.Background(), uuid.New(), fsys, FormatCOCO)
+	require.NoError(t, err)
+	require.Equal(t, ImportResult{Images: 1, Annotations: 1, Requeued: 1, InvalidAnnotations: 1}, result)
+	require.Len(t, queueClient.published, 2)
+	require.Equal(t, 2, store.annotations)
+	for _, job := range store.jobs {
+		require.Equal(t, models.JobStatusPending, job.Status)
+	}
+
+	// Jobs that did not fail are left alone
+	result, err = importer.Import(context.Background(), uuid.New(), fsys, FormatCOCO)
+	require.NoError(t, err)
+	require.Equal(t, ImportResult{Skipped: 2}, result)
+	require.Len(t, queueClient.published, 2)
+}
+
+func TestImportLimits(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.png": testPNG(t, 300, 100),
+		"instances.json": {Data: []byte(`{
+			"images": [{"id": 1, "file_name": "a.png"}],
+			"categories": [{"id": 1, "name": "wordmark"}],
+			"annotations": [{"image_id": 1, "category_id": 1, "bbox": [10, 10, 50, 30]}]
+		}`)},
+	}
+	config := utils.ImportConfig{MaxImageSize: int64(len(fsys["a.png"].Data)), MaxImagePixels: 30000}
+
+	tests := []struct {
+		name   string
+		config utils.ImportConfig
+		err    error
+	}{
+		{name: "within limits", config: config},
+		{name: "too many pixels", config: utils.ImportConfig{MaxImageSize: config.MaxImageSize, MaxImagePixels: 29999}, err: imaging.ErrTooManyPixels},
+		{name: "too large", config: utils.ImportConfig{MaxImageSize: config.MaxImageSize - 1, MaxImagePixels: 30000}, err: errImageTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &importStore{jobs: map[string]db.Job{}}
+			importer := NewImporter(test.config, models.DetectionParams{}, store, discardStorage{}, &flakyQueue{}, nil)
+
+			_, err := importer.Import(context.Background(), uuid.New(), fsys, FormatCOCO)
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+				require.Empty(t, store.jobs)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, store.jobs, 1)
+		})
+	}
+}
+
+func TestImportTilesLargeImages(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.png": testPNG(t, 300, 100),
+		"b.png": testPNG(t, 100, 100),
+		"instances.json": {Data: []byte(`{
+			"images": [{"id": 1, "file_name": "a.png"}, {"id": 2, "file_name": "b.png"}],
+			"categories": [{"id": 1, "name": "wordmark"}],
+			"annotations": []
+		}`)},
+	}
+	store := &importStore{jobs: map[string]db.Job{}}
+	queueClient := &flakyQueue{}
+	tiler := tiling.NewTiler(utils.TilingConfig{MaxDimension: 200, TileSize: 100}, store, discardStorage{}, queueClient)
+	config := utils.ImportConfig{MaxImageSize: 1 << 20, MaxImagePixels: 1 << 20}
+	importer := NewImporter(config, models.DetectionParams{}, store, discardStorage{}, queueClient, tiler)
+
+	_, err := importer.Import(context.Background(), uuid.New(), fsys, FormatCOCO)
+	require.NoError(t, err)
+	require.Len(t, queueClient.splits, 1)
+	require.Len(t, queueClient.published, 1)
+	for _, job := range store.jobs {
+		require.Equal(t, job.ID == queueClient.splits[0], job.Tiled)
+	}
+}
@@ -0,0 +1,75 @@
+package dataset
+
+import (
+	"fmt"
+	"image"
+	"io/fs"
+	"math"
+	"path"
+	"strings"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+)
+
+// Read loads the images and labels of a dataset in the given format. The
+// returned images are named by their path in fsys.
+func Read(fsys fs.FS, format string) ([]Image, error) {
+	switch format {
+	case FormatCOCO:
+		return readCOCO(fsys)
+	case FormatYOLO:
+		return readYOLO(fsys)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+func isImageFile(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+	return false
+}
+
+// walkFiles lists the regular files in fsys, skipping hidden files and macOS archive metadata
+func walkFiles(fsys fs.FS) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		base := entry.Name()
+		if name != "." && (strings.HasPrefix(base, ".") || base == "__MACOSX") {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			files = append(files, name)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// imageSize reads only the header of an image, which is all a label needs
+func imageSize(fsys fs.FS, name string) (int, int, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return config.Width, config.Height, nil
+}
+
+// pixelBox rounds a box given by its corners to whole pixels
+func pixelBox(x0, y0, x1, y1 float64) models.BBox {
+	x, y := int(math.Round(x0)), int(math.Round(y0))
+	return models.BBox{X: x, Y: y, Width: int(math.Round(x1)) - x, Height: int(math.Round(y1)) - y}
+}
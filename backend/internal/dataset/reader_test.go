@@ -0,0 +1,100 @@
+package dataset
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func testPNG(t *testing.T, width, height int) *fstest.MapFile {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return &fstest.MapFile{Data: buf.Bytes()}
+}
+
+func TestReadCOCO(t *testing.T) {
+	fsys := fstest.MapFS{
+		"images/train/a.png": testPNG(t, 200, 100),
+		"annotations/instances_train.json": {Data: []byte(`{
+			"images": [{"id": 7, "file_name": "a.png"}],
+			"categories": [{"id": 3, "name": "wordmark"}],
+			"annotations": [
+				{"image_id": 7, "category_id": 3, "bbox": [10.4, 20, 50.2, 30.6], "iscrowd": 0},
+				{"image_id": 7, "category_id": 3, "bbox": [0, 0, 200, 100], "iscrowd": 1}
+			]
+		}`)},
+		"annotations/readme.json": {Data: []byte(`{"description": "not an annotation file"}`)},
+	}
+
+	images, err := Read(fsys, FormatCOCO)
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	require.Equal(t, "images/train/a.png", images[0].Name)
+	require.Equal(t, 200, images[0].Width)
+	require.Equal(t, 100, images[0].Height)
+	require.Equal(t, []Annotation{{Category: "wordmark", Box: models.BBox{X: 10, Y: 20, Width: 51, Height: 31}}}, images[0].Annotations)
+}
+
+func TestReadCOCOMissingImage(t *testing.T) {
+	fsys := fstest.MapFS{
+		"instances.json": {Data: []byte(`{"images": [{"id": 1, "file_name": "missing.png"}]}`)},
+	}
+
+	_, err := Read(fsys, FormatCOCO)
+	require.ErrorContains(t, err, "missing.png")
+}
+
+func TestReadYOLO(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data.yaml":              {Data: []byte("train: images/train\nnames:\n  0: icon\n  1: wordmark\n")},
+		"images/train/a.png":     testPNG(t, 200, 100),
+		"images/train/empty.png": testPNG(t, 10, 10),
+		"labels/train/a.txt": {Data: []byte(
+			"1 0.5 0.5 0.5 0.5\n" +
+				"0 0.1 0.2 0.3 0.2 0.2 0.6\n"),
+		},
+	}
+
+	images, err := Read(fsys, FormatYOLO)
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+
+	require.Equal(t, "images/train/a.png", images[0].Name)
+	require.Equal(t, []Annotation{
+		{Category: "wordmark", Box: models.BBox{X: 50, Y: 25, Width: 100, Height: 50}},
+		{Category: "icon", Box: models.BBox{X: 20, Y: 20, Width: 40, Height: 40}},
+	}, images[0].Annotations)
+	require.Empty(t, images[1].Annotations)
+}
+
+func TestReadYOLONamesList(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data.yaml": {Data: []byte("names: [icon, wordmark]\n")},
+	}
+
+	names, err := readYOLONames(fsys)
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{0: "icon", 1: "wordmark"}, names)
+}
+
+func TestReadYOLOUnknownClass(t *testing.T) {
+	fsys := fstest.MapFS{
+		"classes.txt":  {Data: []byte("icon\n")},
+		"images/a.png": testPNG(t, 10, 10),
+		"labels/a.txt": {Data: []byte("4 0.5 0.5 0.5 0.5\n")},
+	}
+
+	_, err := Read(fsys, FormatYOLO)
+	require.ErrorContains(t, err, "labels/a.txt:1: unknown class 4")
+}
+
+func TestYOLOLabelPath(t *testing.T) {
+	require.Equal(t, "labels/train/a.txt", yoloLabelPath("images/train/a.jpg"))
+	require.Equal(t, "set/labels/val/b.txt", yoloLabelPath("set/images/val/b.png"))
+	require.Equal(t, "photos/c.txt", yoloLabelPath("photos/c.jpeg"))
+}
@@ -0,0 +1,87 @@
+package dataset
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+type vocAnnotation struct {
+	XMLName   xml.Name    `xml:"annotation"`
+	Folder    string      `xml:"folder"`
+	Filename  string      `xml:"filename"`
+	Size      vocSize     `xml:"size"`
+	Segmented int         `xml:"segmented"`
+	Objects   []vocObject `xml:"object"`
+}
+
+type vocSize struct {
+	Width  int `xml:"width"`
+	Height int `xml:"height"`
+	Depth  int `xml:"depth"`
+}
+
+type vocObject struct {
+	Name      string    `xml:"name"`
+	Pose      string    `xml:"pose"`
+	Truncated int       `xml:"truncated"`
+	Difficult int       `xml:"difficult"`
+	BndBox    vocBndBox `xml:"bndbox"`
+}
+
+type vocBndBox struct {
+	XMin int `xml:"xmin"`
+	YMin int `xml:"ymin"`
+	XMax int `xml:"xmax"`
+	YMax int `xml:"ymax"`
+}
+
+// vocWriter writes JPEGImages/, one Annotations/<image>.xml per image and the
+// ImageSets/Main/<split>.txt image lists
+type vocWriter struct{}
+
+func (vocWriter) ImagePath(img Image) string {
+	return "JPEGImages/" + img.Name
+}
+
+func (vocWriter) WriteAnnotations(archive *zip.Writer, ds Dataset) error {
+	splits := map[string]*bytes.Buffer{SplitTrain: {}, SplitVal: {}}
+
+	for _, img := range ds.Images {
+		annotation := vocAnnotation{
+			Folder:   "JPEGImages",
+			Filename: img.Name,
+			Size:     vocSize{Width: img.Width, Height: img.Height, Depth: 3},
+		}
+		for _, a := range img.Annotations {
+			// VOC pixel coordinates are 1-based and inclusive
+			annotation.Objects = append(annotation.Objects, vocObject{
+				Name: a.Category,
+				Pose: "Unspecified",
+				BndBox: vocBndBox{
+					XMin: a.Box.X + 1,
+					YMin: a.Box.Y + 1,
+					XMax: a.Box.X + a.Box.Width,
+					YMax: a.Box.Y + a.Box.Height,
+				},
+			})
+		}
+
+		data, err := xml.MarshalIndent(annotation, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeFile(archive, fmt.Sprintf("Annotations/%s.xml", img.Stem()), append([]byte(xml.Header), data...)); err != nil {
+			return err
+		}
+		fmt.Fprintln(splits[img.Split], img.Stem())
+	}
+
+	for _, split := range []string{SplitTrain, SplitVal} {
+		if err := writeFile(archive, fmt.Sprintf("ImageSets/Main/%s.txt", split), splits[split].Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package dataset
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yoloWriter writes images/<split>/, one labels/<split>/<image>.txt per image
+// and a data.yaml listing the classes
+type yoloWriter struct{}
+
+func (yoloWriter) ImagePath(img Image) string {
+	return fmt.Sprintf("images/%s/%s", img.Split, img.Name)
+}
+
+func (yoloWriter) WriteAnnotations(archive *zip.Writer, ds Dataset) error {
+	for _, img := range ds.Images {
+		var labels bytes.Buffer
+		for _, annotation := range img.Annotations {
+			// Class index followed by the box center and size relative to the image
+			box := annotation.Box
+			width, height := float64(img.Width), float64(img.Height)
+			fmt.Fprintf(&labels, "%d %.6f %.6f %.6f %.6f\n",
+				ds.CategoryIndex(annotation.Category),
+				(float64(box.X)+float64(box.Width)/2)/width,
+				(float64(box.Y)+float64(box.Height)/2)/height,
+				float64(box.Width)/width,
+				float64(box.Height)/height,
+			)
+		}
+		if err := writeFile(archive, fmt.Sprintf("labels/%s/%s.txt", img.Split, img.Stem()), labels.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	var config bytes.Buffer
+	fmt.Fprintf(&config, "path: .\ntrain: images/%s\nval: images/%s\nnc: %d\nnames:\n", SplitTrain, SplitVal, len(ds.Categories))
+	for i, name := range ds.Categories {
+		fmt.Fprintf(&config, "  %d: %q\n", i, name)
+	}
+	return writeFile(archive, "data.yaml", config.Bytes())
+}
+
+// readYOLO reads images with their labels/<...>/<image>.txt files. Class
+// names come from data.yaml, or classes.txt when there is no data.yaml.
+func readYOLO(fsys fs.FS) ([]Image, error) {
+	names, err := readYOLONames(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := walkFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []Image
+	for _, name := range files {
+		if !isImageFile(name) {
+			continue
+		}
+		width, height, err := imageSize(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		img := Image{Name: name, Width: width, Height: height}
+
+		labelPath := yoloLabelPath(name)
+		labels, err := fs.ReadFile(fsys, labelPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		// An image without a label file has no logos
+		for i, line := range strings.Split(string(labels), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			annotation, err := parseYOLOLabel(fields, names, width, height)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", labelPath, i+1, err)
+			}
+			img.Annotations = append(img.Annotations, annotation)
+		}
+
+		images = append(images, img)
+	}
+
+	if len(images) == 0 {
+		return nil, errors.New("no images found")
+	}
+	return images, nil
+}
+
+// yoloLabelPath maps images/<split>/<image>.jpg to labels/<split>/<image>.txt.
+// Images outside an images directory have their labels next to them.
+func yoloLabelPath(imagePath string) string {
+	labelPath := strings.TrimSuffix(imagePath, path.Ext(imagePath)) + ".txt"
+	if strings.HasPrefix(labelPath, "images/") {
+		return "labels/" + strings.TrimPrefix(labelPath, "images/")
+	}
+	if i := strings.LastIndex(labelPath, "/images/"); i >= 0 {
+		return labelPath[:i] + "/labels/" + labelPath[i+len("/images/"):]
+	}
+	return labelPath
+}
+
+// parseYOLOLabel parses a box line "class cx cy w h" or a segment line
+// "class x1 y1 x2 y2 ...", whose box is the bounds of the polygon
+func parseYOLOLabel(fields []string, names map[int]string, width, height int) (Annotation, error) {
+	if len(fields) < 5 || (len(fields) > 5 && len(fields)%2 == 0) {
+		return Annotation{}, fmt.Errorf("expected a box or polygon, got %d values", len(fields))
+	}
+
+	class, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Annotation{}, fmt.Errorf("invalid class %q", fields[0])
+	}
+	category, ok := names[class]
+	if !ok {
+		if len(names) > 0 {
+			return Annotation{}, fmt.Errorf("unknown class %d", class)
+		}
+		category = strconv.Itoa(class)
+	}
+
+	values := make([]float64, len(fields)-1)
+	for i, field := range fields[1:] {
+		values[i], err = strconv.ParseFloat(field, 64)
+		if err != nil {
+			return Annotation{}, fmt.Errorf("invalid coordinate %q", field)
+		}
+	}
+
+	w, h := float64(width), float64(height)
+	if len(values) == 4 {
+		cx, cy, bw, bh := values[0], values[1], values[2], values[3]
+		return Annotation{
+			Category: category,
+			Box:      pixelBox((cx-bw/2)*w, (cy-bh/2)*h, (cx+bw/2)*w, (cy+bh/2)*h),
+		}, nil
+	}
+
+	x0, y0, x1, y1 := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for i := 0; i < len(values); i += 2 {
+		x0, x1 = min(x0, values[i]), max(x1, values[i])
+		y0, y1 = min(y0, values[i+1]), max(y1, values[i+1])
+	}
+	return Annotation{Category: category, Box: pixelBox(x0*w, y0*h, x1*w, y1*h)}, nil
+}
+
+// readYOLONames reads the class names by index. The names in data.yaml are
+// either a list or a map from index to name.
+func readYOLONames(fsys fs.FS) (map[int]string, error) {
+	names := map[int]string{}
+
+	data, err := fs.ReadFile(fsys, "data.yaml")
+	if err == nil {
+		var config struct {
+			Names yaml.Node `yaml:"names"`
+		}
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse data.yaml: %w", err)
+		}
+		switch config.Names.Kind {
+		case yaml.SequenceNode:
+			var list []string
+			if err := config.Names.Decode(&list); err != nil {
+				return nil, fmt.Errorf("invalid names in data.yaml: %w", err)
+			}
+			for i, name := range list {
+				names[i] = name
+			}
+		case yaml.MappingNode:
+			if err := config.Names.Decode(&names); err != nil {
+				return nil, fmt.Errorf("invalid names in data.yaml: %w", err)
+			}
+		}
+		return names, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	data, err = fs.ReadFile(fsys, "classes.txt")
+	if errors.Is(err, fs.ErrNotExist) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		names[i] = strings.TrimSpace(name)
+	}
+	return names, nil
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_jobs_status_updated_at;
+ALTER TABLE "jobs" ALTER COLUMN "updated_at" SET DEFAULT '0001-01-01 00:00:00Z';
+ALTER TABLE "jobs" DROP COLUMN IF EXISTS "requeue_count";
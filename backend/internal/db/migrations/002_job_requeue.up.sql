@@ -0,0 +1,7 @@
+ALTER TABLE "jobs" ADD COLUMN "requeue_count" int NOT NULL DEFAULT 0;
+
+-- Jobs never had updated_at set on insert; backfill it so stale-job detection works
+UPDATE "jobs" SET "updated_at" = "created_at" WHERE "updated_at" = '0001-01-01 00:00:00Z';
+ALTER TABLE "jobs" ALTER COLUMN "updated_at" SET DEFAULT now();
+
+CREATE INDEX idx_jobs_status_updated_at ON jobs(status, updated_at);
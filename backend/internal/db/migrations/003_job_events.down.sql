@@ -0,0 +1,3 @@
+-- NOT VALID leaves the crops stored since alone, they never matched jobs.s3_key
+ALTER TABLE "logos" ADD CONSTRAINT "logos_s3_key_fkey" FOREIGN KEY ("s3_key") REFERENCES "jobs" ("s3_key") NOT VALID;
+DROP TABLE IF EXISTS "job_events";
@@ -0,0 +1,16 @@
+CREATE TABLE "job_events" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "job_id" bigint NOT NULL,
+  "from_status" varchar,
+  "to_status" varchar NOT NULL,
+  "actor" varchar NOT NULL,
+  "reason" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE "job_events" ADD FOREIGN KEY ("job_id") REFERENCES "jobs" ("id") ON DELETE CASCADE;
+
+CREATE INDEX idx_job_events_job_id_created_at ON job_events(job_id, created_at);
+
+-- Extracted crops live under extracted/, so logos.s3_key can never match jobs.s3_key
+ALTER TABLE "logos" DROP CONSTRAINT IF EXISTS "logos_s3_key_fkey";
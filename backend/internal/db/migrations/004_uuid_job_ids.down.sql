@@ -0,0 +1,28 @@
+-- The original truncated IDs cannot be recovered; assign new sequential ones
+ALTER TABLE "jobs" ADD COLUMN "old_id" bigserial;
+
+ALTER TABLE "logos" ADD COLUMN "old_job_id" bigint;
+UPDATE "logos" SET "old_job_id" = "jobs"."old_id" FROM "jobs" WHERE "logos"."job_id" = "jobs"."id";
+
+ALTER TABLE "job_events" ADD COLUMN "old_job_id" bigint;
+UPDATE "job_events" SET "old_job_id" = "jobs"."old_id" FROM "jobs" WHERE "job_events"."job_id" = "jobs"."id";
+
+ALTER TABLE "logos" DROP CONSTRAINT IF EXISTS "logos_job_id_fkey";
+ALTER TABLE "job_events" DROP CONSTRAINT IF EXISTS "job_events_job_id_fkey";
+
+ALTER TABLE "jobs" ALTER COLUMN "id" DROP DEFAULT;
+ALTER TABLE "jobs" ALTER COLUMN "id" TYPE bigint USING "old_id";
+ALTER TABLE "jobs" DROP COLUMN "old_id";
+
+ALTER TABLE "logos" ALTER COLUMN "job_id" TYPE bigint USING "old_job_id";
+ALTER TABLE "logos" DROP COLUMN "old_job_id";
+
+ALTER TABLE "job_events" ALTER COLUMN "job_id" TYPE bigint USING "old_job_id";
+ALTER TABLE "job_events" DROP COLUMN "old_job_id";
+
+CREATE SEQUENCE IF NOT EXISTS "jobs_id_seq" OWNED BY "jobs"."id";
+SELECT setval('jobs_id_seq', COALESCE((SELECT MAX("id") FROM "jobs"), 0) + 1, false);
+ALTER TABLE "jobs" ALTER COLUMN "id" SET DEFAULT nextval('jobs_id_seq');
+
+ALTER TABLE "logos" ADD FOREIGN KEY ("job_id") REFERENCES "jobs" ("id");
+ALTER TABLE "job_events" ADD FOREIGN KEY ("job_id") REFERENCES "jobs" ("id") ON DELETE CASCADE;
@@ -0,0 +1,37 @@
+-- Job IDs used to be the low 32 bits of the upload UUID. The full UUID that was
+-- returned to the client is still part of the upload key (original/<uuid>/<file>),
+-- so recover it from there and only generate a new one when the key does not match.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+ALTER TABLE "jobs" ADD COLUMN "new_id" uuid;
+UPDATE "jobs" SET "new_id" = CASE
+  WHEN "s3_key" ~ '^original/[0-9a-fA-F-]{36}/' THEN substring("s3_key" from '^original/([0-9a-fA-F-]{36})/')::uuid
+  ELSE gen_random_uuid()
+END;
+
+ALTER TABLE "logos" ADD COLUMN "new_job_id" uuid;
+UPDATE "logos" SET "new_job_id" = "jobs"."new_id" FROM "jobs" WHERE "logos"."job_id" = "jobs"."id";
+
+ALTER TABLE "job_events" ADD COLUMN "new_job_id" uuid;
+UPDATE "job_events" SET "new_job_id" = "jobs"."new_id" FROM "jobs" WHERE "job_events"."job_id" = "jobs"."id";
+
+ALTER TABLE "logos" DROP CONSTRAINT IF EXISTS "logos_job_id_fkey";
+ALTER TABLE "job_events" DROP CONSTRAINT IF EXISTS "job_events_job_id_fkey";
+
+-- Convert the columns in place so they keep their position in the table
+ALTER TABLE "jobs" ALTER COLUMN "id" DROP DEFAULT;
+ALTER TABLE "jobs" ALTER COLUMN "id" TYPE uuid USING "new_id";
+ALTER TABLE "jobs" ALTER COLUMN "id" SET DEFAULT gen_random_uuid();
+ALTER TABLE "jobs" DROP COLUMN "new_id";
+DROP SEQUENCE IF EXISTS "jobs_id_seq";
+
+ALTER TABLE "logos" ALTER COLUMN "job_id" DROP DEFAULT;
+ALTER TABLE "logos" ALTER COLUMN "job_id" TYPE uuid USING "new_job_id";
+ALTER TABLE "logos" DROP COLUMN "new_job_id";
+DROP SEQUENCE IF EXISTS "logos_job_id_seq";
+
+ALTER TABLE "job_events" ALTER COLUMN "job_id" TYPE uuid USING "new_job_id";
+ALTER TABLE "job_events" DROP COLUMN "new_job_id";
+
+ALTER TABLE "logos" ADD FOREIGN KEY ("job_id") REFERENCES "jobs" ("id");
+ALTER TABLE "job_events" ADD FOREIGN KEY ("job_id") REFERENCES "jobs" ("id") ON DELETE CASCADE;
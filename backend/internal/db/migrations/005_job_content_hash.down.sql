@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_logos_s3_key;
+DROP INDEX IF EXISTS idx_jobs_content_sha256;
+
+ALTER TABLE "jobs" DROP COLUMN IF EXISTS "source_job_id";
+ALTER TABLE "jobs" DROP COLUMN IF EXISTS "params_fingerprint";
+ALTER TABLE "jobs" DROP COLUMN IF EXISTS "content_sha256";
@@ -0,0 +1,8 @@
+ALTER TABLE "jobs" ADD COLUMN "content_sha256" varchar(64);
+ALTER TABLE "jobs" ADD COLUMN "params_fingerprint" varchar(64);
+ALTER TABLE "jobs" ADD COLUMN "source_job_id" uuid;
+
+ALTER TABLE "jobs" ADD FOREIGN KEY ("source_job_id") REFERENCES "jobs" ("id") ON DELETE SET NULL;
+
+CREATE INDEX idx_jobs_content_sha256 ON jobs(content_sha256, params_fingerprint, status);
+CREATE INDEX idx_logos_s3_key ON logos(s3_key);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "logo_hashes";
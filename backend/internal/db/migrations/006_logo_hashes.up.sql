@@ -0,0 +1,8 @@
+CREATE TABLE "logo_hashes" (
+  "logo_id" bigint PRIMARY KEY NOT NULL,
+  "dhash" bigint NOT NULL,
+  "phash" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE "logo_hashes" ADD FOREIGN KEY ("logo_id") REFERENCES "logos" ("id") ON DELETE CASCADE;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_logos_brand_id;
+
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "brand_score";
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "brand_id";
+
+DROP TABLE IF EXISTS "brand_references";
+DROP TABLE IF EXISTS "brands";
@@ -0,0 +1,28 @@
+CREATE TABLE "brands" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "name" varchar UNIQUE NOT NULL,
+  "description" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE "brand_references" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "brand_id" bigint NOT NULL,
+  "s3_key" varchar UNIQUE NOT NULL,
+  "dhash" bigint NOT NULL,
+  "phash" bigint NOT NULL,
+  "color_histogram" float8[] NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE "brand_references" ADD FOREIGN KEY ("brand_id") REFERENCES "brands" ("id") ON DELETE CASCADE;
+
+CREATE INDEX idx_brand_references_brand_id ON brand_references(brand_id);
+
+ALTER TABLE "logos" ADD COLUMN "brand_id" bigint;
+ALTER TABLE "logos" ADD COLUMN "brand_score" float8;
+
+ALTER TABLE "logos" ADD FOREIGN KEY ("brand_id") REFERENCES "brands" ("id") ON DELETE SET NULL;
+
+CREATE INDEX idx_logos_brand_id ON logos(brand_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "logo_reviews";
+
+DROP INDEX IF EXISTS idx_logos_review_status_confidence;
+
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "review_status";
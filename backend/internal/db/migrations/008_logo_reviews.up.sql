@@ -0,0 +1,20 @@
+ALTER TABLE "logos" ADD COLUMN "review_status" varchar NOT NULL DEFAULT 'pending';
+
+CREATE INDEX idx_logos_review_status_confidence ON logos(review_status, confidence);
+
+CREATE TABLE "logo_reviews" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "logo_id" bigint NOT NULL,
+  "reviewer" varchar NOT NULL,
+  "decision" varchar NOT NULL,
+  "previous_logo_type" varchar NOT NULL,
+  "logo_type" varchar NOT NULL,
+  "previous_bounding_box" varchar NOT NULL,
+  "bounding_box" varchar NOT NULL,
+  "comment" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE "logo_reviews" ADD FOREIGN KEY ("logo_id") REFERENCES "logos" ("id") ON DELETE CASCADE;
+
+CREATE INDEX idx_logo_reviews_logo_id_created_at ON logo_reviews(logo_id, created_at);
@@ -0,0 +1,16 @@
+CREATE TABLE "dataset_exports" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "format" varchar NOT NULL,
+  "filters" jsonb NOT NULL DEFAULT '{}',
+  "val_fraction" float8 NOT NULL,
+  "s3_key" varchar,
+  "images_count" integer NOT NULL DEFAULT 0,
+  "annotations_count" integer NOT NULL DEFAULT 0,
+  "error_message" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "completed_at" timestamptz
+);
+
+CREATE INDEX idx_dataset_exports_status_created_at ON dataset_exports(status, created_at);
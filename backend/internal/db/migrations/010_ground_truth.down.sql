@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "ground_truth_annotations";
+
+DROP INDEX IF EXISTS idx_jobs_import_id;
+ALTER TABLE "jobs" DROP COLUMN IF EXISTS "import_id";
+
+DROP TABLE IF EXISTS "dataset_imports";
@@ -0,0 +1,34 @@
+CREATE TABLE "dataset_imports" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "status" varchar NOT NULL,
+  "format" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "s3_key" varchar,
+  "images_count" integer NOT NULL DEFAULT 0,
+  "annotations_count" integer NOT NULL DEFAULT 0,
+  "skipped_count" integer NOT NULL DEFAULT 0,
+  "error_message" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "completed_at" timestamptz
+);
+
+CREATE INDEX idx_dataset_imports_status_created_at ON dataset_imports(status, created_at);
+
+ALTER TABLE "jobs" ADD COLUMN "import_id" uuid;
+
+ALTER TABLE "jobs" ADD FOREIGN KEY ("import_id") REFERENCES "dataset_imports" ("id") ON DELETE SET NULL;
+
+CREATE INDEX idx_jobs_import_id ON jobs(import_id);
+
+CREATE TABLE "ground_truth_annotations" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "job_id" uuid NOT NULL,
+  "logo_type" varchar NOT NULL,
+  "bounding_box" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE "ground_truth_annotations" ADD FOREIGN KEY ("job_id") REFERENCES "jobs" ("id") ON DELETE CASCADE;
+
+CREATE INDEX idx_ground_truth_annotations_job_id ON ground_truth_annotations(job_id);
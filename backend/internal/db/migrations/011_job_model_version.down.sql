@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_jobs_model_version;
+
+ALTER TABLE "jobs" DROP COLUMN IF EXISTS "model_version";
@@ -0,0 +1,3 @@
+ALTER TABLE "jobs" ADD COLUMN "model_version" varchar;
+
+CREATE INDEX idx_jobs_model_version ON jobs(model_version);
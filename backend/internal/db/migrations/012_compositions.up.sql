@@ -0,0 +1,13 @@
+CREATE TABLE "compositions" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "target_key" varchar NOT NULL,
+  "placements" jsonb NOT NULL,
+  "s3_key" varchar,
+  "error_message" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "completed_at" timestamptz
+);
+
+CREATE INDEX idx_compositions_status_created_at ON compositions(status, created_at);
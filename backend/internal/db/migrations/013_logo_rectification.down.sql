@@ -0,0 +1,2 @@
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "rectified_s3_key";
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "corners";
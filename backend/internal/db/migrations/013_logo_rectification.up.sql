@@ -0,0 +1,2 @@
+ALTER TABLE "logos" ADD COLUMN "corners" varchar;
+ALTER TABLE "logos" ADD COLUMN "rectified_s3_key" varchar;
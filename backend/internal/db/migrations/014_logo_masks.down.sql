@@ -0,0 +1,3 @@
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "mask_source";
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "mask_quality";
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "masked_s3_key";
@@ -0,0 +1,3 @@
+ALTER TABLE "logos" ADD COLUMN "masked_s3_key" varchar;
+ALTER TABLE "logos" ADD COLUMN "mask_quality" float8;
+ALTER TABLE "logos" ADD COLUMN "mask_source" varchar;
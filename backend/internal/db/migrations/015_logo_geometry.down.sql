@@ -0,0 +1,2 @@
+ALTER TABLE "logos" DROP CONSTRAINT IF EXISTS "logos_geometry_object";
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "geometry";
@@ -0,0 +1,2 @@
+ALTER TABLE "logos" ADD COLUMN "geometry" jsonb NOT NULL DEFAULT '{}'::jsonb;
+ALTER TABLE "logos" ADD CONSTRAINT "logos_geometry_object" CHECK (jsonb_typeof("geometry") = 'object');
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_logos_box_area;
+
+ALTER TABLE "logo_reviews" ALTER COLUMN "bounding_box" TYPE varchar USING "bounding_box"::text;
+ALTER TABLE "logo_reviews" ALTER COLUMN "previous_bounding_box" TYPE varchar USING "previous_bounding_box"::text;
+ALTER TABLE "logos" ALTER COLUMN "bounding_box" TYPE varchar USING "bounding_box"::text;
+
+ALTER TABLE "logos" ALTER COLUMN "confidence" TYPE int8 USING "confidence"::int8;
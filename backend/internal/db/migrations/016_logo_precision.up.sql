@@ -0,0 +1,8 @@
+-- Confidence arrives as a float between 0 and 1 and was truncated by int8
+ALTER TABLE "logos" ALTER COLUMN "confidence" TYPE real USING "confidence"::real;
+
+ALTER TABLE "logos" ALTER COLUMN "bounding_box" TYPE jsonb USING "bounding_box"::jsonb;
+ALTER TABLE "logo_reviews" ALTER COLUMN "previous_bounding_box" TYPE jsonb USING "previous_bounding_box"::jsonb;
+ALTER TABLE "logo_reviews" ALTER COLUMN "bounding_box" TYPE jsonb USING "bounding_box"::jsonb;
+
+CREATE INDEX idx_logos_box_area ON logos ((("bounding_box"->>'width')::int8 * ("bounding_box"->>'height')::int8));
@@ -0,0 +1 @@
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "palette";
@@ -0,0 +1 @@
+ALTER TABLE "logos" ADD COLUMN "palette" jsonb;
@@ -0,0 +1 @@
+ALTER TABLE "logos" DROP COLUMN IF EXISTS "vector_s3_key";
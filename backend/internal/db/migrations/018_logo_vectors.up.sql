@@ -0,0 +1 @@
+ALTER TABLE "logos" ADD COLUMN "vector_s3_key" varchar;
@@ -0,0 +1,18 @@
+CREATE TABLE "job_tiles" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "job_id" uuid NOT NULL,
+  "tile_index" integer NOT NULL,
+  "x" integer NOT NULL,
+  "y" integer NOT NULL,
+  "width" integer NOT NULL,
+  "height" integer NOT NULL,
+  "s3_key" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "detections" jsonb NOT NULL DEFAULT '[]',
+  "model_version" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  UNIQUE ("job_id", "tile_index")
+);
+
+ALTER TABLE "job_tiles" ADD FOREIGN KEY ("job_id") REFERENCES "jobs" ("id") ON DELETE CASCADE;
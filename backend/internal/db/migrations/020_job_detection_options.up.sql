@@ -0,0 +1 @@
+ALTER TABLE "jobs" ADD COLUMN "detection_options" jsonb NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+ALTER TABLE "logo_hashes" DROP COLUMN IF EXISTS "phash_bands";
+ALTER TABLE "logo_hashes" DROP COLUMN IF EXISTS "dhash_bands";
@@ -0,0 +1,18 @@
+-- Each hash split into four 16-bit bands, offset by band so the keys of
+-- different bands never collide. Two hashes within distance d share a band
+-- within d/4 bits, which lets searches skip most rows through the index.
+ALTER TABLE "logo_hashes" ADD COLUMN "dhash_bands" int[] NOT NULL GENERATED ALWAYS AS (ARRAY[
+  ("dhash" & 65535)::int,
+  65536 + (("dhash" >> 16) & 65535)::int,
+  131072 + (("dhash" >> 32) & 65535)::int,
+  196608 + (("dhash" >> 48) & 65535)::int
+]) STORED;
+ALTER TABLE "logo_hashes" ADD COLUMN "phash_bands" int[] NOT NULL GENERATED ALWAYS AS (ARRAY[
+  ("phash" & 65535)::int,
+  65536 + (("phash" >> 16) & 65535)::int,
+  131072 + (("phash" >> 32) & 65535)::int,
+  196608 + (("phash" >> 48) & 65535)::int
+]) STORED;
+
+CREATE INDEX idx_logo_hashes_dhash_bands ON logo_hashes USING gin (dhash_bands);
+CREATE INDEX idx_logo_hashes_phash_bands ON logo_hashes USING gin (phash_bands);
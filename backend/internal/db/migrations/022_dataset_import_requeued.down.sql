@@ -0,0 +1,2 @@
+ALTER TABLE "dataset_imports" DROP COLUMN IF EXISTS "invalid_annotations_count";
+ALTER TABLE "dataset_imports" DROP COLUMN IF EXISTS "requeued_count";
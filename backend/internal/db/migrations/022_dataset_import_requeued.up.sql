@@ -0,0 +1,2 @@
+ALTER TABLE "dataset_imports" ADD COLUMN "requeued_count" int NOT NULL DEFAULT 0;
+ALTER TABLE "dataset_imports" ADD COLUMN "invalid_annotations_count" int NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE "jobs" ADD COLUMN "tiled" boolean NOT NULL DEFAULT false;
@@ -0,0 +1,51 @@
+-- name: CreateBrand :one
+INSERT INTO brands (
+    name,
+    description
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetBrand :one
+SELECT * FROM brands WHERE id = $1;
+
+-- name: ListBrands :many
+SELECT * FROM brands
+ORDER BY name;
+
+-- name: UpdateBrand :one
+UPDATE brands
+SET name = $2,
+    description = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteBrand :exec
+DELETE FROM brands WHERE id = $1;
+
+-- name: CreateBrandReference :one
+INSERT INTO brand_references (
+    brand_id,
+    s3_key,
+    dhash,
+    phash,
+    color_histogram
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetBrandReference :one
+SELECT * FROM brand_references WHERE id = $1;
+
+-- name: ListBrandReferences :many
+SELECT * FROM brand_references
+ORDER BY brand_id, id;
+
+-- name: ListBrandReferencesByBrand :many
+SELECT * FROM brand_references
+WHERE brand_id = $1
+ORDER BY id;
+
+-- name: DeleteBrandReference :exec
+DELETE FROM brand_references WHERE id = $1;
@@ -0,0 +1,42 @@
+-- name: CreateComposition :one
+INSERT INTO compositions (
+    id,
+    target_key,
+    placements
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetComposition :one
+SELECT * FROM compositions WHERE id = $1;
+
+-- name: ListCompositions :many
+SELECT * FROM compositions
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ClaimComposition :one
+-- A composition left processing by a crashed consumer is redelivered and claimed again
+UPDATE compositions
+SET status = 'processing',
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('pending', 'processing')
+RETURNING *;
+
+-- name: CompleteComposition :one
+UPDATE compositions
+SET status = 'completed',
+    s3_key = $2,
+    updated_at = NOW(),
+    completed_at = NOW()
+WHERE id = $1 AND status = 'processing'
+RETURNING *;
+
+-- name: FailComposition :one
+UPDATE compositions
+SET status = 'failed',
+    error_message = $2,
+    updated_at = NOW(),
+    completed_at = NOW()
+WHERE id = $1 AND status IN ('pending', 'processing')
+RETURNING *;
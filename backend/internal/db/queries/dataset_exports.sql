@@ -0,0 +1,63 @@
+-- name: CreateDatasetExport :one
+INSERT INTO dataset_exports (
+    id,
+    format,
+    filters,
+    val_fraction
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetDatasetExport :one
+SELECT * FROM dataset_exports WHERE id = $1;
+
+-- name: ListDatasetExports :many
+SELECT * FROM dataset_exports
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ClaimDatasetExport :one
+UPDATE dataset_exports
+SET status = 'processing',
+    updated_at = NOW()
+WHERE id = (
+    SELECT id FROM dataset_exports
+    WHERE status = 'pending' OR (status = 'processing' AND updated_at < $1)
+    ORDER BY created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteDatasetExport :one
+UPDATE dataset_exports
+SET status = 'completed',
+    s3_key = $2,
+    images_count = $3,
+    annotations_count = $4,
+    updated_at = NOW(),
+    completed_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: FailDatasetExport :one
+UPDATE dataset_exports
+SET status = 'failed',
+    error_message = $2,
+    updated_at = NOW(),
+    completed_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListLogosForExport :many
+SELECT logos.id, logos.job_id, logos.bounding_box, logos.logo_type, jobs.s3_key AS image_key
+FROM logos
+JOIN jobs ON jobs.id = logos.job_id
+WHERE jobs.status = 'completed'
+  AND jobs.source_job_id IS NULL
+  AND (cardinality(sqlc.arg(job_ids)::uuid[]) = 0 OR jobs.id = ANY(sqlc.arg(job_ids)::uuid[]))
+  AND (sqlc.narg(created_after)::timestamptz IS NULL OR jobs.created_at >= sqlc.narg(created_after))
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR jobs.created_at < sqlc.narg(created_before))
+  AND (sqlc.narg(min_confidence)::float8 IS NULL OR logos.confidence >= sqlc.narg(min_confidence))
+  AND logos.review_status = ANY(sqlc.arg(review_statuses)::varchar[])
+ORDER BY jobs.created_at, jobs.id, logos.id;
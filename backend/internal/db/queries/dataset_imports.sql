@@ -0,0 +1,54 @@
+-- name: CreateDatasetImport :one
+INSERT INTO dataset_imports (
+    id,
+    status,
+    format,
+    name,
+    s3_key
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetDatasetImport :one
+SELECT * FROM dataset_imports WHERE id = $1;
+
+-- name: ListDatasetImports :many
+SELECT * FROM dataset_imports
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ClaimDatasetImport :one
+UPDATE dataset_imports
+SET status = 'processing',
+    updated_at = NOW()
+WHERE id = (
+    SELECT id FROM dataset_imports
+    WHERE s3_key IS NOT NULL
+      AND (status = 'pending' OR (status = 'processing' AND updated_at < $1))
+    ORDER BY created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteDatasetImport :one
+UPDATE dataset_imports
+SET status = 'completed',
+    images_count = $2,
+    annotations_count = $3,
+    skipped_count = $4,
+    requeued_count = $5,
+    invalid_annotations_count = $6,
+    updated_at = NOW(),
+    completed_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: FailDatasetImport :one
+UPDATE dataset_imports
+SET status = 'failed',
+    error_message = $2,
+    updated_at = NOW(),
+    completed_at = NOW()
+WHERE id = $1
+RETURNING *;
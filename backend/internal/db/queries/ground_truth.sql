@@ -0,0 +1,18 @@
+-- name: CreateGroundTruthAnnotation :one
+INSERT INTO ground_truth_annotations (
+    job_id,
+    logo_type,
+    bounding_box
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: ListGroundTruthAnnotations :many
+SELECT * FROM ground_truth_annotations
+WHERE job_id = $1
+ORDER BY id;
+
+-- name: ListGroundTruthByJobIDs :many
+SELECT * FROM ground_truth_annotations
+WHERE job_id = ANY(sqlc.arg(job_ids)::uuid[])
+ORDER BY job_id, id;
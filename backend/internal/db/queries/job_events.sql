@@ -0,0 +1,15 @@
+-- name: CreateJobEvent :one
+INSERT INTO job_events (
+    job_id,
+    from_status,
+    to_status,
+    actor,
+    reason
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListJobEvents :many
+SELECT * FROM job_events
+WHERE job_id = $1
+ORDER BY created_at, id;
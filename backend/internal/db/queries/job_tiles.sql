@@ -0,0 +1,37 @@
+-- name: CreateJobTile :one
+INSERT INTO job_tiles (
+    id,
+    job_id,
+    tile_index,
+    x,
+    y,
+    width,
+    height,
+    s3_key
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetJobTile :one
+SELECT * FROM job_tiles WHERE id = $1;
+
+-- name: ListJobTiles :many
+SELECT * FROM job_tiles
+WHERE job_id = $1
+ORDER BY tile_index;
+
+-- name: UpdateJobTileResult :one
+UPDATE job_tiles
+SET status = $2,
+    detections = $3,
+    model_version = $4,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ResetJobTiles :exec
+-- Tiles that have not completed are detected again when the job is requeued
+UPDATE job_tiles
+SET status = 'pending',
+    updated_at = NOW()
+WHERE job_id = $1 AND status <> 'completed';
@@ -3,36 +3,69 @@ INSERT INTO jobs (
     id,
     status,
     s3_key,
-    upload_url
+    upload_url,
+    content_sha256,
+    params_fingerprint,
+    source_job_id,
+    import_id,
+    model_version,
+    detection_options,
+    tiled
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 ) RETURNING *;
 
 -- name: GetJob :one
 SELECT * FROM jobs WHERE id = $1;
 
--- name: UpdateJobStatus :one
-UPDATE jobs 
-SET status = $2, updated_at = NOW()
-WHERE id = $1
-RETURNING *;
+-- name: GetCachedJob :one
+-- Only jobs served by the requested model are reused, a worker may run another
+-- model than the job asked for. Copies always point at the job that ran detection.
+SELECT * FROM jobs
+WHERE content_sha256 = $1 AND params_fingerprint = $2 AND model_version = $3 AND status = 'completed'
+  AND source_job_id IS NULL
+ORDER BY completed_at DESC
+LIMIT 1;
 
--- name: UpdateJobCompleted :one
-UPDATE jobs 
-SET status = $2, 
-    logos_found = $3,
-    result_url = $4,
-    completed_at = NOW(),
-    updated_at = NOW()
-WHERE id = $1
-RETURNING *;
+-- name: GetImportedJob :one
+SELECT * FROM jobs
+WHERE content_sha256 = $1 AND import_id IS NOT NULL
+LIMIT 1;
+
+-- name: ListJobsByIDs :many
+SELECT * FROM jobs
+WHERE id = ANY(sqlc.arg(ids)::uuid[])
+ORDER BY created_at;
+
+-- name: ListJobsByImport :many
+SELECT * FROM jobs
+WHERE import_id = $1
+ORDER BY created_at;
+
+-- name: UpdateJobModelVersion :exec
+UPDATE jobs
+SET model_version = $2
+WHERE id = $1;
 
--- name: UpdateJobError :one
-UPDATE jobs 
-SET status = $2, 
-    error_message = $3,
+-- name: UpdateJobResultUrl :exec
+UPDATE jobs
+SET result_url = $2
+WHERE id = $1;
+
+-- name: GetJobForUpdate :one
+SELECT * FROM jobs WHERE id = $1 FOR UPDATE;
+
+-- name: UpdateJobStatus :one
+-- A job that runs again drops the error of its earlier attempt
+UPDATE jobs
+SET status = sqlc.arg(status),
+    error_message = CASE WHEN sqlc.arg(status) IN ('pending', 'processing') THEN NULL ELSE COALESCE(sqlc.narg(error_message), error_message) END,
+    logos_found = COALESCE(sqlc.narg(logos_found), logos_found),
+    result_url = COALESCE(sqlc.narg(result_url), result_url),
+    requeue_count = requeue_count + sqlc.arg(requeue_increment),
+    completed_at = CASE WHEN sqlc.arg(status) = 'completed' THEN NOW() ELSE completed_at END,
     updated_at = NOW()
-WHERE id = $1
+WHERE id = sqlc.arg(id) AND status = ANY(sqlc.arg(from_statuses)::varchar[])
 RETURNING *;
 
 -- name: ListJobs :many
@@ -44,3 +77,23 @@ LIMIT $1 OFFSET $2;
 
 -- name: DeleteJob :exec
 DELETE FROM jobs WHERE id = $1;
+
+-- name: ListExpiredJobs :many
+-- Imported jobs have their own cutoff, so ground truth can be kept longer
+SELECT * FROM jobs
+WHERE status = sqlc.arg(status)
+  AND created_at < CASE WHEN import_id IS NULL THEN sqlc.arg(created_at)::timestamptz ELSE sqlc.arg(imported_created_at)::timestamptz END
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+ORDER BY created_at, id
+LIMIT sqlc.arg(result_limit)::int;
+
+-- name: CountExpiredJobs :one
+SELECT COUNT(*) FROM jobs
+WHERE status = sqlc.arg(status)
+  AND created_at < CASE WHEN import_id IS NULL THEN sqlc.arg(created_at)::timestamptz ELSE sqlc.arg(imported_created_at)::timestamptz END;
+
+-- name: ListStaleJobs :many
+SELECT * FROM jobs
+WHERE status = $1 AND updated_at < $2
+ORDER BY updated_at
+LIMIT $3;
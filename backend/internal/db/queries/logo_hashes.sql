@@ -0,0 +1,46 @@
+-- name: UpsertLogoHash :one
+INSERT INTO logo_hashes (
+    logo_id,
+    dhash,
+    phash
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (logo_id) DO UPDATE
+SET dhash = EXCLUDED.dhash,
+    phash = EXCLUDED.phash
+RETURNING *;
+
+-- name: GetLogoHash :one
+SELECT * FROM logo_hashes WHERE logo_id = $1;
+
+-- name: ListUnhashedLogos :many
+SELECT logos.* FROM logos
+LEFT JOIN logo_hashes ON logo_hashes.logo_id = logos.id
+WHERE logo_hashes.logo_id IS NULL AND logos.id > $1
+ORDER BY logos.id
+LIMIT $2;
+
+-- name: SearchSimilarLogos :many
+-- band_keys are the band values near the searched hash, the index narrows
+-- the rows to those sharing one before distances are computed. Cached jobs
+-- copy the logos of their source, copies and the logo itself are skipped.
+SELECT id, job_id, logo_type, confidence, s3_key, distance
+FROM (
+    SELECT logos.id, logos.job_id, logos.logo_type, logos.confidence, logos.s3_key,
+        bit_count(((CASE WHEN sqlc.arg(algorithm)::text = 'dhash' THEN logo_hashes.dhash ELSE logo_hashes.phash END)
+            # sqlc.arg(hash)::bigint)::bit(64))::int AS distance
+    FROM logos
+    JOIN logo_hashes ON logo_hashes.logo_id = logos.id
+    JOIN jobs ON jobs.id = logos.job_id
+    WHERE ((sqlc.arg(algorithm)::text = 'dhash' AND logo_hashes.dhash_bands && sqlc.arg(band_keys)::int[])
+            OR (sqlc.arg(algorithm)::text <> 'dhash' AND logo_hashes.phash_bands && sqlc.arg(band_keys)::int[]))
+        AND jobs.source_job_id IS NULL
+        AND NOT EXISTS (
+            SELECT 1 FROM logos excluded
+            WHERE excluded.id = sqlc.arg(exclude_logo_id)::bigint AND excluded.s3_key = logos.s3_key
+        )
+) matches
+WHERE distance <= sqlc.arg(max_distance)::int
+ORDER BY distance, id
+LIMIT sqlc.arg(result_limit)::int;
@@ -0,0 +1,18 @@
+-- name: CreateLogoReview :one
+INSERT INTO logo_reviews (
+    logo_id,
+    reviewer,
+    decision,
+    previous_logo_type,
+    logo_type,
+    previous_bounding_box,
+    bounding_box,
+    comment
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListLogoReviews :many
+SELECT * FROM logo_reviews
+WHERE logo_id = $1
+ORDER BY created_at, id;
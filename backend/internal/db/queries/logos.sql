@@ -4,10 +4,173 @@ INSERT INTO logos (
     bounding_box,
     confidence,
     logo_type,
-    s3_key
+    s3_key,
+    geometry
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetLogosByJobID :many
-SELECT * FROM logos WHERE job_id = $1 ORDER BY confidence DESC;
\ No newline at end of file
+SELECT * FROM logos WHERE job_id = $1 ORDER BY confidence DESC;
+
+-- name: DeleteLogosByJobID :exec
+DELETE FROM logos WHERE job_id = $1;
+
+-- name: CopyLogosToJob :many
+INSERT INTO logos (
+    job_id,
+    bounding_box,
+    confidence,
+    logo_type,
+    s3_key,
+    brand_id,
+    brand_score,
+    review_status,
+    corners,
+    rectified_s3_key,
+    masked_s3_key,
+    mask_quality,
+    mask_source,
+    geometry,
+    palette,
+    vector_s3_key
+)
+SELECT sqlc.arg(job_id)::uuid, bounding_box, confidence, logo_type, s3_key, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key
+FROM logos
+WHERE logos.job_id = sqlc.arg(source_job_id)::uuid
+ORDER BY id
+RETURNING *;
+
+-- name: ListReferencedLogoKeys :many
+-- Rectified, masked and vectorized crops and overlays are shared with cached jobs too
+SELECT s3_key FROM logos
+WHERE s3_key = ANY(sqlc.arg(keys)::varchar[])
+UNION
+SELECT rectified_s3_key FROM logos
+WHERE rectified_s3_key = ANY(sqlc.arg(keys)::varchar[])
+UNION
+SELECT masked_s3_key FROM logos
+WHERE masked_s3_key = ANY(sqlc.arg(keys)::varchar[])
+UNION
+SELECT vector_s3_key FROM logos
+WHERE vector_s3_key = ANY(sqlc.arg(keys)::varchar[])
+UNION
+SELECT result_url::varchar FROM jobs
+WHERE result_url = ANY(sqlc.arg(keys)::varchar[]);
+
+-- name: GetLogo :one
+SELECT * FROM logos WHERE id = $1;
+
+-- name: UpdateLogoBrand :exec
+UPDATE logos
+SET brand_id = $2,
+    brand_score = $3
+WHERE id = $1;
+
+-- name: UpdateLogoRectification :one
+UPDATE logos
+SET corners = $2,
+    rectified_s3_key = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateLogoMask :one
+UPDATE logos
+SET masked_s3_key = $2,
+    mask_quality = $3,
+    mask_source = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateLogoPalette :one
+UPDATE logos
+SET palette = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateLogoVector :one
+UPDATE logos
+SET vector_s3_key = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: GetLogoForUpdate :one
+SELECT * FROM logos WHERE id = $1 FOR UPDATE;
+
+-- name: UpdateLogoReview :one
+UPDATE logos
+SET review_status = $2,
+    logo_type = $3,
+    bounding_box = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: GetSourceLogo :one
+-- The logo a cached copy was made from, the two share their crop
+SELECT source.* FROM logos source
+JOIN jobs ON jobs.source_job_id = source.job_id
+WHERE jobs.id = sqlc.arg(job_id) AND source.s3_key = sqlc.arg(s3_key)
+LIMIT 1;
+
+-- name: UpdateCopiedLogosReview :many
+-- Copies of a logo in cached jobs take every review decision made on it
+UPDATE logos
+SET review_status = sqlc.arg(review_status),
+    logo_type = sqlc.arg(logo_type),
+    bounding_box = sqlc.arg(bounding_box)
+FROM jobs
+WHERE jobs.id = logos.job_id
+  AND jobs.source_job_id = sqlc.arg(source_job_id)::uuid
+  AND logos.s3_key = sqlc.arg(s3_key)
+RETURNING logos.*;
+
+-- name: ListLogosForReview :many
+-- Logos copied into cached jobs are left out, they are reviewed through their source job
+SELECT logos.* FROM logos
+JOIN jobs ON jobs.id = logos.job_id
+WHERE logos.review_status = 'pending'
+  AND jobs.source_job_id IS NULL
+  AND (sqlc.narg(job_id)::uuid IS NULL OR logos.job_id = sqlc.narg(job_id)::uuid)
+ORDER BY logos.confidence, logos.id
+LIMIT sqlc.arg(result_limit)::int
+OFFSET sqlc.arg(result_offset)::int;
+
+-- name: CountLogosByReviewStatus :many
+SELECT review_status, COUNT(*) AS count FROM logos
+WHERE job_id = $1
+GROUP BY review_status
+ORDER BY review_status;
+
+-- name: ListDetectionsByJobIDs :many
+-- The logos of jobs as the model detected them. Reviews overwrite the logo's type and
+-- box, so reviewed logos take the values kept by their first review. Copies in cached
+-- jobs are reviewed through their source logo and take the values of its first review.
+SELECT logos.id, logos.job_id, logos.confidence,
+    COALESCE(first_review.previous_logo_type, logos.logo_type)::varchar AS logo_type,
+    COALESCE(first_review.previous_bounding_box, logos.bounding_box)::jsonb AS bounding_box
+FROM logos
+JOIN jobs ON jobs.id = logos.job_id
+LEFT JOIN LATERAL (
+    SELECT logo_reviews.previous_logo_type, logo_reviews.previous_bounding_box FROM logo_reviews
+    JOIN logos origin ON origin.id = logo_reviews.logo_id
+    WHERE origin.id = logos.id
+       OR (origin.job_id = jobs.source_job_id AND origin.s3_key = logos.s3_key)
+    ORDER BY logo_reviews.created_at, logo_reviews.id
+    LIMIT 1
+) first_review ON TRUE
+WHERE logos.job_id = ANY(sqlc.arg(job_ids)::uuid[])
+ORDER BY logos.job_id, logos.id;
+
+-- name: FilterLogos :many
+-- Area is width * height and aspect ratio is width / height of the bounding box
+SELECT * FROM logos
+WHERE (sqlc.narg(job_id)::uuid IS NULL OR job_id = sqlc.narg(job_id)::uuid)
+  AND (sqlc.narg(logo_type)::varchar IS NULL OR logo_type = sqlc.narg(logo_type))
+  AND (sqlc.narg(min_confidence)::float8 IS NULL OR confidence >= sqlc.narg(min_confidence))
+  AND (sqlc.narg(min_area)::int8 IS NULL OR (bounding_box->>'width')::int8 * (bounding_box->>'height')::int8 >= sqlc.narg(min_area))
+  AND (sqlc.narg(max_area)::int8 IS NULL OR (bounding_box->>'width')::int8 * (bounding_box->>'height')::int8 <= sqlc.narg(max_area))
+  AND (sqlc.narg(min_aspect_ratio)::float8 IS NULL OR (bounding_box->>'width')::float8 / NULLIF((bounding_box->>'height')::float8, 0) >= sqlc.narg(min_aspect_ratio))
+  AND (sqlc.narg(max_aspect_ratio)::float8 IS NULL OR (bounding_box->>'width')::float8 / NULLIF((bounding_box->>'height')::float8, 0) <= sqlc.narg(max_aspect_ratio))
+ORDER BY confidence DESC, id
+LIMIT sqlc.arg(result_limit)::int
+OFFSET sqlc.arg(result_offset)::int;
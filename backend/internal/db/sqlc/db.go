@@ -24,76 +24,596 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.claimCompositionStmt, err = db.PrepareContext(ctx, claimComposition); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimComposition: %w", err)
+	}
+	if q.claimDatasetExportStmt, err = db.PrepareContext(ctx, claimDatasetExport); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimDatasetExport: %w", err)
+	}
+	if q.claimDatasetImportStmt, err = db.PrepareContext(ctx, claimDatasetImport); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimDatasetImport: %w", err)
+	}
+	if q.completeCompositionStmt, err = db.PrepareContext(ctx, completeComposition); err != nil {
+		return nil, fmt.Errorf("error preparing query CompleteComposition: %w", err)
+	}
+	if q.completeDatasetExportStmt, err = db.PrepareContext(ctx, completeDatasetExport); err != nil {
+		return nil, fmt.Errorf("error preparing query CompleteDatasetExport: %w", err)
+	}
+	if q.completeDatasetImportStmt, err = db.PrepareContext(ctx, completeDatasetImport); err != nil {
+		return nil, fmt.Errorf("error preparing query CompleteDatasetImport: %w", err)
+	}
+	if q.copyLogosToJobStmt, err = db.PrepareContext(ctx, copyLogosToJob); err != nil {
+		return nil, fmt.Errorf("error preparing query CopyLogosToJob: %w", err)
+	}
+	if q.countExpiredJobsStmt, err = db.PrepareContext(ctx, countExpiredJobs); err != nil {
+		return nil, fmt.Errorf("error preparing query CountExpiredJobs: %w", err)
+	}
+	if q.countLogosByReviewStatusStmt, err = db.PrepareContext(ctx, countLogosByReviewStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query CountLogosByReviewStatus: %w", err)
+	}
+	if q.createBrandStmt, err = db.PrepareContext(ctx, createBrand); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateBrand: %w", err)
+	}
+	if q.createBrandReferenceStmt, err = db.PrepareContext(ctx, createBrandReference); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateBrandReference: %w", err)
+	}
+	if q.createCompositionStmt, err = db.PrepareContext(ctx, createComposition); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateComposition: %w", err)
+	}
+	if q.createDatasetExportStmt, err = db.PrepareContext(ctx, createDatasetExport); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateDatasetExport: %w", err)
+	}
+	if q.createDatasetImportStmt, err = db.PrepareContext(ctx, createDatasetImport); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateDatasetImport: %w", err)
+	}
+	if q.createGroundTruthAnnotationStmt, err = db.PrepareContext(ctx, createGroundTruthAnnotation); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateGroundTruthAnnotation: %w", err)
+	}
 	if q.createJobStmt, err = db.PrepareContext(ctx, createJob); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateJob: %w", err)
 	}
+	if q.createJobEventStmt, err = db.PrepareContext(ctx, createJobEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateJobEvent: %w", err)
+	}
+	if q.createJobTileStmt, err = db.PrepareContext(ctx, createJobTile); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateJobTile: %w", err)
+	}
 	if q.createLogoStmt, err = db.PrepareContext(ctx, createLogo); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateLogo: %w", err)
 	}
+	if q.createLogoReviewStmt, err = db.PrepareContext(ctx, createLogoReview); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateLogoReview: %w", err)
+	}
+	if q.deleteBrandStmt, err = db.PrepareContext(ctx, deleteBrand); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteBrand: %w", err)
+	}
+	if q.deleteBrandReferenceStmt, err = db.PrepareContext(ctx, deleteBrandReference); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteBrandReference: %w", err)
+	}
 	if q.deleteJobStmt, err = db.PrepareContext(ctx, deleteJob); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteJob: %w", err)
 	}
+	if q.deleteLogosByJobIDStmt, err = db.PrepareContext(ctx, deleteLogosByJobID); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteLogosByJobID: %w", err)
+	}
+	if q.failCompositionStmt, err = db.PrepareContext(ctx, failComposition); err != nil {
+		return nil, fmt.Errorf("error preparing query FailComposition: %w", err)
+	}
+	if q.failDatasetExportStmt, err = db.PrepareContext(ctx, failDatasetExport); err != nil {
+		return nil, fmt.Errorf("error preparing query FailDatasetExport: %w", err)
+	}
+	if q.failDatasetImportStmt, err = db.PrepareContext(ctx, failDatasetImport); err != nil {
+		return nil, fmt.Errorf("error preparing query FailDatasetImport: %w", err)
+	}
+	if q.filterLogosStmt, err = db.PrepareContext(ctx, filterLogos); err != nil {
+		return nil, fmt.Errorf("error preparing query FilterLogos: %w", err)
+	}
+	if q.getBrandStmt, err = db.PrepareContext(ctx, getBrand); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBrand: %w", err)
+	}
+	if q.getBrandReferenceStmt, err = db.PrepareContext(ctx, getBrandReference); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBrandReference: %w", err)
+	}
+	if q.getCachedJobStmt, err = db.PrepareContext(ctx, getCachedJob); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCachedJob: %w", err)
+	}
+	if q.getCompositionStmt, err = db.PrepareContext(ctx, getComposition); err != nil {
+		return nil, fmt.Errorf("error preparing query GetComposition: %w", err)
+	}
+	if q.getDatasetExportStmt, err = db.PrepareContext(ctx, getDatasetExport); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDatasetExport: %w", err)
+	}
+	if q.getDatasetImportStmt, err = db.PrepareContext(ctx, getDatasetImport); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDatasetImport: %w", err)
+	}
+	if q.getImportedJobStmt, err = db.PrepareContext(ctx, getImportedJob); err != nil {
+		return nil, fmt.Errorf("error preparing query GetImportedJob: %w", err)
+	}
 	if q.getJobStmt, err = db.PrepareContext(ctx, getJob); err != nil {
 		return nil, fmt.Errorf("error preparing query GetJob: %w", err)
 	}
+	if q.getJobForUpdateStmt, err = db.PrepareContext(ctx, getJobForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetJobForUpdate: %w", err)
+	}
+	if q.getJobTileStmt, err = db.PrepareContext(ctx, getJobTile); err != nil {
+		return nil, fmt.Errorf("error preparing query GetJobTile: %w", err)
+	}
+	if q.getLogoStmt, err = db.PrepareContext(ctx, getLogo); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLogo: %w", err)
+	}
+	if q.getLogoForUpdateStmt, err = db.PrepareContext(ctx, getLogoForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLogoForUpdate: %w", err)
+	}
+	if q.getLogoHashStmt, err = db.PrepareContext(ctx, getLogoHash); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLogoHash: %w", err)
+	}
 	if q.getLogosByJobIDStmt, err = db.PrepareContext(ctx, getLogosByJobID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetLogosByJobID: %w", err)
 	}
+	if q.getSourceLogoStmt, err = db.PrepareContext(ctx, getSourceLogo); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSourceLogo: %w", err)
+	}
+	if q.listBrandReferencesStmt, err = db.PrepareContext(ctx, listBrandReferences); err != nil {
+		return nil, fmt.Errorf("error preparing query ListBrandReferences: %w", err)
+	}
+	if q.listBrandReferencesByBrandStmt, err = db.PrepareContext(ctx, listBrandReferencesByBrand); err != nil {
+		return nil, fmt.Errorf("error preparing query ListBrandReferencesByBrand: %w", err)
+	}
+	if q.listBrandsStmt, err = db.PrepareContext(ctx, listBrands); err != nil {
+		return nil, fmt.Errorf("error preparing query ListBrands: %w", err)
+	}
+	if q.listCompositionsStmt, err = db.PrepareContext(ctx, listCompositions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCompositions: %w", err)
+	}
+	if q.listDatasetExportsStmt, err = db.PrepareContext(ctx, listDatasetExports); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDatasetExports: %w", err)
+	}
+	if q.listDatasetImportsStmt, err = db.PrepareContext(ctx, listDatasetImports); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDatasetImports: %w", err)
+	}
+	if q.listDetectionsByJobIDsStmt, err = db.PrepareContext(ctx, listDetectionsByJobIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDetectionsByJobIDs: %w", err)
+	}
+	if q.listExpiredJobsStmt, err = db.PrepareContext(ctx, listExpiredJobs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListExpiredJobs: %w", err)
+	}
+	if q.listGroundTruthAnnotationsStmt, err = db.PrepareContext(ctx, listGroundTruthAnnotations); err != nil {
+		return nil, fmt.Errorf("error preparing query ListGroundTruthAnnotations: %w", err)
+	}
+	if q.listGroundTruthByJobIDsStmt, err = db.PrepareContext(ctx, listGroundTruthByJobIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListGroundTruthByJobIDs: %w", err)
+	}
+	if q.listJobEventsStmt, err = db.PrepareContext(ctx, listJobEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJobEvents: %w", err)
+	}
+	if q.listJobTilesStmt, err = db.PrepareContext(ctx, listJobTiles); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJobTiles: %w", err)
+	}
 	if q.listJobsStmt, err = db.PrepareContext(ctx, listJobs); err != nil {
 		return nil, fmt.Errorf("error preparing query ListJobs: %w", err)
 	}
-	if q.updateJobCompletedStmt, err = db.PrepareContext(ctx, updateJobCompleted); err != nil {
-		return nil, fmt.Errorf("error preparing query UpdateJobCompleted: %w", err)
+	if q.listJobsByIDsStmt, err = db.PrepareContext(ctx, listJobsByIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJobsByIDs: %w", err)
+	}
+	if q.listJobsByImportStmt, err = db.PrepareContext(ctx, listJobsByImport); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJobsByImport: %w", err)
+	}
+	if q.listLogoReviewsStmt, err = db.PrepareContext(ctx, listLogoReviews); err != nil {
+		return nil, fmt.Errorf("error preparing query ListLogoReviews: %w", err)
+	}
+	if q.listLogosForExportStmt, err = db.PrepareContext(ctx, listLogosForExport); err != nil {
+		return nil, fmt.Errorf("error preparing query ListLogosForExport: %w", err)
 	}
-	if q.updateJobErrorStmt, err = db.PrepareContext(ctx, updateJobError); err != nil {
-		return nil, fmt.Errorf("error preparing query UpdateJobError: %w", err)
+	if q.listLogosForReviewStmt, err = db.PrepareContext(ctx, listLogosForReview); err != nil {
+		return nil, fmt.Errorf("error preparing query ListLogosForReview: %w", err)
+	}
+	if q.listReferencedLogoKeysStmt, err = db.PrepareContext(ctx, listReferencedLogoKeys); err != nil {
+		return nil, fmt.Errorf("error preparing query ListReferencedLogoKeys: %w", err)
+	}
+	if q.listStaleJobsStmt, err = db.PrepareContext(ctx, listStaleJobs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListStaleJobs: %w", err)
+	}
+	if q.listUnhashedLogosStmt, err = db.PrepareContext(ctx, listUnhashedLogos); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUnhashedLogos: %w", err)
+	}
+	if q.resetJobTilesStmt, err = db.PrepareContext(ctx, resetJobTiles); err != nil {
+		return nil, fmt.Errorf("error preparing query ResetJobTiles: %w", err)
+	}
+	if q.searchSimilarLogosStmt, err = db.PrepareContext(ctx, searchSimilarLogos); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchSimilarLogos: %w", err)
+	}
+	if q.updateBrandStmt, err = db.PrepareContext(ctx, updateBrand); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateBrand: %w", err)
+	}
+	if q.updateCopiedLogosReviewStmt, err = db.PrepareContext(ctx, updateCopiedLogosReview); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateCopiedLogosReview: %w", err)
+	}
+	if q.updateJobModelVersionStmt, err = db.PrepareContext(ctx, updateJobModelVersion); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateJobModelVersion: %w", err)
+	}
+	if q.updateJobResultUrlStmt, err = db.PrepareContext(ctx, updateJobResultUrl); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateJobResultUrl: %w", err)
 	}
 	if q.updateJobStatusStmt, err = db.PrepareContext(ctx, updateJobStatus); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateJobStatus: %w", err)
 	}
+	if q.updateJobTileResultStmt, err = db.PrepareContext(ctx, updateJobTileResult); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateJobTileResult: %w", err)
+	}
+	if q.updateLogoBrandStmt, err = db.PrepareContext(ctx, updateLogoBrand); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateLogoBrand: %w", err)
+	}
+	if q.updateLogoMaskStmt, err = db.PrepareContext(ctx, updateLogoMask); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateLogoMask: %w", err)
+	}
+	if q.updateLogoPaletteStmt, err = db.PrepareContext(ctx, updateLogoPalette); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateLogoPalette: %w", err)
+	}
+	if q.updateLogoRectificationStmt, err = db.PrepareContext(ctx, updateLogoRectification); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateLogoRectification: %w", err)
+	}
+	if q.updateLogoReviewStmt, err = db.PrepareContext(ctx, updateLogoReview); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateLogoReview: %w", err)
+	}
+	if q.updateLogoVectorStmt, err = db.PrepareContext(ctx, updateLogoVector); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateLogoVector: %w", err)
+	}
+	if q.upsertLogoHashStmt, err = db.PrepareContext(ctx, upsertLogoHash); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertLogoHash: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.claimCompositionStmt != nil {
+		if cerr := q.claimCompositionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimCompositionStmt: %w", cerr)
+		}
+	}
+	if q.claimDatasetExportStmt != nil {
+		if cerr := q.claimDatasetExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimDatasetExportStmt: %w", cerr)
+		}
+	}
+	if q.claimDatasetImportStmt != nil {
+		if cerr := q.claimDatasetImportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimDatasetImportStmt: %w", cerr)
+		}
+	}
+	if q.completeCompositionStmt != nil {
+		if cerr := q.completeCompositionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing completeCompositionStmt: %w", cerr)
+		}
+	}
+	if q.completeDatasetExportStmt != nil {
+		if cerr := q.completeDatasetExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing completeDatasetExportStmt: %w", cerr)
+		}
+	}
+	if q.completeDatasetImportStmt != nil {
+		if cerr := q.completeDatasetImportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing completeDatasetImportStmt: %w", cerr)
+		}
+	}
+	if q.copyLogosToJobStmt != nil {
+		if cerr := q.copyLogosToJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing copyLogosToJobStmt: %w", cerr)
+		}
+	}
+	if q.countExpiredJobsStmt != nil {
+		if cerr := q.countExpiredJobsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countExpiredJobsStmt: %w", cerr)
+		}
+	}
+	if q.countLogosByReviewStatusStmt != nil {
+		if cerr := q.countLogosByReviewStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countLogosByReviewStatusStmt: %w", cerr)
+		}
+	}
+	if q.createBrandStmt != nil {
+		if cerr := q.createBrandStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createBrandStmt: %w", cerr)
+		}
+	}
+	if q.createBrandReferenceStmt != nil {
+		if cerr := q.createBrandReferenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createBrandReferenceStmt: %w", cerr)
+		}
+	}
+	if q.createCompositionStmt != nil {
+		if cerr := q.createCompositionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCompositionStmt: %w", cerr)
+		}
+	}
+	if q.createDatasetExportStmt != nil {
+		if cerr := q.createDatasetExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createDatasetExportStmt: %w", cerr)
+		}
+	}
+	if q.createDatasetImportStmt != nil {
+		if cerr := q.createDatasetImportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createDatasetImportStmt: %w", cerr)
+		}
+	}
+	if q.createGroundTruthAnnotationStmt != nil {
+		if cerr := q.createGroundTruthAnnotationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createGroundTruthAnnotationStmt: %w", cerr)
+		}
+	}
 	if q.createJobStmt != nil {
 		if cerr := q.createJobStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createJobStmt: %w", cerr)
 		}
 	}
+	if q.createJobEventStmt != nil {
+		if cerr := q.createJobEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createJobEventStmt: %w", cerr)
+		}
+	}
+	if q.createJobTileStmt != nil {
+		if cerr := q.createJobTileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createJobTileStmt: %w", cerr)
+		}
+	}
 	if q.createLogoStmt != nil {
 		if cerr := q.createLogoStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createLogoStmt: %w", cerr)
 		}
 	}
+	if q.createLogoReviewStmt != nil {
+		if cerr := q.createLogoReviewStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createLogoReviewStmt: %w", cerr)
+		}
+	}
+	if q.deleteBrandStmt != nil {
+		if cerr := q.deleteBrandStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteBrandStmt: %w", cerr)
+		}
+	}
+	if q.deleteBrandReferenceStmt != nil {
+		if cerr := q.deleteBrandReferenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteBrandReferenceStmt: %w", cerr)
+		}
+	}
 	if q.deleteJobStmt != nil {
 		if cerr := q.deleteJobStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteJobStmt: %w", cerr)
 		}
 	}
+	if q.deleteLogosByJobIDStmt != nil {
+		if cerr := q.deleteLogosByJobIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteLogosByJobIDStmt: %w", cerr)
+		}
+	}
+	if q.failCompositionStmt != nil {
+		if cerr := q.failCompositionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failCompositionStmt: %w", cerr)
+		}
+	}
+	if q.failDatasetExportStmt != nil {
+		if cerr := q.failDatasetExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failDatasetExportStmt: %w", cerr)
+		}
+	}
+	if q.failDatasetImportStmt != nil {
+		if cerr := q.failDatasetImportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failDatasetImportStmt: %w", cerr)
+		}
+	}
+	if q.filterLogosStmt != nil {
+		if cerr := q.filterLogosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing filterLogosStmt: %w", cerr)
+		}
+	}
+	if q.getBrandStmt != nil {
+		if cerr := q.getBrandStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBrandStmt: %w", cerr)
+		}
+	}
+	if q.getBrandReferenceStmt != nil {
+		if cerr := q.getBrandReferenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBrandReferenceStmt: %w", cerr)
+		}
+	}
+	if q.getCachedJobStmt != nil {
+		if cerr := q.getCachedJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCachedJobStmt: %w", cerr)
+		}
+	}
+	if q.getCompositionStmt != nil {
+		if cerr := q.getCompositionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCompositionStmt: %w", cerr)
+		}
+	}
+	if q.getDatasetExportStmt != nil {
+		if cerr := q.getDatasetExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDatasetExportStmt: %w", cerr)
+		}
+	}
+	if q.getDatasetImportStmt != nil {
+		if cerr := q.getDatasetImportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDatasetImportStmt: %w", cerr)
+		}
+	}
+	if q.getImportedJobStmt != nil {
+		if cerr := q.getImportedJobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getImportedJobStmt: %w", cerr)
+		}
+	}
 	if q.getJobStmt != nil {
 		if cerr := q.getJobStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getJobStmt: %w", cerr)
 		}
 	}
+	if q.getJobForUpdateStmt != nil {
+		if cerr := q.getJobForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getJobForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getJobTileStmt != nil {
+		if cerr := q.getJobTileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getJobTileStmt: %w", cerr)
+		}
+	}
+	if q.getLogoStmt != nil {
+		if cerr := q.getLogoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLogoStmt: %w", cerr)
+		}
+	}
+	if q.getLogoForUpdateStmt != nil {
+		if cerr := q.getLogoForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLogoForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getLogoHashStmt != nil {
+		if cerr := q.getLogoHashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLogoHashStmt: %w", cerr)
+		}
+	}
 	if q.getLogosByJobIDStmt != nil {
 		if cerr := q.getLogosByJobIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getLogosByJobIDStmt: %w", cerr)
 		}
 	}
+	if q.getSourceLogoStmt != nil {
+		if cerr := q.getSourceLogoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSourceLogoStmt: %w", cerr)
+		}
+	}
+	if q.listBrandReferencesStmt != nil {
+		if cerr := q.listBrandReferencesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listBrandReferencesStmt: %w", cerr)
+		}
+	}
+	if q.listBrandReferencesByBrandStmt != nil {
+		if cerr := q.listBrandReferencesByBrandStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listBrandReferencesByBrandStmt: %w", cerr)
+		}
+	}
+	if q.listBrandsStmt != nil {
+		if cerr := q.listBrandsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listBrandsStmt: %w", cerr)
+		}
+	}
+	if q.listCompositionsStmt != nil {
+		if cerr := q.listCompositionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCompositionsStmt: %w", cerr)
+		}
+	}
+	if q.listDatasetExportsStmt != nil {
+		if cerr := q.listDatasetExportsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDatasetExportsStmt: %w", cerr)
+		}
+	}
+	if q.listDatasetImportsStmt != nil {
+		if cerr := q.listDatasetImportsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDatasetImportsStmt: %w", cerr)
+		}
+	}
+	if q.listDetectionsByJobIDsStmt != nil {
+		if cerr := q.listDetectionsByJobIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDetectionsByJobIDsStmt: %w", cerr)
+		}
+	}
+	if q.listExpiredJobsStmt != nil {
+		if cerr := q.listExpiredJobsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listExpiredJobsStmt: %w", cerr)
+		}
+	}
+	if q.listGroundTruthAnnotationsStmt != nil {
+		if cerr := q.listGroundTruthAnnotationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listGroundTruthAnnotationsStmt: %w", cerr)
+		}
+	}
+	if q.listGroundTruthByJobIDsStmt != nil {
+		if cerr := q.listGroundTruthByJobIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listGroundTruthByJobIDsStmt: %w", cerr)
+		}
+	}
+	if q.listJobEventsStmt != nil {
+		if cerr := q.listJobEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJobEventsStmt: %w", cerr)
+		}
+	}
+	if q.listJobTilesStmt != nil {
+		if cerr := q.listJobTilesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJobTilesStmt: %w", cerr)
+		}
+	}
 	if q.listJobsStmt != nil {
 		if cerr := q.listJobsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listJobsStmt: %w", cerr)
 		}
 	}
-	if q.updateJobCompletedStmt != nil {
-		if cerr := q.updateJobCompletedStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing updateJobCompletedStmt: %w", cerr)
+	if q.listJobsByIDsStmt != nil {
+		if cerr := q.listJobsByIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJobsByIDsStmt: %w", cerr)
+		}
+	}
+	if q.listJobsByImportStmt != nil {
+		if cerr := q.listJobsByImportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJobsByImportStmt: %w", cerr)
+		}
+	}
+	if q.listLogoReviewsStmt != nil {
+		if cerr := q.listLogoReviewsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listLogoReviewsStmt: %w", cerr)
+		}
+	}
+	if q.listLogosForExportStmt != nil {
+		if cerr := q.listLogosForExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listLogosForExportStmt: %w", cerr)
+		}
+	}
+	if q.listLogosForReviewStmt != nil {
+		if cerr := q.listLogosForReviewStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listLogosForReviewStmt: %w", cerr)
+		}
+	}
+	if q.listReferencedLogoKeysStmt != nil {
+		if cerr := q.listReferencedLogoKeysStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listReferencedLogoKeysStmt: %w", cerr)
+		}
+	}
+	if q.listStaleJobsStmt != nil {
+		if cerr := q.listStaleJobsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listStaleJobsStmt: %w", cerr)
+		}
+	}
+	if q.listUnhashedLogosStmt != nil {
+		if cerr := q.listUnhashedLogosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUnhashedLogosStmt: %w", cerr)
 		}
 	}
-	if q.updateJobErrorStmt != nil {
-		if cerr := q.updateJobErrorStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing updateJobErrorStmt: %w", cerr)
+	if q.resetJobTilesStmt != nil {
+		if cerr := q.resetJobTilesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing resetJobTilesStmt: %w", cerr)
+		}
+	}
+	if q.searchSimilarLogosStmt != nil {
+		if cerr := q.searchSimilarLogosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchSimilarLogosStmt: %w", cerr)
+		}
+	}
+	if q.updateBrandStmt != nil {
+		if cerr := q.updateBrandStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateBrandStmt: %w", cerr)
+		}
+	}
+	if q.updateCopiedLogosReviewStmt != nil {
+		if cerr := q.updateCopiedLogosReviewStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateCopiedLogosReviewStmt: %w", cerr)
+		}
+	}
+	if q.updateJobModelVersionStmt != nil {
+		if cerr := q.updateJobModelVersionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateJobModelVersionStmt: %w", cerr)
+		}
+	}
+	if q.updateJobResultUrlStmt != nil {
+		if cerr := q.updateJobResultUrlStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateJobResultUrlStmt: %w", cerr)
 		}
 	}
 	if q.updateJobStatusStmt != nil {
@@ -101,6 +621,46 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateJobStatusStmt: %w", cerr)
 		}
 	}
+	if q.updateJobTileResultStmt != nil {
+		if cerr := q.updateJobTileResultStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateJobTileResultStmt: %w", cerr)
+		}
+	}
+	if q.updateLogoBrandStmt != nil {
+		if cerr := q.updateLogoBrandStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateLogoBrandStmt: %w", cerr)
+		}
+	}
+	if q.updateLogoMaskStmt != nil {
+		if cerr := q.updateLogoMaskStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateLogoMaskStmt: %w", cerr)
+		}
+	}
+	if q.updateLogoPaletteStmt != nil {
+		if cerr := q.updateLogoPaletteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateLogoPaletteStmt: %w", cerr)
+		}
+	}
+	if q.updateLogoRectificationStmt != nil {
+		if cerr := q.updateLogoRectificationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateLogoRectificationStmt: %w", cerr)
+		}
+	}
+	if q.updateLogoReviewStmt != nil {
+		if cerr := q.updateLogoReviewStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateLogoReviewStmt: %w", cerr)
+		}
+	}
+	if q.updateLogoVectorStmt != nil {
+		if cerr := q.updateLogoVectorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateLogoVectorStmt: %w", cerr)
+		}
+	}
+	if q.upsertLogoHashStmt != nil {
+		if cerr := q.upsertLogoHashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertLogoHashStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -138,31 +698,171 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                     DBTX
-	tx                     *sql.Tx
-	createJobStmt          *sql.Stmt
-	createLogoStmt         *sql.Stmt
-	deleteJobStmt          *sql.Stmt
-	getJobStmt             *sql.Stmt
-	getLogosByJobIDStmt    *sql.Stmt
-	listJobsStmt           *sql.Stmt
-	updateJobCompletedStmt *sql.Stmt
-	updateJobErrorStmt     *sql.Stmt
-	updateJobStatusStmt    *sql.Stmt
+	db                              DBTX
+	tx                              *sql.Tx
+	claimCompositionStmt            *sql.Stmt
+	claimDatasetExportStmt          *sql.Stmt
+	claimDatasetImportStmt          *sql.Stmt
+	completeCompositionStmt         *sql.Stmt
+	completeDatasetExportStmt       *sql.Stmt
+	completeDatasetImportStmt       *sql.Stmt
+	copyLogosToJobStmt              *sql.Stmt
+	countExpiredJobsStmt            *sql.Stmt
+	countLogosByReviewStatusStmt    *sql.Stmt
+	createBrandStmt                 *sql.Stmt
+	createBrandReferenceStmt        *sql.Stmt
+	createCompositionStmt           *sql.Stmt
+	createDatasetExportStmt         *sql.Stmt
+	createDatasetImportStmt         *sql.Stmt
+	createGroundTruthAnnotationStmt *sql.Stmt
+	createJobStmt                   *sql.Stmt
+	createJobEventStmt              *sql.Stmt
+	createJobTileStmt               *sql.Stmt
+	createLogoStmt                  *sql.Stmt
+	createLogoReviewStmt            *sql.Stmt
+	deleteBrandStmt                 *sql.Stmt
+	deleteBrandReferenceStmt        *sql.Stmt
+	deleteJobStmt                   *sql.Stmt
+	deleteLogosByJobIDStmt          *sql.Stmt
+	failCompositionStmt             *sql.Stmt
+	failDatasetExportStmt           *sql.Stmt
+	failDatasetImportStmt           *sql.Stmt
+	filterLogosStmt                 *sql.Stmt
+	getBrandStmt                    *sql.Stmt
+	getBrandReferenceStmt           *sql.Stmt
+	getCachedJobStmt                *sql.Stmt
+	getCompositionStmt              *sql.Stmt
+	getDatasetExportStmt            *sql.Stmt
+	getDatasetImportStmt            *sql.Stmt
+	getImportedJobStmt              *sql.Stmt
+	getJobStmt                      *sql.Stmt
+	getJobForUpdateStmt             *sql.Stmt
+	getJobTileStmt                  *sql.Stmt
+	getLogoStmt                     *sql.Stmt
+	getLogoForUpdateStmt            *sql.Stmt
+	getLogoHashStmt                 *sql.Stmt
+	getLogosByJobIDStmt             *sql.Stmt
+	getSourceLogoStmt               *sql.Stmt
+	listBrandReferencesStmt         *sql.Stmt
+	listBrandReferencesByBrandStmt  *sql.Stmt
+	listBrandsStmt                  *sql.Stmt
+	listCompositionsStmt            *sql.Stmt
+	listDatasetExportsStmt          *sql.Stmt
+	listDatasetImportsStmt          *sql.Stmt
+	listDetectionsByJobIDsStmt      *sql.Stmt
+	listExpiredJobsStmt             *sql.Stmt
+	listGroundTruthAnnotationsStmt  *sql.Stmt
+	listGroundTruthByJobIDsStmt     *sql.Stmt
+	listJobEventsStmt               *sql.Stmt
+	listJobTilesStmt                *sql.Stmt
+	listJobsStmt                    *sql.Stmt
+	listJobsByIDsStmt               *sql.Stmt
+	listJobsByImportStmt            *sql.Stmt
+	listLogoReviewsStmt             *sql.Stmt
+	listLogosForExportStmt          *sql.Stmt
+	listLogosForReviewStmt          *sql.Stmt
+	listReferencedLogoKeysStmt      *sql.Stmt
+	listStaleJobsStmt               *sql.Stmt
+	listUnhashedLogosStmt           *sql.Stmt
+	resetJobTilesStmt               *sql.Stmt
+	searchSimilarLogosStmt          *sql.Stmt
+	updateBrandStmt                 *sql.Stmt
+	updateCopiedLogosReviewStmt     *sql.Stmt
+	updateJobModelVersionStmt       *sql.Stmt
+	updateJobResultUrlStmt          *sql.Stmt
+	updateJobStatusStmt             *sql.Stmt
+	updateJobTileResultStmt         *sql.Stmt
+	updateLogoBrandStmt             *sql.Stmt
+	updateLogoMaskStmt              *sql.Stmt
+	updateLogoPaletteStmt           *sql.Stmt
+	updateLogoRectificationStmt     *sql.Stmt
+	updateLogoReviewStmt            *sql.Stmt
+	updateLogoVectorStmt            *sql.Stmt
+	upsertLogoHashStmt              *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                     tx,
-		tx:                     tx,
-		createJobStmt:          q.createJobStmt,
-		createLogoStmt:         q.createLogoStmt,
-		deleteJobStmt:          q.deleteJobStmt,
-		getJobStmt:             q.getJobStmt,
-		getLogosByJobIDStmt:    q.getLogosByJobIDStmt,
-		listJobsStmt:           q.listJobsStmt,
-		updateJobCompletedStmt: q.updateJobCompletedStmt,
-		updateJobErrorStmt:     q.updateJobErrorStmt,
-		updateJobStatusStmt:    q.updateJobStatusStmt,
+		db:                              tx,
+		tx:                              tx,
+		claimCompositionStmt:            q.claimCompositionStmt,
+		claimDatasetExportStmt:          q.claimDatasetExportStmt,
+		claimDatasetImportStmt:          q.claimDatasetImportStmt,
+		completeCompositionStmt:         q.completeCompositionStmt,
+		completeDatasetExportStmt:       q.completeDatasetExportStmt,
+		completeDatasetImportStmt:       q.completeDatasetImportStmt,
+		copyLogosToJobStmt:              q.copyLogosToJobStmt,
+		countExpiredJobsStmt:            q.countExpiredJobsStmt,
+		countLogosByReviewStatusStmt:    q.countLogosByReviewStatusStmt,
+		createBrandStmt:                 q.createBrandStmt,
+		createBrandReferenceStmt:        q.createBrandReferenceStmt,
+		createCompositionStmt:           q.createCompositionStmt,
+		createDatasetExportStmt:         q.createDatasetExportStmt,
+		createDatasetImportStmt:         q.createDatasetImportStmt,
+		createGroundTruthAnnotationStmt: q.createGroundTruthAnnotationStmt,
+		createJobStmt:                   q.createJobStmt,
+		createJobEventStmt:              q.createJobEventStmt,
+		createJobTileStmt:               q.createJobTileStmt,
+		createLogoStmt:                  q.createLogoStmt,
+		createLogoReviewStmt:            q.createLogoReviewStmt,
+		deleteBrandStmt:                 q.deleteBrandStmt,
+		deleteBrandReferenceStmt:        q.deleteBrandReferenceStmt,
+		deleteJobStmt:                   q.deleteJobStmt,
+		deleteLogosByJobIDStmt:          q.deleteLogosByJobIDStmt,
+		failCompositionStmt:             q.failCompositionStmt,
+		failDatasetExportStmt:           q.failDatasetExportStmt,
+		failDatasetImportStmt:           q.failDatasetImportStmt,
+		filterLogosStmt:                 q.filterLogosStmt,
+		getBrandStmt:                    q.getBrandStmt,
+		getBrandReferenceStmt:           q.getBrandReferenceStmt,
+		getCachedJobStmt:                q.getCachedJobStmt,
+		getCompositionStmt:              q.getCompositionStmt,
+		getDatasetExportStmt:            q.getDatasetExportStmt,
+		getDatasetImportStmt:            q.getDatasetImportStmt,
+		getImportedJobStmt:              q.getImportedJobStmt,
+		getJobStmt:                      q.getJobStmt,
+		getJobForUpdateStmt:             q.getJobForUpdateStmt,
+		getJobTileStmt:                  q.getJobTileStmt,
+		getLogoStmt:                     q.getLogoStmt,
+		getLogoForUpdateStmt:            q.getLogoForUpdateStmt,
+		getLogoHashStmt:                 q.getLogoHashStmt,
+		getLogosByJobIDStmt:             q.getLogosByJobIDStmt,
+		getSourceLogoStmt:               q.getSourceLogoStmt,
+		listBrandReferencesStmt:         q.listBrandReferencesStmt,
+		listBrandReferencesByBrandStmt:  q.listBrandReferencesByBrandStmt,
+		listBrandsStmt:                  q.listBrandsStmt,
+		listCompositionsStmt:            q.listCompositionsStmt,
+		listDatasetExportsStmt:          q.listDatasetExportsStmt,
+		listDatasetImportsStmt:          q.listDatasetImportsStmt,
+		listDetectionsByJobIDsStmt:      q.listDetectionsByJobIDsStmt,
+		listExpiredJobsStmt:             q.listExpiredJobsStmt,
+		listGroundTruthAnnotationsStmt:  q.listGroundTruthAnnotationsStmt,
+		listGroundTruthByJobIDsStmt:     q.listGroundTruthByJobIDsStmt,
+		listJobEventsStmt:               q.listJobEventsStmt,
+		listJobTilesStmt:                q.listJobTilesStmt,
+		listJobsStmt:                    q.listJobsStmt,
+		listJobsByIDsStmt:               q.listJobsByIDsStmt,
+		listJobsByImportStmt:            q.listJobsByImportStmt,
+		listLogoReviewsStmt:             q.listLogoReviewsStmt,
+		listLogosForExportStmt:          q.listLogosForExportStmt,
+		listLogosForReviewStmt:          q.listLogosForReviewStmt,
+		listReferencedLogoKeysStmt:      q.listReferencedLogoKeysStmt,
+		listStaleJobsStmt:               q.listStaleJobsStmt,
+		listUnhashedLogosStmt:           q.listUnhashedLogosStmt,
+		resetJobTilesStmt:               q.resetJobTilesStmt,
+		searchSimilarLogosStmt:          q.searchSimilarLogosStmt,
+		updateBrandStmt:                 q.updateBrandStmt,
+		updateCopiedLogosReviewStmt:     q.updateCopiedLogosReviewStmt,
+		updateJobModelVersionStmt:       q.updateJobModelVersionStmt,
+		updateJobResultUrlStmt:          q.updateJobResultUrlStmt,
+		updateJobStatusStmt:             q.updateJobStatusStmt,
+		updateJobTileResultStmt:         q.updateJobTileResultStmt,
+		updateLogoBrandStmt:             q.updateLogoBrandStmt,
+		updateLogoMaskStmt:              q.updateLogoMaskStmt,
+		updateLogoPaletteStmt:           q.updateLogoPaletteStmt,
+		updateLogoRectificationStmt:     q.updateLogoRectificationStmt,
+		updateLogoReviewStmt:            q.updateLogoReviewStmt,
+		updateLogoVectorStmt:            q.updateLogoVectorStmt,
+		upsertLogoHashStmt:              q.upsertLogoHashStmt,
 	}
 }
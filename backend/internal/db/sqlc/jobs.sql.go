@@ -8,24 +8,62 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/db"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countExpiredJobs = `-- name: CountExpiredJobs :one
+SELECT COUNT(*) FROM jobs
+WHERE status = $1
+  AND created_at < CASE WHEN import_id IS NULL THEN $2::timestamptz ELSE $3::timestamptz END
+`
+
+type CountExpiredJobsParams struct {
+	Status            string    `json:"status"`
+	CreatedAt         time.Time `json:"created_at"`
+	ImportedCreatedAt time.Time `json:"imported_created_at"`
+}
+
+func (q *Queries) CountExpiredJobs(ctx context.Context, arg CountExpiredJobsParams) (int64, error) {
+	row := q.queryRow(ctx, q.countExpiredJobsStmt, countExpiredJobs, arg.Status, arg.CreatedAt, arg.ImportedCreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createJob = `-- name: CreateJob :one
 INSERT INTO jobs (
     id,
     status,
     s3_key,
-    upload_url
+    upload_url,
+    content_sha256,
+    params_fingerprint,
+    source_job_id,
+    import_id,
+    model_version,
+    detection_options,
+    tiled
 ) VALUES (
-    $1, $2, $3, $4
-) RETURNING id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled
 `
 
 type CreateJobParams struct {
-	ID        int64  `json:"id"`
-	Status    string `json:"status"`
-	S3Key     string `json:"s3_key"`
-	UploadUrl string `json:"upload_url"`
+	ID                uuid.UUID           `json:"id"`
+	Status            string              `json:"status"`
+	S3Key             string              `json:"s3_key"`
+	UploadUrl         string              `json:"upload_url"`
+	ContentSha256     sql.NullString      `json:"content_sha256"`
+	ParamsFingerprint sql.NullString      `json:"params_fingerprint"`
+	SourceJobID       uuid.NullUUID       `json:"source_job_id"`
+	ImportID          uuid.NullUUID       `json:"import_id"`
+	ModelVersion      sql.NullString      `json:"model_version"`
+	DetectionOptions  db.DetectionOptions `json:"detection_options"`
+	Tiled             bool                `json:"tiled"`
 }
 
 func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, error) {
@@ -34,6 +72,13 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		arg.Status,
 		arg.S3Key,
 		arg.UploadUrl,
+		arg.ContentSha256,
+		arg.ParamsFingerprint,
+		arg.SourceJobID,
+		arg.ImportID,
+		arg.ModelVersion,
+		arg.DetectionOptions,
+		arg.Tiled,
 	)
 	var i Job
 	err := row.Scan(
@@ -47,6 +92,14 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.RequeueCount,
+		&i.ContentSha256,
+		&i.ParamsFingerprint,
+		&i.SourceJobID,
+		&i.ImportID,
+		&i.ModelVersion,
+		&i.DetectionOptions,
+		&i.Tiled,
 	)
 	return i, err
 }
@@ -55,16 +108,90 @@ const deleteJob = `-- name: DeleteJob :exec
 DELETE FROM jobs WHERE id = $1
 `
 
-func (q *Queries) DeleteJob(ctx context.Context, id int64) error {
+func (q *Queries) DeleteJob(ctx context.Context, id uuid.UUID) error {
 	_, err := q.exec(ctx, q.deleteJobStmt, deleteJob, id)
 	return err
 }
 
+const getCachedJob = `-- name: GetCachedJob :one
+SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled FROM jobs
+WHERE content_sha256 = $1 AND params_fingerprint = $2 AND model_version = $3 AND status = 'completed'
+  AND source_job_id IS NULL
+ORDER BY completed_at DESC
+LIMIT 1
+`
+
+type GetCachedJobParams struct {
+	ContentSha256     sql.NullString `json:"content_sha256"`
+	ParamsFingerprint sql.NullString `json:"params_fingerprint"`
+	ModelVersion      sql.NullString `json:"model_version"`
+}
+
+// Only jobs served by the requested model are reused, a worker may run another
+// model than the job asked for. Copies always point at the job that ran detection.
+func (q *Queries) GetCachedJob(ctx context.Context, arg GetCachedJobParams) (Job, error) {
+	row := q.queryRow(ctx, q.getCachedJobStmt, getCachedJob, arg.ContentSha256, arg.ParamsFingerprint, arg.ModelVersion)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.S3Key,
+		&i.UploadUrl,
+		&i.ResultUrl,
+		&i.LogosFound,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.RequeueCount,
+		&i.ContentSha256,
+		&i.ParamsFingerprint,
+		&i.SourceJobID,
+		&i.ImportID,
+		&i.ModelVersion,
+		&i.DetectionOptions,
+		&i.Tiled,
+	)
+	return i, err
+}
+
+const getImportedJob = `-- name: GetImportedJob :one
+SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled FROM jobs
+WHERE content_sha256 = $1 AND import_id IS NOT NULL
+LIMIT 1
+`
+
+func (q *Queries) GetImportedJob(ctx context.Context, contentSha256 sql.NullString) (Job, error) {
+	row := q.queryRow(ctx, q.getImportedJobStmt, getImportedJob, contentSha256)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.S3Key,
+		&i.UploadUrl,
+		&i.ResultUrl,
+		&i.LogosFound,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.RequeueCount,
+		&i.ContentSha256,
+		&i.ParamsFingerprint,
+		&i.SourceJobID,
+		&i.ImportID,
+		&i.ModelVersion,
+		&i.DetectionOptions,
+		&i.Tiled,
+	)
+	return i, err
+}
+
 const getJob = `-- name: GetJob :one
-SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at FROM jobs WHERE id = $1
+SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled FROM jobs WHERE id = $1
 `
 
-func (q *Queries) GetJob(ctx context.Context, id int64) (Job, error) {
+func (q *Queries) GetJob(ctx context.Context, id uuid.UUID) (Job, error) {
 	row := q.queryRow(ctx, q.getJobStmt, getJob, id)
 	var i Job
 	err := row.Scan(
@@ -78,12 +205,118 @@ func (q *Queries) GetJob(ctx context.Context, id int64) (Job, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.RequeueCount,
+		&i.ContentSha256,
+		&i.ParamsFingerprint,
+		&i.SourceJobID,
+		&i.ImportID,
+		&i.ModelVersion,
+		&i.DetectionOptions,
+		&i.Tiled,
+	)
+	return i, err
+}
+
+const getJobForUpdate = `-- name: GetJobForUpdate :one
+SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled FROM jobs WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetJobForUpdate(ctx context.Context, id uuid.UUID) (Job, error) {
+	row := q.queryRow(ctx, q.getJobForUpdateStmt, getJobForUpdate, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.S3Key,
+		&i.UploadUrl,
+		&i.ResultUrl,
+		&i.LogosFound,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.RequeueCount,
+		&i.ContentSha256,
+		&i.ParamsFingerprint,
+		&i.SourceJobID,
+		&i.ImportID,
+		&i.ModelVersion,
+		&i.DetectionOptions,
+		&i.Tiled,
 	)
 	return i, err
 }
 
+const listExpiredJobs = `-- name: ListExpiredJobs :many
+SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled FROM jobs
+WHERE status = $1
+  AND created_at < CASE WHEN import_id IS NULL THEN $2::timestamptz ELSE $3::timestamptz END
+  AND (created_at, id) > ($4::timestamptz, $5::uuid)
+ORDER BY created_at, id
+LIMIT $6::int
+`
+
+type ListExpiredJobsParams struct {
+	Status            string    `json:"status"`
+	CreatedAt         time.Time `json:"created_at"`
+	ImportedCreatedAt time.Time `json:"imported_created_at"`
+	AfterCreatedAt    time.Time `json:"after_created_at"`
+	AfterID           uuid.UUID `json:"after_id"`
+	ResultLimit       int32     `json:"result_limit"`
+}
+
+// Imported jobs have their own cutoff, so ground truth can be kept longer
+func (q *Queries) ListExpiredJobs(ctx context.Context, arg ListExpiredJobsParams) ([]Job, error) {
+	rows, err := q.query(ctx, q.listExpiredJobsStmt, listExpiredJobs,
+		arg.Status,
+		arg.CreatedAt,
+		arg.ImportedCreatedAt,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.ResultLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.S3Key,
+			&i.UploadUrl,
+			&i.ResultUrl,
+			&i.LogosFound,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.RequeueCount,
+			&i.ContentSha256,
+			&i.ParamsFingerprint,
+			&i.SourceJobID,
+			&i.ImportID,
+			&i.ModelVersion,
+			&i.DetectionOptions,
+			&i.Tiled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listJobs = `-- name: ListJobs :many
-SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at FROM jobs 
+SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled FROM jobs 
 ORDER BY created_at DESC 
 LIMIT $1 OFFSET $2
 `
@@ -113,6 +346,14 @@ func (q *Queries) ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, erro
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CompletedAt,
+			&i.RequeueCount,
+			&i.ContentSha256,
+			&i.ParamsFingerprint,
+			&i.SourceJobID,
+			&i.ImportID,
+			&i.ModelVersion,
+			&i.DetectionOptions,
+			&i.Tiled,
 		); err != nil {
 			return nil, err
 		}
@@ -127,94 +368,223 @@ func (q *Queries) ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, erro
 	return items, nil
 }
 
-const updateJobCompleted = `-- name: UpdateJobCompleted :one
-UPDATE jobs 
-SET status = $2, 
-    logos_found = $3,
-    result_url = $4,
-    completed_at = NOW(),
-    updated_at = NOW()
+const listJobsByIDs = `-- name: ListJobsByIDs :many
+SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled FROM jobs
+WHERE id = ANY($1::uuid[])
+ORDER BY created_at
+`
+
+func (q *Queries) ListJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]Job, error) {
+	rows, err := q.query(ctx, q.listJobsByIDsStmt, listJobsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.S3Key,
+			&i.UploadUrl,
+			&i.ResultUrl,
+			&i.LogosFound,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.RequeueCount,
+			&i.ContentSha256,
+			&i.ParamsFingerprint,
+			&i.SourceJobID,
+			&i.ImportID,
+			&i.ModelVersion,
+			&i.DetectionOptions,
+			&i.Tiled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobsByImport = `-- name: ListJobsByImport :many
+SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled FROM jobs
+WHERE import_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListJobsByImport(ctx context.Context, importID uuid.NullUUID) ([]Job, error) {
+	rows, err := q.query(ctx, q.listJobsByImportStmt, listJobsByImport, importID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.S3Key,
+			&i.UploadUrl,
+			&i.ResultUrl,
+			&i.LogosFound,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.RequeueCount,
+			&i.ContentSha256,
+			&i.ParamsFingerprint,
+			&i.SourceJobID,
+			&i.ImportID,
+			&i.ModelVersion,
+			&i.DetectionOptions,
+			&i.Tiled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStaleJobs = `-- name: ListStaleJobs :many
+SELECT id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled FROM jobs
+WHERE status = $1 AND updated_at < $2
+ORDER BY updated_at
+LIMIT $3
+`
+
+type ListStaleJobsParams struct {
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Limit     int32     `json:"limit"`
+}
+
+func (q *Queries) ListStaleJobs(ctx context.Context, arg ListStaleJobsParams) ([]Job, error) {
+	rows, err := q.query(ctx, q.listStaleJobsStmt, listStaleJobs, arg.Status, arg.UpdatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.S3Key,
+			&i.UploadUrl,
+			&i.ResultUrl,
+			&i.LogosFound,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.RequeueCount,
+			&i.ContentSha256,
+			&i.ParamsFingerprint,
+			&i.SourceJobID,
+			&i.ImportID,
+			&i.ModelVersion,
+			&i.DetectionOptions,
+			&i.Tiled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateJobModelVersion = `-- name: UpdateJobModelVersion :exec
+UPDATE jobs
+SET model_version = $2
 WHERE id = $1
-RETURNING id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at
 `
 
-type UpdateJobCompletedParams struct {
-	ID         int64          `json:"id"`
-	Status     string         `json:"status"`
-	LogosFound sql.NullString `json:"logos_found"`
-	ResultUrl  sql.NullString `json:"result_url"`
+type UpdateJobModelVersionParams struct {
+	ID           uuid.UUID      `json:"id"`
+	ModelVersion sql.NullString `json:"model_version"`
 }
 
-func (q *Queries) UpdateJobCompleted(ctx context.Context, arg UpdateJobCompletedParams) (Job, error) {
-	row := q.queryRow(ctx, q.updateJobCompletedStmt, updateJobCompleted,
-		arg.ID,
-		arg.Status,
-		arg.LogosFound,
-		arg.ResultUrl,
-	)
-	var i Job
-	err := row.Scan(
-		&i.ID,
-		&i.Status,
-		&i.S3Key,
-		&i.UploadUrl,
-		&i.ResultUrl,
-		&i.LogosFound,
-		&i.ErrorMessage,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.CompletedAt,
-	)
-	return i, err
+func (q *Queries) UpdateJobModelVersion(ctx context.Context, arg UpdateJobModelVersionParams) error {
+	_, err := q.exec(ctx, q.updateJobModelVersionStmt, updateJobModelVersion, arg.ID, arg.ModelVersion)
+	return err
 }
 
-const updateJobError = `-- name: UpdateJobError :one
-UPDATE jobs 
-SET status = $2, 
-    error_message = $3,
-    updated_at = NOW()
+const updateJobResultUrl = `-- name: UpdateJobResultUrl :exec
+UPDATE jobs
+SET result_url = $2
 WHERE id = $1
-RETURNING id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at
 `
 
-type UpdateJobErrorParams struct {
-	ID           int64          `json:"id"`
-	Status       string         `json:"status"`
-	ErrorMessage sql.NullString `json:"error_message"`
+type UpdateJobResultUrlParams struct {
+	ID        uuid.UUID      `json:"id"`
+	ResultUrl sql.NullString `json:"result_url"`
 }
 
-func (q *Queries) UpdateJobError(ctx context.Context, arg UpdateJobErrorParams) (Job, error) {
-	row := q.queryRow(ctx, q.updateJobErrorStmt, updateJobError, arg.ID, arg.Status, arg.ErrorMessage)
-	var i Job
-	err := row.Scan(
-		&i.ID,
-		&i.Status,
-		&i.S3Key,
-		&i.UploadUrl,
-		&i.ResultUrl,
-		&i.LogosFound,
-		&i.ErrorMessage,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.CompletedAt,
-	)
-	return i, err
+func (q *Queries) UpdateJobResultUrl(ctx context.Context, arg UpdateJobResultUrlParams) error {
+	_, err := q.exec(ctx, q.updateJobResultUrlStmt, updateJobResultUrl, arg.ID, arg.ResultUrl)
+	return err
 }
 
 const updateJobStatus = `-- name: UpdateJobStatus :one
-UPDATE jobs 
-SET status = $2, updated_at = NOW()
-WHERE id = $1
-RETURNING id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at
+UPDATE jobs
+SET status = $1,
+    error_message = CASE WHEN $1 IN ('pending', 'processing') THEN NULL ELSE COALESCE($2, error_message) END,
+    logos_found = COALESCE($3, logos_found),
+    result_url = COALESCE($4, result_url),
+    requeue_count = requeue_count + $5,
+    completed_at = CASE WHEN $1 = 'completed' THEN NOW() ELSE completed_at END,
+    updated_at = NOW()
+WHERE id = $6 AND status = ANY($7::varchar[])
+RETURNING id, status, s3_key, upload_url, result_url, logos_found, error_message, created_at, updated_at, completed_at, requeue_count, content_sha256, params_fingerprint, source_job_id, import_id, model_version, detection_options, tiled
 `
 
 type UpdateJobStatusParams struct {
-	ID     int64  `json:"id"`
-	Status string `json:"status"`
+	Status           string         `json:"status"`
+	ErrorMessage     sql.NullString `json:"error_message"`
+	LogosFound       sql.NullString `json:"logos_found"`
+	ResultUrl        sql.NullString `json:"result_url"`
+	RequeueIncrement int32          `json:"requeue_increment"`
+	ID               uuid.UUID      `json:"id"`
+	FromStatuses     []string       `json:"from_statuses"`
 }
 
+// A job that runs again drops the error of its earlier attempt
 func (q *Queries) UpdateJobStatus(ctx context.Context, arg UpdateJobStatusParams) (Job, error) {
-	row := q.queryRow(ctx, q.updateJobStatusStmt, updateJobStatus, arg.ID, arg.Status)
+	row := q.queryRow(ctx, q.updateJobStatusStmt, updateJobStatus,
+		arg.Status,
+		arg.ErrorMessage,
+		arg.LogosFound,
+		arg.ResultUrl,
+		arg.RequeueIncrement,
+		arg.ID,
+		pq.Array(arg.FromStatuses),
+	)
 	var i Job
 	err := row.Scan(
 		&i.ID,
@@ -227,6 +597,14 @@ func (q *Queries) UpdateJobStatus(ctx context.Context, arg UpdateJobStatusParams
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CompletedAt,
+		&i.RequeueCount,
+		&i.ContentSha256,
+		&i.ParamsFingerprint,
+		&i.SourceJobID,
+		&i.ImportID,
+		&i.ModelVersion,
+		&i.DetectionOptions,
+		&i.Tiled,
 	)
 	return i, err
 }
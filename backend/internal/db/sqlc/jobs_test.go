@@ -14,7 +14,7 @@ func TestCreateJob(t *testing.T) {
 
 	db := testQueries
 	job, err := db.CreateJob(context.Background(), CreateJobParams{
-		ID:        int64(uuid.New().ID()),
+		ID:        uuid.New(),
 		Status:    "pending",
 		S3Key:     uuid.New().String(),
 		UploadUrl: uuid.New().String()[:10],
@@ -7,7 +7,123 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/db"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const copyLogosToJob = `-- name: CopyLogosToJob :many
+INSERT INTO logos (
+    job_id,
+    bounding_box,
+    confidence,
+    logo_type,
+    s3_key,
+    brand_id,
+    brand_score,
+    review_status,
+    corners,
+    rectified_s3_key,
+    masked_s3_key,
+    mask_quality,
+    mask_source,
+    geometry,
+    palette,
+    vector_s3_key
 )
+SELECT $1::uuid, bounding_box, confidence, logo_type, s3_key, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key
+FROM logos
+WHERE logos.job_id = $2::uuid
+ORDER BY id
+RETURNING id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key
+`
+
+type CopyLogosToJobParams struct {
+	JobID       uuid.UUID `json:"job_id"`
+	SourceJobID uuid.UUID `json:"source_job_id"`
+}
+
+func (q *Queries) CopyLogosToJob(ctx context.Context, arg CopyLogosToJobParams) ([]Logo, error) {
+	rows, err := q.query(ctx, q.copyLogosToJobStmt, copyLogosToJob, arg.JobID, arg.SourceJobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Logo{}
+	for rows.Next() {
+		var i Logo
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.BoundingBox,
+			&i.Confidence,
+			&i.LogoType,
+			&i.S3Key,
+			&i.CreatedAt,
+			&i.BrandID,
+			&i.BrandScore,
+			&i.ReviewStatus,
+			&i.Corners,
+			&i.RectifiedS3Key,
+			&i.MaskedS3Key,
+			&i.MaskQuality,
+			&i.MaskSource,
+			&i.Geometry,
+			&i.Palette,
+			&i.VectorS3Key,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countLogosByReviewStatus = `-- name: CountLogosByReviewStatus :many
+SELECT review_status, COUNT(*) AS count FROM logos
+WHERE job_id = $1
+GROUP BY review_status
+ORDER BY review_status
+`
+
+type CountLogosByReviewStatusRow struct {
+	ReviewStatus string `json:"review_status"`
+	Count        int64  `json:"count"`
+}
+
+func (q *Queries) CountLogosByReviewStatus(ctx context.Context, jobID uuid.UUID) ([]CountLogosByReviewStatusRow, error) {
+	rows, err := q.query(ctx, q.countLogosByReviewStatusStmt, countLogosByReviewStatus, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountLogosByReviewStatusRow{}
+	for rows.Next() {
+		var i CountLogosByReviewStatusRow
+		if err := rows.Scan(
+			&i.ReviewStatus,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
 
 const createLogo = `-- name: CreateLogo :one
 INSERT INTO logos (
@@ -15,18 +131,20 @@ INSERT INTO logos (
     bounding_box,
     confidence,
     logo_type,
-    s3_key
+    s3_key,
+    geometry
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, job_id, bounding_box, confidence, logo_type, s3_key, created_at
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key
 `
 
 type CreateLogoParams struct {
-	JobID       int64  `json:"job_id"`
-	BoundingBox string `json:"bounding_box"`
-	Confidence  int64  `json:"confidence"`
-	LogoType    string `json:"logo_type"`
-	S3Key       string `json:"s3_key"`
+	JobID       uuid.UUID      `json:"job_id"`
+	BoundingBox db.BoundingBox `json:"bounding_box"`
+	Confidence  float32        `json:"confidence"`
+	LogoType    string         `json:"logo_type"`
+	S3Key       string         `json:"s3_key"`
+	Geometry    db.Geometry    `json:"geometry"`
 }
 
 func (q *Queries) CreateLogo(ctx context.Context, arg CreateLogoParams) (Logo, error) {
@@ -36,7 +154,157 @@ func (q *Queries) CreateLogo(ctx context.Context, arg CreateLogoParams) (Logo, e
 		arg.Confidence,
 		arg.LogoType,
 		arg.S3Key,
+		arg.Geometry,
+	)
+	var i Logo
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.BoundingBox,
+		&i.Confidence,
+		&i.LogoType,
+		&i.S3Key,
+		&i.CreatedAt,
+		&i.BrandID,
+		&i.BrandScore,
+		&i.ReviewStatus,
+		&i.Corners,
+		&i.RectifiedS3Key,
+		&i.MaskedS3Key,
+		&i.MaskQuality,
+		&i.MaskSource,
+		&i.Geometry,
+		&i.Palette,
+		&i.VectorS3Key,
+	)
+	return i, err
+}
+
+const deleteLogosByJobID = `-- name: DeleteLogosByJobID :exec
+DELETE FROM logos WHERE job_id = $1
+`
+
+func (q *Queries) DeleteLogosByJobID(ctx context.Context, jobID uuid.UUID) error {
+	_, err := q.exec(ctx, q.deleteLogosByJobIDStmt, deleteLogosByJobID, jobID)
+	return err
+}
+
+const filterLogos = `-- name: FilterLogos :many
+-- Area is width * height and aspect ratio is width / height of the bounding box
+SELECT id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key FROM logos
+WHERE ($1::uuid IS NULL OR job_id = $1::uuid)
+  AND ($2::varchar IS NULL OR logo_type = $2)
+  AND ($3::float8 IS NULL OR confidence >= $3)
+  AND ($4::int8 IS NULL OR (bounding_box->>'width')::int8 * (bounding_box->>'height')::int8 >= $4)
+  AND ($5::int8 IS NULL OR (bounding_box->>'width')::int8 * (bounding_box->>'height')::int8 <= $5)
+  AND ($6::float8 IS NULL OR (bounding_box->>'width')::float8 / NULLIF((bounding_box->>'height')::float8, 0) >= $6)
+  AND ($7::float8 IS NULL OR (bounding_box->>'width')::float8 / NULLIF((bounding_box->>'height')::float8, 0) <= $7)
+ORDER BY confidence DESC, id
+LIMIT $8::int
+OFFSET $9::int
+`
+
+type FilterLogosParams struct {
+	JobID          uuid.NullUUID   `json:"job_id"`
+	LogoType       sql.NullString  `json:"logo_type"`
+	MinConfidence  sql.NullFloat64 `json:"min_confidence"`
+	MinArea        sql.NullInt64   `json:"min_area"`
+	MaxArea        sql.NullInt64   `json:"max_area"`
+	MinAspectRatio sql.NullFloat64 `json:"min_aspect_ratio"`
+	MaxAspectRatio sql.NullFloat64 `json:"max_aspect_ratio"`
+	ResultLimit    int32           `json:"result_limit"`
+	ResultOffset   int32           `json:"result_offset"`
+}
+
+// Area is width * height and aspect ratio is width / height of the bounding box
+func (q *Queries) FilterLogos(ctx context.Context, arg FilterLogosParams) ([]Logo, error) {
+	rows, err := q.query(ctx, q.filterLogosStmt, filterLogos,
+		arg.JobID,
+		arg.LogoType,
+		arg.MinConfidence,
+		arg.MinArea,
+		arg.MaxArea,
+		arg.MinAspectRatio,
+		arg.MaxAspectRatio,
+		arg.ResultLimit,
+		arg.ResultOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Logo{}
+	for rows.Next() {
+		var i Logo
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.BoundingBox,
+			&i.Confidence,
+			&i.LogoType,
+			&i.S3Key,
+			&i.CreatedAt,
+			&i.BrandID,
+			&i.BrandScore,
+			&i.ReviewStatus,
+			&i.Corners,
+			&i.RectifiedS3Key,
+			&i.MaskedS3Key,
+			&i.MaskQuality,
+			&i.MaskSource,
+			&i.Geometry,
+			&i.Palette,
+			&i.VectorS3Key,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLogo = `-- name: GetLogo :one
+SELECT id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key FROM logos WHERE id = $1
+`
+
+func (q *Queries) GetLogo(ctx context.Context, id int64) (Logo, error) {
+	row := q.queryRow(ctx, q.getLogoStmt, getLogo, id)
+	var i Logo
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.BoundingBox,
+		&i.Confidence,
+		&i.LogoType,
+		&i.S3Key,
+		&i.CreatedAt,
+		&i.BrandID,
+		&i.BrandScore,
+		&i.ReviewStatus,
+		&i.Corners,
+		&i.RectifiedS3Key,
+		&i.MaskedS3Key,
+		&i.MaskQuality,
+		&i.MaskSource,
+		&i.Geometry,
+		&i.Palette,
+		&i.VectorS3Key,
 	)
+	return i, err
+}
+
+const getLogoForUpdate = `-- name: GetLogoForUpdate :one
+SELECT id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key FROM logos WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetLogoForUpdate(ctx context.Context, id int64) (Logo, error) {
+	row := q.queryRow(ctx, q.getLogoForUpdateStmt, getLogoForUpdate, id)
 	var i Logo
 	err := row.Scan(
 		&i.ID,
@@ -46,15 +314,26 @@ func (q *Queries) CreateLogo(ctx context.Context, arg CreateLogoParams) (Logo, e
 		&i.LogoType,
 		&i.S3Key,
 		&i.CreatedAt,
+		&i.BrandID,
+		&i.BrandScore,
+		&i.ReviewStatus,
+		&i.Corners,
+		&i.RectifiedS3Key,
+		&i.MaskedS3Key,
+		&i.MaskQuality,
+		&i.MaskSource,
+		&i.Geometry,
+		&i.Palette,
+		&i.VectorS3Key,
 	)
 	return i, err
 }
 
 const getLogosByJobID = `-- name: GetLogosByJobID :many
-SELECT id, job_id, bounding_box, confidence, logo_type, s3_key, created_at FROM logos WHERE job_id = $1 ORDER BY confidence DESC
+SELECT id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key FROM logos WHERE job_id = $1 ORDER BY confidence DESC
 `
 
-func (q *Queries) GetLogosByJobID(ctx context.Context, jobID int64) ([]Logo, error) {
+func (q *Queries) GetLogosByJobID(ctx context.Context, jobID uuid.UUID) ([]Logo, error) {
 	rows, err := q.query(ctx, q.getLogosByJobIDStmt, getLogosByJobID, jobID)
 	if err != nil {
 		return nil, err
@@ -71,6 +350,174 @@ func (q *Queries) GetLogosByJobID(ctx context.Context, jobID int64) ([]Logo, err
 			&i.LogoType,
 			&i.S3Key,
 			&i.CreatedAt,
+			&i.BrandID,
+			&i.BrandScore,
+			&i.ReviewStatus,
+			&i.Corners,
+			&i.RectifiedS3Key,
+			&i.MaskedS3Key,
+			&i.MaskQuality,
+			&i.MaskSource,
+			&i.Geometry,
+			&i.Palette,
+			&i.VectorS3Key,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSourceLogo = `-- name: GetSourceLogo :one
+SELECT source.id, source.job_id, source.bounding_box, source.confidence, source.logo_type, source.s3_key, source.created_at, source.brand_id, source.brand_score, source.review_status, source.corners, source.rectified_s3_key, source.masked_s3_key, source.mask_quality, source.mask_source, source.geometry, source.palette, source.vector_s3_key FROM logos source
+JOIN jobs ON jobs.source_job_id = source.job_id
+WHERE jobs.id = $1 AND source.s3_key = $2
+LIMIT 1
+`
+
+type GetSourceLogoParams struct {
+	JobID uuid.UUID `json:"job_id"`
+	S3Key string    `json:"s3_key"`
+}
+
+// The logo a cached copy was made from, the two share their crop
+func (q *Queries) GetSourceLogo(ctx context.Context, arg GetSourceLogoParams) (Logo, error) {
+	row := q.queryRow(ctx, q.getSourceLogoStmt, getSourceLogo, arg.JobID, arg.S3Key)
+	var i Logo
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.BoundingBox,
+		&i.Confidence,
+		&i.LogoType,
+		&i.S3Key,
+		&i.CreatedAt,
+		&i.BrandID,
+		&i.BrandScore,
+		&i.ReviewStatus,
+		&i.Corners,
+		&i.RectifiedS3Key,
+		&i.MaskedS3Key,
+		&i.MaskQuality,
+		&i.MaskSource,
+		&i.Geometry,
+		&i.Palette,
+		&i.VectorS3Key,
+	)
+	return i, err
+}
+
+const listDetectionsByJobIDs = `-- name: ListDetectionsByJobIDs :many
+SELECT logos.id, logos.job_id, logos.confidence,
+    COALESCE(first_review.previous_logo_type, logos.logo_type)::varchar AS logo_type,
+    COALESCE(first_review.previous_bounding_box, logos.bounding_box)::jsonb AS bounding_box
+FROM logos
+JOIN jobs ON jobs.id = logos.job_id
+LEFT JOIN LATERAL (
+    SELECT logo_reviews.previous_logo_type, logo_reviews.previous_bounding_box FROM logo_reviews
+    JOIN logos origin ON origin.id = logo_reviews.logo_id
+    WHERE origin.id = logos.id
+       OR (origin.job_id = jobs.source_job_id AND origin.s3_key = logos.s3_key)
+    ORDER BY logo_reviews.created_at, logo_reviews.id
+    LIMIT 1
+) first_review ON TRUE
+WHERE logos.job_id = ANY($1::uuid[])
+ORDER BY logos.job_id, logos.id
+`
+
+type ListDetectionsByJobIDsRow struct {
+	ID          int64          `json:"id"`
+	JobID       uuid.UUID      `json:"job_id"`
+	Confidence  float32        `json:"confidence"`
+	LogoType    string         `json:"logo_type"`
+	BoundingBox db.BoundingBox `json:"bounding_box"`
+}
+
+// The logos of jobs as the model detected them. Reviews overwrite the logo's type and
+// box, so reviewed logos take the values kept by their first review. Copies in cached
+// jobs are reviewed through their source logo and take the values of its first review.
+func (q *Queries) ListDetectionsByJobIDs(ctx context.Context, jobIds []uuid.UUID) ([]ListDetectionsByJobIDsRow, error) {
+	rows, err := q.query(ctx, q.listDetectionsByJobIDsStmt, listDetectionsByJobIDs, pq.Array(jobIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDetectionsByJobIDsRow{}
+	for rows.Next() {
+		var i ListDetectionsByJobIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Confidence,
+			&i.LogoType,
+			&i.BoundingBox,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLogosForReview = `-- name: ListLogosForReview :many
+SELECT logos.id, logos.job_id, logos.bounding_box, logos.confidence, logos.logo_type, logos.s3_key, logos.created_at, logos.brand_id, logos.brand_score, logos.review_status, logos.corners, logos.rectified_s3_key, logos.masked_s3_key, logos.mask_quality, logos.mask_source, logos.geometry, logos.palette, logos.vector_s3_key FROM logos
+JOIN jobs ON jobs.id = logos.job_id
+WHERE logos.review_status = 'pending'
+  AND jobs.source_job_id IS NULL
+  AND ($1::uuid IS NULL OR logos.job_id = $1::uuid)
+ORDER BY logos.confidence, logos.id
+LIMIT $2::int
+OFFSET $3::int
+`
+
+type ListLogosForReviewParams struct {
+	JobID        uuid.NullUUID `json:"job_id"`
+	ResultLimit  int32         `json:"result_limit"`
+	ResultOffset int32         `json:"result_offset"`
+}
+
+// Logos copied into cached jobs are left out, they are reviewed through their source job
+func (q *Queries) ListLogosForReview(ctx context.Context, arg ListLogosForReviewParams) ([]Logo, error) {
+	rows, err := q.query(ctx, q.listLogosForReviewStmt, listLogosForReview, arg.JobID, arg.ResultLimit, arg.ResultOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Logo{}
+	for rows.Next() {
+		var i Logo
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.BoundingBox,
+			&i.Confidence,
+			&i.LogoType,
+			&i.S3Key,
+			&i.CreatedAt,
+			&i.BrandID,
+			&i.BrandScore,
+			&i.ReviewStatus,
+			&i.Corners,
+			&i.RectifiedS3Key,
+			&i.MaskedS3Key,
+			&i.MaskQuality,
+			&i.MaskSource,
+			&i.Geometry,
+			&i.Palette,
+			&i.VectorS3Key,
 		); err != nil {
 			return nil, err
 		}
@@ -84,3 +531,341 @@ func (q *Queries) GetLogosByJobID(ctx context.Context, jobID int64) ([]Logo, err
 	}
 	return items, nil
 }
+
+const listReferencedLogoKeys = `-- name: ListReferencedLogoKeys :many
+SELECT s3_key FROM logos
+WHERE s3_key = ANY($1::varchar[])
+UNION
+SELECT rectified_s3_key FROM logos
+WHERE rectified_s3_key = ANY($1::varchar[])
+UNION
+SELECT masked_s3_key FROM logos
+WHERE masked_s3_key = ANY($1::varchar[])
+UNION
+SELECT vector_s3_key FROM logos
+WHERE vector_s3_key = ANY($1::varchar[])
+UNION
+SELECT result_url::varchar FROM jobs
+WHERE result_url = ANY($1::varchar[])
+`
+
+// Rectified, masked and vectorized crops and overlays are shared with cached jobs too
+func (q *Queries) ListReferencedLogoKeys(ctx context.Context, keys []string) ([]string, error) {
+	rows, err := q.query(ctx, q.listReferencedLogoKeysStmt, listReferencedLogoKeys, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var s3_key string
+		if err := rows.Scan(&s3_key); err != nil {
+			return nil, err
+		}
+		items = append(items, s3_key)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCopiedLogosReview = `-- name: UpdateCopiedLogosReview :many
+UPDATE logos
+SET review_status = $1,
+    logo_type = $2,
+    bounding_box = $3
+FROM jobs
+WHERE jobs.id = logos.job_id
+  AND jobs.source_job_id = $4::uuid
+  AND logos.s3_key = $5
+RETURNING logos.id, logos.job_id, logos.bounding_box, logos.confidence, logos.logo_type, logos.s3_key, logos.created_at, logos.brand_id, logos.brand_score, logos.review_status, logos.corners, logos.rectified_s3_key, logos.masked_s3_key, logos.mask_quality, logos.mask_source, logos.geometry, logos.palette, logos.vector_s3_key
+`
+
+type UpdateCopiedLogosReviewParams struct {
+	ReviewStatus string         `json:"review_status"`
+	LogoType     string         `json:"logo_type"`
+	BoundingBox  db.BoundingBox `json:"bounding_box"`
+	SourceJobID  uuid.UUID      `json:"source_job_id"`
+	S3Key        string         `json:"s3_key"`
+}
+
+// Copies of a logo in cached jobs take every review decision made on it
+func (q *Queries) UpdateCopiedLogosReview(ctx context.Context, arg UpdateCopiedLogosReviewParams) ([]Logo, error) {
+	rows, err := q.query(ctx, q.updateCopiedLogosReviewStmt, updateCopiedLogosReview,
+		arg.ReviewStatus,
+		arg.LogoType,
+		arg.BoundingBox,
+		arg.SourceJobID,
+		arg.S3Key,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Logo{}
+	for rows.Next() {
+		var i Logo
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.BoundingBox,
+			&i.Confidence,
+			&i.LogoType,
+			&i.S3Key,
+			&i.CreatedAt,
+			&i.BrandID,
+			&i.BrandScore,
+			&i.ReviewStatus,
+			&i.Corners,
+			&i.RectifiedS3Key,
+			&i.MaskedS3Key,
+			&i.MaskQuality,
+			&i.MaskSource,
+			&i.Geometry,
+			&i.Palette,
+			&i.VectorS3Key,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateLogoBrand = `-- name: UpdateLogoBrand :exec
+UPDATE logos
+SET brand_id = $2,
+    brand_score = $3
+WHERE id = $1
+`
+
+type UpdateLogoBrandParams struct {
+	ID         int64           `json:"id"`
+	BrandID    sql.NullInt64   `json:"brand_id"`
+	BrandScore sql.NullFloat64 `json:"brand_score"`
+}
+
+func (q *Queries) UpdateLogoBrand(ctx context.Context, arg UpdateLogoBrandParams) error {
+	_, err := q.exec(ctx, q.updateLogoBrandStmt, updateLogoBrand, arg.ID, arg.BrandID, arg.BrandScore)
+	return err
+}
+
+const updateLogoMask = `-- name: UpdateLogoMask :one
+UPDATE logos
+SET masked_s3_key = $2,
+    mask_quality = $3,
+    mask_source = $4
+WHERE id = $1
+RETURNING id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key
+`
+
+type UpdateLogoMaskParams struct {
+	ID          int64           `json:"id"`
+	MaskedS3Key sql.NullString  `json:"masked_s3_key"`
+	MaskQuality sql.NullFloat64 `json:"mask_quality"`
+	MaskSource  sql.NullString  `json:"mask_source"`
+}
+
+func (q *Queries) UpdateLogoMask(ctx context.Context, arg UpdateLogoMaskParams) (Logo, error) {
+	row := q.queryRow(ctx, q.updateLogoMaskStmt, updateLogoMask,
+		arg.ID,
+		arg.MaskedS3Key,
+		arg.MaskQuality,
+		arg.MaskSource,
+	)
+	var i Logo
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.BoundingBox,
+		&i.Confidence,
+		&i.LogoType,
+		&i.S3Key,
+		&i.CreatedAt,
+		&i.BrandID,
+		&i.BrandScore,
+		&i.ReviewStatus,
+		&i.Corners,
+		&i.RectifiedS3Key,
+		&i.MaskedS3Key,
+		&i.MaskQuality,
+		&i.MaskSource,
+		&i.Geometry,
+		&i.Palette,
+		&i.VectorS3Key,
+	)
+	return i, err
+}
+
+const updateLogoPalette = `-- name: UpdateLogoPalette :one
+UPDATE logos
+SET palette = $2
+WHERE id = $1
+RETURNING id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key
+`
+
+type UpdateLogoPaletteParams struct {
+	ID      int64      `json:"id"`
+	Palette db.Palette `json:"palette"`
+}
+
+func (q *Queries) UpdateLogoPalette(ctx context.Context, arg UpdateLogoPaletteParams) (Logo, error) {
+	row := q.queryRow(ctx, q.updateLogoPaletteStmt, updateLogoPalette, arg.ID, arg.Palette)
+	var i Logo
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.BoundingBox,
+		&i.Confidence,
+		&i.LogoType,
+		&i.S3Key,
+		&i.CreatedAt,
+		&i.BrandID,
+		&i.BrandScore,
+		&i.ReviewStatus,
+		&i.Corners,
+		&i.RectifiedS3Key,
+		&i.MaskedS3Key,
+		&i.MaskQuality,
+		&i.MaskSource,
+		&i.Geometry,
+		&i.Palette,
+		&i.VectorS3Key,
+	)
+	return i, err
+}
+
+const updateLogoRectification = `-- name: UpdateLogoRectification :one
+UPDATE logos
+SET corners = $2,
+    rectified_s3_key = $3
+WHERE id = $1
+RETURNING id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key
+`
+
+type UpdateLogoRectificationParams struct {
+	ID             int64          `json:"id"`
+	Corners        sql.NullString `json:"corners"`
+	RectifiedS3Key sql.NullString `json:"rectified_s3_key"`
+}
+
+func (q *Queries) UpdateLogoRectification(ctx context.Context, arg UpdateLogoRectificationParams) (Logo, error) {
+	row := q.queryRow(ctx, q.updateLogoRectificationStmt, updateLogoRectification, arg.ID, arg.Corners, arg.RectifiedS3Key)
+	var i Logo
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.BoundingBox,
+		&i.Confidence,
+		&i.LogoType,
+		&i.S3Key,
+		&i.CreatedAt,
+		&i.BrandID,
+		&i.BrandScore,
+		&i.ReviewStatus,
+		&i.Corners,
+		&i.RectifiedS3Key,
+		&i.MaskedS3Key,
+		&i.MaskQuality,
+		&i.MaskSource,
+		&i.Geometry,
+		&i.Palette,
+		&i.VectorS3Key,
+	)
+	return i, err
+}
+
+const updateLogoReview = `-- name: UpdateLogoReview :one
+UPDATE logos
+SET review_status = $2,
+    logo_type = $3,
+    bounding_box = $4
+WHERE id = $1
+RETURNING id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key
+`
+
+type UpdateLogoReviewParams struct {
+	ID           int64          `json:"id"`
+	ReviewStatus string         `json:"review_status"`
+	LogoType     string         `json:"logo_type"`
+	BoundingBox  db.BoundingBox `json:"bounding_box"`
+}
+
+func (q *Queries) UpdateLogoReview(ctx context.Context, arg UpdateLogoReviewParams) (Logo, error) {
+	row := q.queryRow(ctx, q.updateLogoReviewStmt, updateLogoReview,
+		arg.ID,
+		arg.ReviewStatus,
+		arg.LogoType,
+		arg.BoundingBox,
+	)
+	var i Logo
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.BoundingBox,
+		&i.Confidence,
+		&i.LogoType,
+		&i.S3Key,
+		&i.CreatedAt,
+		&i.BrandID,
+		&i.BrandScore,
+		&i.ReviewStatus,
+		&i.Corners,
+		&i.RectifiedS3Key,
+		&i.MaskedS3Key,
+		&i.MaskQuality,
+		&i.MaskSource,
+		&i.Geometry,
+		&i.Palette,
+		&i.VectorS3Key,
+	)
+	return i, err
+}
+
+const updateLogoVector = `-- name: UpdateLogoVector :one
+UPDATE logos
+SET vector_s3_key = $2
+WHERE id = $1
+RETURNING id, job_id, bounding_box, confidence, logo_type, s3_key, created_at, brand_id, brand_score, review_status, corners, rectified_s3_key, masked_s3_key, mask_quality, mask_source, geometry, palette, vector_s3_key
+`
+
+type UpdateLogoVectorParams struct {
+	ID          int64          `json:"id"`
+	VectorS3Key sql.NullString `json:"vector_s3_key"`
+}
+
+func (q *Queries) UpdateLogoVector(ctx context.Context, arg UpdateLogoVectorParams) (Logo, error) {
+	row := q.queryRow(ctx, q.updateLogoVectorStmt, updateLogoVector, arg.ID, arg.VectorS3Key)
+	var i Logo
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.BoundingBox,
+		&i.Confidence,
+		&i.LogoType,
+		&i.S3Key,
+		&i.CreatedAt,
+		&i.BrandID,
+		&i.BrandScore,
+		&i.ReviewStatus,
+		&i.Corners,
+		&i.RectifiedS3Key,
+		&i.MaskedS3Key,
+		&i.MaskQuality,
+		&i.MaskSource,
+		&i.Geometry,
+		&i.Palette,
+		&i.VectorS3Key,
+	)
+	return i, err
+}
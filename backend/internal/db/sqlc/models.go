@@ -6,28 +6,177 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/db"
+	"github.com/google/uuid"
 )
 
-type Job struct {
-	ID           int64          `json:"id"`
-	Status       string         `json:"status"`
-	S3Key        string         `json:"s3_key"`
-	UploadUrl    string         `json:"upload_url"`
-	ResultUrl    sql.NullString `json:"result_url"`
-	LogosFound   sql.NullString `json:"logos_found"`
-	ErrorMessage sql.NullString `json:"error_message"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	CompletedAt  time.Time      `json:"completed_at"`
+type Brand struct {
+	ID          int64          `json:"id"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
 }
 
-type Logo struct {
+type BrandReference struct {
+	ID             int64     `json:"id"`
+	BrandID        int64     `json:"brand_id"`
+	S3Key          string    `json:"s3_key"`
+	Dhash          int64     `json:"dhash"`
+	Phash          int64     `json:"phash"`
+	ColorHistogram []float64 `json:"color_histogram"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type Composition struct {
+	ID           uuid.UUID       `json:"id"`
+	Status       string          `json:"status"`
+	TargetKey    string          `json:"target_key"`
+	Placements   json.RawMessage `json:"placements"`
+	S3Key        sql.NullString  `json:"s3_key"`
+	ErrorMessage sql.NullString  `json:"error_message"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	CompletedAt  sql.NullTime    `json:"completed_at"`
+}
+
+type DatasetExport struct {
+	ID               uuid.UUID       `json:"id"`
+	Status           string          `json:"status"`
+	Format           string          `json:"format"`
+	Filters          json.RawMessage `json:"filters"`
+	ValFraction      float64         `json:"val_fraction"`
+	S3Key            sql.NullString  `json:"s3_key"`
+	ImagesCount      int32           `json:"images_count"`
+	AnnotationsCount int32           `json:"annotations_count"`
+	ErrorMessage     sql.NullString  `json:"error_message"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	CompletedAt      sql.NullTime    `json:"completed_at"`
+}
+
+type DatasetImport struct {
+	ID               uuid.UUID      `json:"id"`
+	Status           string         `json:"status"`
+	Format           string         `json:"format"`
+	Name             string         `json:"name"`
+	S3Key            sql.NullString `json:"s3_key"`
+	ImagesCount      int32          `json:"images_count"`
+	AnnotationsCount int32          `json:"annotations_count"`
+	SkippedCount     int32          `json:"skipped_count"`
+	ErrorMessage     sql.NullString `json:"error_message"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	CompletedAt      sql.NullTime   `json:"completed_at"`
+	// RequeuedCount are images of failed jobs an earlier run imported, queued again
+	RequeuedCount int32 `json:"requeued_count"`
+	// InvalidAnnotationsCount are labels skipped because their box is outside the image
+	InvalidAnnotationsCount int32 `json:"invalid_annotations_count"`
+}
+
+type GroundTruthAnnotation struct {
 	ID          int64     `json:"id"`
-	JobID       int64     `json:"job_id"`
-	BoundingBox string    `json:"bounding_box"`
-	Confidence  int64     `json:"confidence"`
+	JobID       uuid.UUID `json:"job_id"`
 	LogoType    string    `json:"logo_type"`
-	S3Key       string    `json:"s3_key"`
+	BoundingBox string    `json:"bounding_box"`
 	CreatedAt   time.Time `json:"created_at"`
 }
+
+type Job struct {
+	ID                uuid.UUID           `json:"id"`
+	Status            string              `json:"status"`
+	S3Key             string              `json:"s3_key"`
+	UploadUrl         string              `json:"upload_url"`
+	ResultUrl         sql.NullString      `json:"result_url"`
+	LogosFound        sql.NullString      `json:"logos_found"`
+	ErrorMessage      sql.NullString      `json:"error_message"`
+	CreatedAt         time.Time           `json:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+	CompletedAt       time.Time           `json:"completed_at"`
+	RequeueCount      int32               `json:"requeue_count"`
+	ContentSha256     sql.NullString      `json:"content_sha256"`
+	ParamsFingerprint sql.NullString      `json:"params_fingerprint"`
+	SourceJobID       uuid.NullUUID       `json:"source_job_id"`
+	ImportID          uuid.NullUUID       `json:"import_id"`
+	ModelVersion      sql.NullString      `json:"model_version"`
+	DetectionOptions  db.DetectionOptions `json:"detection_options"`
+	// Tiled jobs are split into tiles before detection
+	Tiled bool `json:"tiled"`
+}
+
+type JobEvent struct {
+	ID         int64          `json:"id"`
+	JobID      uuid.UUID      `json:"job_id"`
+	FromStatus sql.NullString `json:"from_status"`
+	ToStatus   string         `json:"to_status"`
+	Actor      string         `json:"actor"`
+	Reason     sql.NullString `json:"reason"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type JobTile struct {
+	ID           uuid.UUID       `json:"id"`
+	JobID        uuid.UUID       `json:"job_id"`
+	TileIndex    int32           `json:"tile_index"`
+	X            int32           `json:"x"`
+	Y            int32           `json:"y"`
+	Width        int32           `json:"width"`
+	Height       int32           `json:"height"`
+	S3Key        string          `json:"s3_key"`
+	Status       string          `json:"status"`
+	Detections   json.RawMessage `json:"detections"`
+	ModelVersion sql.NullString  `json:"model_version"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+type Logo struct {
+	ID           int64           `json:"id"`
+	JobID        uuid.UUID       `json:"job_id"`
+	BoundingBox  db.BoundingBox  `json:"bounding_box"`
+	Confidence   float32         `json:"confidence"`
+	LogoType     string          `json:"logo_type"`
+	S3Key        string          `json:"s3_key"`
+	CreatedAt    time.Time       `json:"created_at"`
+	BrandID      sql.NullInt64   `json:"brand_id"`
+	BrandScore   sql.NullFloat64 `json:"brand_score"`
+	ReviewStatus string          `json:"review_status"`
+	// Corners is a JSON array of the four corners of the logo in the original image
+	Corners        sql.NullString `json:"corners"`
+	RectifiedS3Key sql.NullString `json:"rectified_s3_key"`
+	// MaskedS3Key is the crop with a transparent background
+	MaskedS3Key sql.NullString  `json:"masked_s3_key"`
+	MaskQuality sql.NullFloat64 `json:"mask_quality"`
+	MaskSource  sql.NullString  `json:"mask_source"`
+	// Geometry holds polygons, a rotated box and an RLE mask when the detector provides them
+	Geometry db.Geometry `json:"geometry"`
+	// Palette is the logo's dominant colors, most common first
+	Palette db.Palette `json:"palette"`
+	// VectorS3Key is the SVG tracing of the logo
+	VectorS3Key sql.NullString `json:"vector_s3_key"`
+}
+
+type LogoHash struct {
+	LogoID     int64     `json:"logo_id"`
+	Dhash      int64     `json:"dhash"`
+	Phash      int64     `json:"phash"`
+	CreatedAt  time.Time `json:"created_at"`
+	DhashBands []int32   `json:"dhash_bands"`
+	PhashBands []int32   `json:"phash_bands"`
+}
+
+type LogoReview struct {
+	ID                  int64          `json:"id"`
+	LogoID              int64          `json:"logo_id"`
+	Reviewer            string         `json:"reviewer"`
+	Decision            string         `json:"decision"`
+	PreviousLogoType    string         `json:"previous_logo_type"`
+	LogoType            string         `json:"logo_type"`
+	PreviousBoundingBox db.BoundingBox `json:"previous_bounding_box"`
+	BoundingBox         db.BoundingBox `json:"bounding_box"`
+	Comment             sql.NullString `json:"comment"`
+	CreatedAt           time.Time      `json:"created_at"`
+}
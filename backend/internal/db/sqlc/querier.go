@@ -6,18 +6,109 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	// A composition left processing by a crashed consumer is redelivered and claimed again
+	ClaimComposition(ctx context.Context, id uuid.UUID) (Composition, error)
+	ClaimDatasetExport(ctx context.Context, updatedAt time.Time) (DatasetExport, error)
+	ClaimDatasetImport(ctx context.Context, updatedAt time.Time) (DatasetImport, error)
+	CompleteComposition(ctx context.Context, arg CompleteCompositionParams) (Composition, error)
+	CompleteDatasetExport(ctx context.Context, arg CompleteDatasetExportParams) (DatasetExport, error)
+	CompleteDatasetImport(ctx context.Context, arg CompleteDatasetImportParams) (DatasetImport, error)
+	CopyLogosToJob(ctx context.Context, arg CopyLogosToJobParams) ([]Logo, error)
+	CountExpiredJobs(ctx context.Context, arg CountExpiredJobsParams) (int64, error)
+	CountLogosByReviewStatus(ctx context.Context, jobID uuid.UUID) ([]CountLogosByReviewStatusRow, error)
+	CreateBrand(ctx context.Context, arg CreateBrandParams) (Brand, error)
+	CreateBrandReference(ctx context.Context, arg CreateBrandReferenceParams) (BrandReference, error)
+	CreateComposition(ctx context.Context, arg CreateCompositionParams) (Composition, error)
+	CreateDatasetExport(ctx context.Context, arg CreateDatasetExportParams) (DatasetExport, error)
+	CreateDatasetImport(ctx context.Context, arg CreateDatasetImportParams) (DatasetImport, error)
+	CreateGroundTruthAnnotation(ctx context.Context, arg CreateGroundTruthAnnotationParams) (GroundTruthAnnotation, error)
 	CreateJob(ctx context.Context, arg CreateJobParams) (Job, error)
+	CreateJobEvent(ctx context.Context, arg CreateJobEventParams) (JobEvent, error)
+	CreateJobTile(ctx context.Context, arg CreateJobTileParams) (JobTile, error)
 	CreateLogo(ctx context.Context, arg CreateLogoParams) (Logo, error)
-	DeleteJob(ctx context.Context, id int64) error
-	GetJob(ctx context.Context, id int64) (Job, error)
-	GetLogosByJobID(ctx context.Context, jobID int64) ([]Logo, error)
+	CreateLogoReview(ctx context.Context, arg CreateLogoReviewParams) (LogoReview, error)
+	DeleteBrand(ctx context.Context, id int64) error
+	DeleteBrandReference(ctx context.Context, id int64) error
+	DeleteJob(ctx context.Context, id uuid.UUID) error
+	DeleteLogosByJobID(ctx context.Context, jobID uuid.UUID) error
+	FailComposition(ctx context.Context, arg FailCompositionParams) (Composition, error)
+	FailDatasetExport(ctx context.Context, arg FailDatasetExportParams) (DatasetExport, error)
+	FailDatasetImport(ctx context.Context, arg FailDatasetImportParams) (DatasetImport, error)
+	// Area is width * height and aspect ratio is width / height of the bounding box
+	FilterLogos(ctx context.Context, arg FilterLogosParams) ([]Logo, error)
+	GetBrand(ctx context.Context, id int64) (Brand, error)
+	GetBrandReference(ctx context.Context, id int64) (BrandReference, error)
+	// Only jobs served by the requested model are reused, a worker may run another
+	// model than the job asked for. Copies always point at the job that ran detection.
+	GetCachedJob(ctx context.Context, arg GetCachedJobParams) (Job, error)
+	GetComposition(ctx context.Context, id uuid.UUID) (Composition, error)
+	GetDatasetExport(ctx context.Context, id uuid.UUID) (DatasetExport, error)
+	GetDatasetImport(ctx context.Context, id uuid.UUID) (DatasetImport, error)
+	GetImportedJob(ctx context.Context, contentSha256 sql.NullString) (Job, error)
+	GetJob(ctx context.Context, id uuid.UUID) (Job, error)
+	GetJobForUpdate(ctx context.Context, id uuid.UUID) (Job, error)
+	GetJobTile(ctx context.Context, id uuid.UUID) (JobTile, error)
+	GetLogo(ctx context.Context, id int64) (Logo, error)
+	GetLogoForUpdate(ctx context.Context, id int64) (Logo, error)
+	GetLogoHash(ctx context.Context, logoID int64) (LogoHash, error)
+	GetLogosByJobID(ctx context.Context, jobID uuid.UUID) ([]Logo, error)
+	// The logo a cached copy was made from, the two share their crop
+	GetSourceLogo(ctx context.Context, arg GetSourceLogoParams) (Logo, error)
+	ListBrandReferences(ctx context.Context) ([]BrandReference, error)
+	ListBrandReferencesByBrand(ctx context.Context, brandID int64) ([]BrandReference, error)
+	ListBrands(ctx context.Context) ([]Brand, error)
+	ListCompositions(ctx context.Context, arg ListCompositionsParams) ([]Composition, error)
+	ListDatasetExports(ctx context.Context, arg ListDatasetExportsParams) ([]DatasetExport, error)
+	ListDatasetImports(ctx context.Context, arg ListDatasetImportsParams) ([]DatasetImport, error)
+	// The logos of jobs as the model detected them. Reviews overwrite the logo's type and
+	// box, so reviewed logos take the values kept by their first review. Copies in cached
+	// jobs are reviewed through their source logo and take the values of its first review.
+	ListDetectionsByJobIDs(ctx context.Context, jobIds []uuid.UUID) ([]ListDetectionsByJobIDsRow, error)
+	// Imported jobs have their own cutoff, so ground truth can be kept longer
+	ListExpiredJobs(ctx context.Context, arg ListExpiredJobsParams) ([]Job, error)
+	ListGroundTruthAnnotations(ctx context.Context, jobID uuid.UUID) ([]GroundTruthAnnotation, error)
+	ListGroundTruthByJobIDs(ctx context.Context, jobIds []uuid.UUID) ([]GroundTruthAnnotation, error)
+	ListJobEvents(ctx context.Context, jobID uuid.UUID) ([]JobEvent, error)
+	ListJobTiles(ctx context.Context, jobID uuid.UUID) ([]JobTile, error)
 	ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, error)
-	UpdateJobCompleted(ctx context.Context, arg UpdateJobCompletedParams) (Job, error)
-	UpdateJobError(ctx context.Context, arg UpdateJobErrorParams) (Job, error)
+	ListJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]Job, error)
+	ListJobsByImport(ctx context.Context, importID uuid.NullUUID) ([]Job, error)
+	ListLogoReviews(ctx context.Context, logoID int64) ([]LogoReview, error)
+	ListLogosForExport(ctx context.Context, arg ListLogosForExportParams) ([]ListLogosForExportRow, error)
+	// Logos copied into cached jobs are left out, they are reviewed through their source job
+	ListLogosForReview(ctx context.Context, arg ListLogosForReviewParams) ([]Logo, error)
+	// Rectified, masked and vectorized crops and overlays are shared with cached jobs too
+	ListReferencedLogoKeys(ctx context.Context, keys []string) ([]string, error)
+	ListStaleJobs(ctx context.Context, arg ListStaleJobsParams) ([]Job, error)
+	ListUnhashedLogos(ctx context.Context, arg ListUnhashedLogosParams) ([]Logo, error)
+	// Tiles that have not completed are detected again when the job is requeued
+	ResetJobTiles(ctx context.Context, jobID uuid.UUID) error
+	// band_keys are the band values near the searched hash, the index narrows
+	// the rows to those sharing one before distances are computed. Cached jobs
+	// copy the logos of their source, copies and the logo itself are skipped.
+	SearchSimilarLogos(ctx context.Context, arg SearchSimilarLogosParams) ([]SearchSimilarLogosRow, error)
+	UpdateBrand(ctx context.Context, arg UpdateBrandParams) (Brand, error)
+	// Copies of a logo in cached jobs take every review decision made on it
+	UpdateCopiedLogosReview(ctx context.Context, arg UpdateCopiedLogosReviewParams) ([]Logo, error)
+	UpdateJobModelVersion(ctx context.Context, arg UpdateJobModelVersionParams) error
+	UpdateJobResultUrl(ctx context.Context, arg UpdateJobResultUrlParams) error
+	// A job that runs again drops the error of its earlier attempt
 	UpdateJobStatus(ctx context.Context, arg UpdateJobStatusParams) (Job, error)
+	UpdateJobTileResult(ctx context.Context, arg UpdateJobTileResultParams) (JobTile, error)
+	UpdateLogoBrand(ctx context.Context, arg UpdateLogoBrandParams) error
+	UpdateLogoMask(ctx context.Context, arg UpdateLogoMaskParams) (Logo, error)
+	UpdateLogoPalette(ctx context.Context, arg UpdateLogoPaletteParams) (Logo, error)
+	UpdateLogoRectification(ctx context.Context, arg UpdateLogoRectificationParams) (Logo, error)
+	UpdateLogoReview(ctx context.Context, arg UpdateLogoReviewParams) (Logo, error)
+	UpdateLogoVector(ctx context.Context, arg UpdateLogoVectorParams) (Logo, error)
+	UpsertLogoHash(ctx context.Context, arg UpsertLogoHashParams) (LogoHash, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -1,17 +1,531 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/db"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidTransition is returned when the job's current status does not allow the requested one
+	ErrInvalidTransition = errors.New("invalid job status transition")
+	// ErrJobNotStale is returned when a stale-only transition finds a job that was updated recently
+	ErrJobNotStale = errors.New("job was updated recently")
+	// ErrInvalidReviewDecision is returned when a review decision is unknown
+	ErrInvalidReviewDecision = errors.New("invalid review decision")
 )
 
+// Store provides all queries plus the operations that need a transaction
+type Store interface {
+	Querier
+	DeleteJobTx(ctx context.Context, jobID uuid.UUID) (DeleteJobTxResult, error)
+	CreateJobTx(ctx context.Context, arg CreateJobTxParams) (Job, error)
+	CreateCachedJobTx(ctx context.Context, arg CreateCachedJobTxParams) (CompleteJobTxResult, error)
+	TransitionJobTx(ctx context.Context, arg TransitionJobTxParams) (Job, error)
+	CompleteJobTx(ctx context.Context, arg CompleteJobTxParams) (CompleteJobTxResult, error)
+	ReviewLogoTx(ctx context.Context, arg ReviewLogoTxParams) (ReviewLogoTxResult, error)
+	ImportJobTx(ctx context.Context, arg ImportJobTxParams) (ImportJobTxResult, error)
+	CreateJobTilesTx(ctx context.Context, tiles []CreateJobTileParams) ([]JobTile, error)
+	UpdateJobTileTx(ctx context.Context, arg UpdateJobTileResultParams) (UpdateJobTileTxResult, error)
+}
+
 type SQLStore struct {
 	*Queries
 	db *sql.DB
 }
 
-func NewStore(db *sql.DB) Querier {
+func NewStore(db *sql.DB) Store {
 	return &SQLStore{
 		db:      db,
 		Queries: New(db),
 	}
 }
+
+// execTx runs fn inside a database transaction
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteJobTxResult is the result of a job deletion
+type DeleteJobTxResult struct {
+	Job   Job       `json:"job"`
+	Logos []Logo    `json:"logos"`
+	Tiles []JobTile `json:"tiles"`
+}
+
+// DeleteJobTx removes a job and all of its logos in a single transaction.
+// It returns the deleted rows so callers can clean up the objects they owned.
+func (store *SQLStore) DeleteJobTx(ctx context.Context, jobID uuid.UUID) (DeleteJobTxResult, error) {
+	var result DeleteJobTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Job, err = q.GetJob(ctx, jobID)
+		if err != nil {
+			return err
+		}
+
+		result.Logos, err = q.GetLogosByJobID(ctx, jobID)
+		if err != nil {
+			return err
+		}
+
+		// Tiles are deleted with the job
+		result.Tiles, err = q.ListJobTiles(ctx, jobID)
+		if err != nil {
+			return err
+		}
+
+		if err = q.DeleteLogosByJobID(ctx, jobID); err != nil {
+			return err
+		}
+
+		return q.DeleteJob(ctx, jobID)
+	})
+
+	return result, err
+}
+
+// CreateJobTxParams contains the input of a job creation
+type CreateJobTxParams struct {
+	CreateJobParams
+	Actor string
+}
+
+// CreateJobTx creates a job and records its first event in the timeline
+func (store *SQLStore) CreateJobTx(ctx context.Context, arg CreateJobTxParams) (Job, error) {
+	var job Job
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		job, err = q.CreateJob(ctx, arg.CreateJobParams)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateJobEvent(ctx, CreateJobEventParams{
+			JobID:    job.ID,
+			ToStatus: job.Status,
+			Actor:    arg.Actor,
+			Reason:   sql.NullString{String: "Job created", Valid: true},
+		})
+		return err
+	})
+
+	return job, err
+}
+
+// CreateCachedJobTxParams contains the input of a job that reuses the result of an earlier job
+type CreateCachedJobTxParams struct {
+	CreateJobParams
+	Actor  string
+	Source Job
+}
+
+// CreateCachedJobTx creates a job for an image that was already detected with
+// the same parameters. The source job's logos are copied to the new job, which
+// is completed right away instead of being queued for detection again.
+func (store *SQLStore) CreateCachedJobTx(ctx context.Context, arg CreateCachedJobTxParams) (CompleteJobTxResult, error) {
+	var result CompleteJobTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		arg.SourceJobID = uuid.NullUUID{UUID: arg.Source.ID, Valid: true}
+		job, err := q.CreateJob(ctx, arg.CreateJobParams)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateJobEvent(ctx, CreateJobEventParams{
+			JobID:    job.ID,
+			ToStatus: job.Status,
+			Actor:    arg.Actor,
+			Reason:   sql.NullString{String: "Job created", Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Logos, err = q.CopyLogosToJob(ctx, CopyLogosToJobParams{
+			JobID:       job.ID,
+			SourceJobID: arg.Source.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Job, err = transitionJob(ctx, q, TransitionJobTxParams{
+			JobID:      job.ID,
+			ToStatus:   models.JobStatusCompleted,
+			Actor:      arg.Actor,
+			Reason:     fmt.Sprintf("Reused result of job %s", arg.Source.ID),
+			LogosFound: sql.NullString{String: strconv.Itoa(len(result.Logos)), Valid: true},
+			ResultUrl:  arg.Source.ResultUrl,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ImportJobTxParams contains the input of a job created from a labeled dataset
+type ImportJobTxParams struct {
+	CreateJobParams
+	Actor       string
+	Annotations []CreateGroundTruthAnnotationParams
+}
+
+// ImportJobTxResult is the result of a job created from a labeled dataset
+type ImportJobTxResult struct {
+	Job         Job                     `json:"job"`
+	Annotations []GroundTruthAnnotation `json:"annotations"`
+}
+
+// ImportJobTx creates a job together with its ground-truth annotations
+func (store *SQLStore) ImportJobTx(ctx context.Context, arg ImportJobTxParams) (ImportJobTxResult, error) {
+	var result ImportJobTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Job, err = q.CreateJob(ctx, arg.CreateJobParams)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateJobEvent(ctx, CreateJobEventParams{
+			JobID:    result.Job.ID,
+			ToStatus: result.Job.Status,
+			Actor:    arg.Actor,
+			Reason:   sql.NullString{String: "Job created from dataset import", Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Annotations = make([]GroundTruthAnnotation, 0, len(arg.Annotations))
+		for _, params := range arg.Annotations {
+			params.JobID = result.Job.ID
+			annotation, err := q.CreateGroundTruthAnnotation(ctx, params)
+			if err != nil {
+				return err
+			}
+			result.Annotations = append(result.Annotations, annotation)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// TransitionJobTxParams contains the input of a job status transition
+type TransitionJobTxParams struct {
+	JobID        uuid.UUID
+	ToStatus     string
+	Actor        string
+	Reason       string
+	ErrorMessage sql.NullString
+	LogosFound   sql.NullString
+	ResultUrl    sql.NullString
+	// Requeue increments the job's requeue count
+	Requeue bool
+	// StaleBefore, when set, only moves jobs that have not been updated since
+	StaleBefore time.Time
+}
+
+// TransitionJobTx moves a job to a new status if the state machine allows it
+// and records the transition in job_events
+func (store *SQLStore) TransitionJobTx(ctx context.Context, arg TransitionJobTxParams) (Job, error) {
+	var job Job
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		job, err = transitionJob(ctx, q, arg)
+		return err
+	})
+
+	return job, err
+}
+
+// CompleteJobTxParams contains the input of a completed detection result
+type CompleteJobTxParams struct {
+	JobID     uuid.UUID
+	Actor     string
+	ResultUrl sql.NullString
+	Logos     []CreateLogoParams
+	// ModelVersion is the detector version the worker reported, if any
+	ModelVersion string
+}
+
+// CompleteJobTxResult is the result of a completed detection result
+type CompleteJobTxResult struct {
+	Job   Job    `json:"job"`
+	Logos []Logo `json:"logos"`
+}
+
+// CompleteJobTx marks a job completed and stores its logos in a single transaction
+func (store *SQLStore) CompleteJobTx(ctx context.Context, arg CompleteJobTxParams) (CompleteJobTxResult, error) {
+	var result CompleteJobTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Job, err = transitionJob(ctx, q, TransitionJobTxParams{
+			JobID:      arg.JobID,
+			ToStatus:   models.JobStatusCompleted,
+			Actor:      arg.Actor,
+			Reason:     fmt.Sprintf("Detection completed with %d logos", len(arg.Logos)),
+			LogosFound: sql.NullString{String: strconv.Itoa(len(arg.Logos)), Valid: true},
+			ResultUrl:  arg.ResultUrl,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.ModelVersion != "" {
+			err = q.UpdateJobModelVersion(ctx, UpdateJobModelVersionParams{
+				ID:           arg.JobID,
+				ModelVersion: sql.NullString{String: arg.ModelVersion, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			result.Job.ModelVersion = sql.NullString{String: arg.ModelVersion, Valid: true}
+		}
+
+		result.Logos = make([]Logo, 0, len(arg.Logos))
+		for _, params := range arg.Logos {
+			params.JobID = arg.JobID
+			logo, err := q.CreateLogo(ctx, params)
+			if err != nil {
+				return err
+			}
+			result.Logos = append(result.Logos, logo)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// CreateJobTilesTx creates all tiles of a tiled job or none of them
+func (store *SQLStore) CreateJobTilesTx(ctx context.Context, tiles []CreateJobTileParams) ([]JobTile, error) {
+	created := make([]JobTile, 0, len(tiles))
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		for _, params := range tiles {
+			tile, err := q.CreateJobTile(ctx, params)
+			if err != nil {
+				return err
+			}
+			created = append(created, tile)
+		}
+		return nil
+	})
+
+	return created, err
+}
+
+// UpdateJobTileTxResult is the result of a tile update
+type UpdateJobTileTxResult struct {
+	Tile JobTile `json:"tile"`
+	Job  Job     `json:"job"`
+	// Tiles are all tiles of the job after the update
+	Tiles []JobTile `json:"tiles"`
+}
+
+// UpdateJobTileTx stores the result of one tile and returns every tile of
+// its job. The job row is locked, so of two tiles finishing at the same time
+// only the second sees both results.
+func (store *SQLStore) UpdateJobTileTx(ctx context.Context, arg UpdateJobTileResultParams) (UpdateJobTileTxResult, error) {
+	var result UpdateJobTileTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		tile, err := q.GetJobTile(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Job, err = q.GetJobForUpdate(ctx, tile.JobID)
+		if err != nil {
+			return err
+		}
+
+		result.Tile, err = q.UpdateJobTileResult(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		result.Tiles, err = q.ListJobTiles(ctx, tile.JobID)
+		return err
+	})
+
+	return result, err
+}
+
+// ReviewLogoTxParams contains the input of a review decision
+type ReviewLogoTxParams struct {
+	LogoID   int64
+	Reviewer string
+	Decision string
+	// LogoType replaces the logo's type when relabeling
+	LogoType string
+	// BoundingBox replaces the logo's bounding box when adjusting
+	BoundingBox db.BoundingBox
+	Comment     sql.NullString
+}
+
+// ReviewLogoTxResult is the result of a review decision. Logo is the
+// reviewed logo and Copies its copies in cached jobs.
+type ReviewLogoTxResult struct {
+	Logo   Logo       `json:"logo"`
+	Copies []Logo     `json:"copies"`
+	Review LogoReview `json:"review"`
+}
+
+// ReviewLogoTx applies a reviewer's decision to a logo and records it in
+// logo_reviews, keeping the values the logo had before the decision.
+// Reviewing a copy in a cached job reviews the logo it was copied from, and
+// the decision is applied to every copy of that logo.
+// Adjusting only moves the bounding box, the crop and everything derived
+// from it (rectification, mask, palette, vector and brand match) still show
+// the detected box.
+func (store *SQLStore) ReviewLogoTx(ctx context.Context, arg ReviewLogoTxParams) (ReviewLogoTxResult, error) {
+	var result ReviewLogoTxResult
+
+	status, ok := models.ReviewStatusFor(arg.Decision)
+	if !ok {
+		return result, fmt.Errorf("%w: %s", ErrInvalidReviewDecision, arg.Decision)
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		current, err := q.GetLogoForUpdate(ctx, arg.LogoID)
+		if err != nil {
+			return err
+		}
+		source, err := q.GetSourceLogo(ctx, GetSourceLogoParams{JobID: current.JobID, S3Key: current.S3Key})
+		switch {
+		case err == nil:
+			current, err = q.GetLogoForUpdate(ctx, source.ID)
+			if err != nil {
+				return err
+			}
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		logoType, boundingBox := current.LogoType, current.BoundingBox
+		switch arg.Decision {
+		case models.ReviewDecisionRelabel:
+			logoType = arg.LogoType
+		case models.ReviewDecisionAdjust:
+			boundingBox = arg.BoundingBox
+		}
+
+		result.Logo, err = q.UpdateLogoReview(ctx, UpdateLogoReviewParams{
+			ID:           current.ID,
+			ReviewStatus: status,
+			LogoType:     logoType,
+			BoundingBox:  boundingBox,
+		})
+		if err != nil {
+			return err
+		}
+		result.Copies, err = q.UpdateCopiedLogosReview(ctx, UpdateCopiedLogosReviewParams{
+			ReviewStatus: status,
+			LogoType:     logoType,
+			BoundingBox:  boundingBox,
+			SourceJobID:  current.JobID,
+			S3Key:        current.S3Key,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Review, err = q.CreateLogoReview(ctx, CreateLogoReviewParams{
+			LogoID:              current.ID,
+			Reviewer:            arg.Reviewer,
+			Decision:            arg.Decision,
+			PreviousLogoType:    current.LogoType,
+			LogoType:            logoType,
+			PreviousBoundingBox: current.BoundingBox,
+			BoundingBox:         boundingBox,
+			Comment:             arg.Comment,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+func transitionJob(ctx context.Context, q *Queries, arg TransitionJobTxParams) (Job, error) {
+	current, err := q.GetJobForUpdate(ctx, arg.JobID)
+	if err != nil {
+		return Job{}, err
+	}
+
+	if !arg.StaleBefore.IsZero() && !current.UpdatedAt.Before(arg.StaleBefore) {
+		return current, ErrJobNotStale
+	}
+	if !models.CanTransition(current.Status, arg.ToStatus) {
+		return current, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, current.Status, arg.ToStatus)
+	}
+
+	var requeueIncrement int32
+	if arg.Requeue {
+		requeueIncrement = 1
+	}
+
+	job, err := q.UpdateJobStatus(ctx, UpdateJobStatusParams{
+		Status:           arg.ToStatus,
+		ErrorMessage:     arg.ErrorMessage,
+		LogosFound:       arg.LogosFound,
+		ResultUrl:        arg.ResultUrl,
+		RequeueIncrement: requeueIncrement,
+		ID:               arg.JobID,
+		FromStatuses:     models.AllowedFromStatuses(arg.ToStatus),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return current, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, current.Status, arg.ToStatus)
+		}
+		return current, err
+	}
+
+	_, err = q.CreateJobEvent(ctx, CreateJobEventParams{
+		JobID:      arg.JobID,
+		FromStatus: sql.NullString{String: current.Status, Valid: true},
+		ToStatus:   arg.ToStatus,
+		Actor:      arg.Actor,
+		Reason:     sql.NullString{String: arg.Reason, Valid: arg.Reason != ""},
+	})
+	if err != nil {
+		return current, err
+	}
+
+	return job, nil
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	dbtypes "github.com/Viczdera/ai-logo-preserve/backend/internal/db"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestJob(t *testing.T, sourceJobID uuid.NullUUID) Job {
+	job, err := testQueries.CreateJob(context.Background(), CreateJobParams{
+		ID:          uuid.New(),
+		Status:      "completed",
+		S3Key:       uuid.New().String(),
+		UploadUrl:   uuid.New().String()[:10],
+		SourceJobID: sourceJobID,
+	})
+	require.NoError(t, err)
+	return job
+}
+
+func TestReviewLogoTxAppliesToCopies(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB)
+
+	source := createTestJob(t, uuid.NullUUID{})
+	logo, err := testQueries.CreateLogo(ctx, CreateLogoParams{
+		JobID:       source.ID,
+		BoundingBox: dbtypes.BoundingBox{X: 1, Y: 2, Width: 30, Height: 40},
+		Confidence:  0.9,
+		LogoType:    "nike",
+		S3Key:       uuid.New().String(),
+	})
+	require.NoError(t, err)
+
+	cached := createTestJob(t, uuid.NullUUID{UUID: source.ID, Valid: true})
+	copies, err := testQueries.CopyLogosToJob(ctx, CopyLogosToJobParams{JobID: cached.ID, SourceJobID: source.ID})
+	require.NoError(t, err)
+	require.Len(t, copies, 1)
+
+	// Reviewing the copy reviews the source and every copy of it
+	result, err := store.ReviewLogoTx(ctx, ReviewLogoTxParams{
+		LogoID:   copies[0].ID,
+		Reviewer: "alice",
+		Decision: models.ReviewDecisionRelabel,
+		LogoType: "adidas",
+	})
+	require.NoError(t, err)
+	require.Equal(t, logo.ID, result.Logo.ID)
+	require.Equal(t, logo.ID, result.Review.LogoID)
+	require.Equal(t, "nike", result.Review.PreviousLogoType)
+	require.Len(t, result.Copies, 1)
+
+	for _, id := range []int64{logo.ID, copies[0].ID} {
+		got, err := testQueries.GetLogo(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, "adidas", got.LogoType)
+		require.Equal(t, result.Logo.ReviewStatus, got.ReviewStatus)
+	}
+
+	// Reviewing the source again reaches the copy too
+	result, err = store.ReviewLogoTx(ctx, ReviewLogoTxParams{
+		LogoID:   logo.ID,
+		Reviewer: "alice",
+		Decision: models.ReviewDecisionReject,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Copies, 1)
+	require.Equal(t, result.Logo.ReviewStatus, result.Copies[0].ReviewStatus)
+}
+
+func TestListDetectionsByJobIDsResolvesCopies(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB)
+
+	source := createTestJob(t, uuid.NullUUID{})
+	detected := dbtypes.BoundingBox{X: 1, Y: 2, Width: 30, Height: 40}
+	logo, err := testQueries.CreateLogo(ctx, CreateLogoParams{
+		JobID:       source.ID,
+		BoundingBox: detected,
+		Confidence:  0.9,
+		LogoType:    "nike",
+		S3Key:       uuid.New().String(),
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReviewLogoTx(ctx, ReviewLogoTxParams{
+		LogoID:      logo.ID,
+		Reviewer:    "alice",
+		Decision:    models.ReviewDecisionAdjust,
+		BoundingBox: dbtypes.BoundingBox{X: 5, Y: 5, Width: 10, Height: 10},
+	})
+	require.NoError(t, err)
+
+	// The copy is made after the review and takes the corrected box
+	cached := createTestJob(t, uuid.NullUUID{UUID: source.ID, Valid: true})
+	copies, err := testQueries.CopyLogosToJob(ctx, CopyLogosToJobParams{JobID: cached.ID, SourceJobID: source.ID})
+	require.NoError(t, err)
+	require.Len(t, copies, 1)
+
+	detections, err := testQueries.ListDetectionsByJobIDs(ctx, []uuid.UUID{source.ID, cached.ID})
+	require.NoError(t, err)
+	require.Len(t, detections, 2)
+	for _, detection := range detections {
+		require.Equal(t, "nike", detection.LogoType)
+		require.Equal(t, detected, detection.BoundingBox)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
 )
 
 // BoundingBox represents a bounding box with x, y, width, height
@@ -32,3 +34,74 @@ func (bb *BoundingBox) Scan(value interface{}) error {
 
 	return json.Unmarshal(bytes, bb)
 }
+
+// Geometry stores a logo's polygons, rotated box and segmentation mask as
+// jsonb. Validation happens before it is written, see models.Geometry.
+type Geometry models.Geometry
+
+// Value implements the driver.Valuer interface for database storage
+func (g Geometry) Value() (driver.Value, error) {
+	return json.Marshal(g)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (g *Geometry) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into Geometry", value)
+	}
+
+	return json.Unmarshal(bytes, g)
+}
+
+// Palette stores the dominant colors of a logo as jsonb, NULL until extracted
+type Palette []models.PaletteColor
+
+// Value implements the driver.Valuer interface for database storage
+func (p Palette) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (p *Palette) Scan(value interface{}) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into Palette", value)
+	}
+
+	return json.Unmarshal(bytes, p)
+}
+
+// DetectionOptions stores a job's detection overrides as jsonb
+type DetectionOptions models.DetectionOptions
+
+// Value implements the driver.Valuer interface for database storage
+func (o DetectionOptions) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (o *DetectionOptions) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into DetectionOptions", value)
+	}
+
+	return json.Unmarshal(bytes, o)
+}
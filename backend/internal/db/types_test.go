@@ -0,0 +1,54 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundingBoxRoundTrip(t *testing.T) {
+	box := BoundingBox{X: 10, Y: 20, Width: 30, Height: 40}
+	value, err := box.Value()
+	require.NoError(t, err)
+
+	var scanned BoundingBox
+	require.NoError(t, scanned.Scan(value))
+	require.Equal(t, box, scanned)
+	require.Error(t, scanned.Scan("not bytes"))
+}
+
+func TestGeometryRoundTrip(t *testing.T) {
+	geometry := Geometry{
+		Polygons:   [][]models.Point{{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 2}}},
+		RotatedBox: &models.RotatedBox{CenterX: 2, CenterY: 1, Width: 4, Height: 2, Angle: 15},
+		Mask:       &models.RLEMask{Size: [2]int{2, 2}, Counts: []int{1, 3}},
+	}
+	value, err := geometry.Value()
+	require.NoError(t, err)
+
+	var scanned Geometry
+	require.NoError(t, scanned.Scan(value))
+	require.Equal(t, geometry, scanned)
+
+	// The column default is an empty object
+	var empty Geometry
+	require.NoError(t, empty.Scan([]byte(`{}`)))
+	require.True(t, models.Geometry(empty).IsZero())
+}
+
+func TestDetectionOptionsRoundTrip(t *testing.T) {
+	tiling := false
+	options := DetectionOptions{ConfidenceThreshold: 0.4, ModelVariant: "s", Classes: []string{"nike"}, MaxDetections: 10, Tiling: &tiling}
+	value, err := options.Value()
+	require.NoError(t, err)
+
+	var scanned DetectionOptions
+	require.NoError(t, scanned.Scan(value))
+	require.Equal(t, options, scanned)
+
+	// Jobs without options store an empty object
+	value, err = DetectionOptions{}.Value()
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{}`), value)
+}
@@ -0,0 +1,100 @@
+package eval
+
+import (
+	"slices"
+	"sort"
+)
+
+// Background is the confusion matrix label for a missed or spurious box
+const Background = "background"
+
+// ConfusionMatrix counts how ground-truth categories were detected. Rows are
+// ground-truth labels and columns detected labels; the background row counts
+// detections without ground truth and the background column missed ground truth.
+type ConfusionMatrix struct {
+	Labels []string `json:"labels"`
+	Matrix [][]int  `json:"matrix"`
+}
+
+// NewConfusionMatrix matches detections to ground truth regardless of
+// category, pairing the boxes with the highest IoU first
+func NewConfusionMatrix(groundTruths []GroundTruth, detections []Detection, iouThreshold float64) ConfusionMatrix {
+	var labels []string
+	for _, gt := range groundTruths {
+		labels = append(labels, gt.Category)
+	}
+	for _, det := range detections {
+		labels = append(labels, det.Category)
+	}
+	slices.Sort(labels)
+	labels = append(slices.Compact(labels), Background)
+
+	index := map[string]int{}
+	for i, label := range labels {
+		index[label] = i
+	}
+	background := len(labels) - 1
+
+	cm := ConfusionMatrix{Labels: labels, Matrix: make([][]int, len(labels))}
+	for i := range cm.Matrix {
+		cm.Matrix[i] = make([]int, len(labels))
+	}
+
+	gtsByImage := map[string][]GroundTruth{}
+	for _, gt := range groundTruths {
+		gtsByImage[gt.ImageID] = append(gtsByImage[gt.ImageID], gt)
+	}
+	detsByImage := map[string][]Detection{}
+	for _, det := range detections {
+		detsByImage[det.ImageID] = append(detsByImage[det.ImageID], det)
+	}
+
+	type pair struct {
+		gt, det int
+		iou     float64
+	}
+	for image, gts := range gtsByImage {
+		dets := detsByImage[image]
+		var pairs []pair
+		for i, gt := range gts {
+			for j, det := range dets {
+				if iou := IoU(gt.Box, det.Box); iou >= iouThreshold {
+					pairs = append(pairs, pair{i, j, iou})
+				}
+			}
+		}
+		sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].iou > pairs[j].iou })
+
+		gtMatched := make([]bool, len(gts))
+		detMatched := make([]bool, len(dets))
+		for _, p := range pairs {
+			if gtMatched[p.gt] || detMatched[p.det] {
+				continue
+			}
+			gtMatched[p.gt], detMatched[p.det] = true, true
+			cm.Matrix[index[gts[p.gt].Category]][index[dets[p.det].Category]]++
+		}
+		for i, gt := range gts {
+			if !gtMatched[i] {
+				cm.Matrix[index[gt.Category]][background]++
+			}
+		}
+		for j, det := range dets {
+			if !detMatched[j] {
+				cm.Matrix[background][index[det.Category]]++
+			}
+		}
+	}
+
+	// Detections on images without any ground truth
+	for image, dets := range detsByImage {
+		if _, ok := gtsByImage[image]; ok {
+			continue
+		}
+		for _, det := range dets {
+			cm.Matrix[background][index[det.Category]]++
+		}
+	}
+
+	return cm
+}
@@ -0,0 +1,247 @@
+// Package eval measures detection quality against ground truth. Detections
+// are matched to ground-truth boxes of the same category by IoU, the way the
+// COCO evaluation does.
+package eval
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// IoUThresholds are the thresholds mAP@0.5:0.95 averages over
+var IoUThresholds = []float64{0.5, 0.55, 0.6, 0.65, 0.7, 0.75, 0.8, 0.85, 0.9, 0.95}
+
+// DefaultIoUThreshold is the threshold for precision, recall, F1 and the confusion matrix
+const DefaultIoUThreshold = 0.5
+
+// Box is an axis-aligned box in pixels
+type Box struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// IoU is the intersection over union of two boxes
+func IoU(a, b Box) float64 {
+	width := math.Min(a.X+a.Width, b.X+b.Width) - math.Max(a.X, b.X)
+	height := math.Min(a.Y+a.Height, b.Y+b.Height) - math.Max(a.Y, b.Y)
+	if width <= 0 || height <= 0 {
+		return 0
+	}
+	intersection := width * height
+	return intersection / (a.Width*a.Height + b.Width*b.Height - intersection)
+}
+
+// GroundTruth is a labeled box on an image
+type GroundTruth struct {
+	ImageID  string
+	Category string
+	Box      Box
+}
+
+// Detection is a box the detector found on an image
+type Detection struct {
+	ImageID  string
+	Category string
+	Box      Box
+	Score    float64
+}
+
+// Metrics are the results for one category, or for all of them
+type Metrics struct {
+	Category       string  `json:"category"`
+	GroundTruths   int     `json:"ground_truths"`
+	Detections     int     `json:"detections"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+	// AP50, AP75 and MAP are nil when there is no ground truth to average over
+	AP50 *float64 `json:"ap50"`
+	AP75 *float64 `json:"ap75"`
+	MAP  *float64 `json:"map"`
+}
+
+// Report is the evaluation of a set of images
+type Report struct {
+	IoUThreshold    float64         `json:"iou_threshold"`
+	Overall         Metrics         `json:"overall"`
+	Categories      []Metrics       `json:"categories"`
+	ConfusionMatrix ConfusionMatrix `json:"confusion_matrix"`
+}
+
+// Evaluate compares detections with ground truth. Precision, recall, F1 and
+// the confusion matrix use iouThreshold; AP is computed at every threshold in
+// IoUThresholds with COCO's 101-point interpolation.
+func Evaluate(groundTruths []GroundTruth, detections []Detection, iouThreshold float64) Report {
+	report := Report{
+		IoUThreshold:    iouThreshold,
+		Overall:         Metrics{Category: "all"},
+		Categories:      []Metrics{},
+		ConfusionMatrix: NewConfusionMatrix(groundTruths, detections, iouThreshold),
+	}
+
+	var categories []string
+	for _, gt := range groundTruths {
+		categories = append(categories, gt.Category)
+	}
+	for _, det := range detections {
+		categories = append(categories, det.Category)
+	}
+	slices.Sort(categories)
+	categories = slices.Compact(categories)
+
+	var ap50s, ap75s, maps []float64
+	for _, category := range categories {
+		metrics := evaluateCategory(category, groundTruths, detections, iouThreshold)
+		report.Categories = append(report.Categories, metrics)
+
+		overall := &report.Overall
+		overall.GroundTruths += metrics.GroundTruths
+		overall.Detections += metrics.Detections
+		overall.TruePositives += metrics.TruePositives
+		overall.FalsePositives += metrics.FalsePositives
+		overall.FalseNegatives += metrics.FalseNegatives
+		if metrics.MAP != nil {
+			ap50s = append(ap50s, *metrics.AP50)
+			ap75s = append(ap75s, *metrics.AP75)
+			maps = append(maps, *metrics.MAP)
+		}
+	}
+
+	// Counts are summed over categories, AP is the mean over categories
+	report.Overall.Precision, report.Overall.Recall, report.Overall.F1 = scores(report.Overall)
+	report.Overall.AP50, report.Overall.AP75, report.Overall.MAP = mean(ap50s), mean(ap75s), mean(maps)
+	return report
+}
+
+func evaluateCategory(category string, groundTruths []GroundTruth, detections []Detection, iouThreshold float64) Metrics {
+	metrics := Metrics{Category: category}
+
+	gtsByImage := map[string][]Box{}
+	for _, gt := range groundTruths {
+		if gt.Category == category {
+			gtsByImage[gt.ImageID] = append(gtsByImage[gt.ImageID], gt.Box)
+			metrics.GroundTruths++
+		}
+	}
+	var dets []Detection
+	for _, det := range detections {
+		if det.Category == category {
+			dets = append(dets, det)
+		}
+	}
+	metrics.Detections = len(dets)
+
+	// Most confident first, so they claim their ground truth first
+	sort.SliceStable(dets, func(i, j int) bool { return dets[i].Score > dets[j].Score })
+
+	matched := match(gtsByImage, dets, iouThreshold)
+	for _, ok := range matched {
+		if ok {
+			metrics.TruePositives++
+		}
+	}
+	metrics.FalsePositives = metrics.Detections - metrics.TruePositives
+	metrics.FalseNegatives = metrics.GroundTruths - metrics.TruePositives
+	metrics.Precision, metrics.Recall, metrics.F1 = scores(metrics)
+
+	if metrics.GroundTruths == 0 {
+		return metrics
+	}
+	aps := make([]float64, len(IoUThresholds))
+	for i, threshold := range IoUThresholds {
+		aps[i] = averagePrecision(match(gtsByImage, dets, threshold), metrics.GroundTruths)
+	}
+	metrics.AP50 = &aps[0]
+	metrics.AP75 = &aps[5]
+	metrics.MAP = mean(aps)
+	return metrics
+}
+
+// match greedily assigns each detection, in order, to the unmatched ground
+// truth on its image with the highest IoU of at least threshold. It reports
+// which detections are true positives.
+func match(gtsByImage map[string][]Box, dets []Detection, threshold float64) []bool {
+	taken := map[string][]bool{}
+	for image, boxes := range gtsByImage {
+		taken[image] = make([]bool, len(boxes))
+	}
+
+	matched := make([]bool, len(dets))
+	for i, det := range dets {
+		best, bestIoU := -1, threshold
+		for j, box := range gtsByImage[det.ImageID] {
+			if taken[det.ImageID][j] {
+				continue
+			}
+			if iou := IoU(det.Box, box); iou >= bestIoU {
+				best, bestIoU = j, iou
+			}
+		}
+		if best >= 0 {
+			taken[det.ImageID][best] = true
+			matched[i] = true
+		}
+	}
+	return matched
+}
+
+// averagePrecision is the area under the precision-recall curve of
+// detections sorted by score, sampled at 101 recall points
+func averagePrecision(matched []bool, groundTruths int) float64 {
+	precisions := make([]float64, len(matched))
+	recalls := make([]float64, len(matched))
+	tp := 0
+	for i, ok := range matched {
+		if ok {
+			tp++
+		}
+		precisions[i] = float64(tp) / float64(i+1)
+		recalls[i] = float64(tp) / float64(groundTruths)
+	}
+
+	// Make precision monotonically decreasing
+	for i := len(precisions) - 2; i >= 0; i-- {
+		precisions[i] = math.Max(precisions[i], precisions[i+1])
+	}
+
+	var sum float64
+	for point := 0; point <= 100; point++ {
+		recall := float64(point) / 100
+		i := sort.SearchFloat64s(recalls, recall)
+		if i < len(precisions) {
+			sum += precisions[i]
+		}
+	}
+	return sum / 101
+}
+
+func scores(m Metrics) (precision, recall, f1 float64) {
+	if m.Detections > 0 {
+		precision = float64(m.TruePositives) / float64(m.Detections)
+	}
+	if m.GroundTruths > 0 {
+		recall = float64(m.TruePositives) / float64(m.GroundTruths)
+	}
+	if precision+recall > 0 {
+		f1 = 2 * precision * recall / (precision + recall)
+	}
+	return precision, recall, f1
+}
+
+func mean(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	result := sum / float64(len(values))
+	return &result
+}
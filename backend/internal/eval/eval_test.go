@@ -0,0 +1,108 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIoU(t *testing.T) {
+	box := Box{X: 0, Y: 0, Width: 10, Height: 10}
+
+	require.InDelta(t, 1.0, IoU(box, box), 1e-9)
+	require.InDelta(t, 50.0/150.0, IoU(box, Box{X: 5, Y: 0, Width: 10, Height: 10}), 1e-9)
+	require.Zero(t, IoU(box, Box{X: 10, Y: 0, Width: 10, Height: 10}))
+}
+
+func TestEvaluatePerfectDetections(t *testing.T) {
+	gts := []GroundTruth{
+		{ImageID: "a", Category: "text", Box: Box{X: 0, Y: 0, Width: 10, Height: 10}},
+		{ImageID: "b", Category: "icon", Box: Box{X: 20, Y: 20, Width: 30, Height: 30}},
+	}
+	dets := []Detection{
+		{ImageID: "a", Category: "text", Box: gts[0].Box, Score: 90},
+		{ImageID: "b", Category: "icon", Box: gts[1].Box, Score: 80},
+	}
+
+	report := Evaluate(gts, dets, DefaultIoUThreshold)
+	require.Equal(t, 2, report.Overall.TruePositives)
+	require.Equal(t, 1.0, report.Overall.Precision)
+	require.Equal(t, 1.0, report.Overall.Recall)
+	require.Equal(t, 1.0, report.Overall.F1)
+	require.InDelta(t, 1.0, *report.Overall.MAP, 1e-9)
+	require.Len(t, report.Categories, 2)
+	require.Equal(t, "icon", report.Categories[0].Category)
+}
+
+func TestEvaluateCountsErrors(t *testing.T) {
+	gts := []GroundTruth{
+		{ImageID: "a", Category: "text", Box: Box{X: 0, Y: 0, Width: 10, Height: 10}},
+		{ImageID: "a", Category: "text", Box: Box{X: 50, Y: 50, Width: 10, Height: 10}},
+	}
+	dets := []Detection{
+		{ImageID: "a", Category: "text", Box: Box{X: 1, Y: 0, Width: 10, Height: 10}, Score: 90},
+		// Duplicate of the first detection, the ground truth is already taken
+		{ImageID: "a", Category: "text", Box: Box{X: 0, Y: 1, Width: 10, Height: 10}, Score: 50},
+		{ImageID: "b", Category: "icon", Box: Box{X: 0, Y: 0, Width: 10, Height: 10}, Score: 70},
+	}
+
+	report := Evaluate(gts, dets, DefaultIoUThreshold)
+	text := report.Categories[1]
+	require.Equal(t, "text", text.Category)
+	require.Equal(t, 1, text.TruePositives)
+	require.Equal(t, 1, text.FalsePositives)
+	require.Equal(t, 1, text.FalseNegatives)
+	require.Equal(t, 0.5, text.Precision)
+	require.Equal(t, 0.5, text.Recall)
+
+	icon := report.Categories[0]
+	require.Equal(t, 1, icon.FalsePositives)
+	require.Nil(t, icon.MAP)
+	require.Equal(t, 3, report.Overall.Detections)
+	require.InDelta(t, *text.MAP, *report.Overall.MAP, 1e-9)
+}
+
+func TestConfusionMatrix(t *testing.T) {
+	gts := []GroundTruth{
+		{ImageID: "a", Category: "text", Box: Box{X: 0, Y: 0, Width: 10, Height: 10}},
+		{ImageID: "a", Category: "icon", Box: Box{X: 50, Y: 50, Width: 10, Height: 10}},
+	}
+	dets := []Detection{
+		{ImageID: "a", Category: "icon", Box: Box{X: 0, Y: 0, Width: 10, Height: 10}, Score: 90},
+		{ImageID: "b", Category: "text", Box: Box{X: 0, Y: 0, Width: 10, Height: 10}, Score: 90},
+	}
+
+	cm := NewConfusionMatrix(gts, dets, DefaultIoUThreshold)
+	require.Equal(t, []string{"icon", "text", Background}, cm.Labels)
+	require.Equal(t, [][]int{
+		{0, 0, 1},
+		{1, 0, 0},
+		{0, 1, 0},
+	}, cm.Matrix)
+}
+
+func TestParseCOCO(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	data := []byte(`{
+		"images": [
+			{"id": 1, "file_name": "images/` + first.String() + `.jpg"},
+			{"id": 2, "file_name": "photo.jpg", "job_id": "` + second.String() + `"}
+		],
+		"annotations": [
+			{"image_id": 1, "category_id": 3, "bbox": [1, 2, 3, 4]},
+			{"image_id": 2, "category_id": 3, "bbox": [0, 0, 5, 5], "iscrowd": 1}
+		],
+		"categories": [{"id": 3, "name": "text"}]
+	}`)
+
+	gts, jobIDs, err := ParseCOCO(data)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{first, second}, jobIDs)
+	require.Equal(t, []GroundTruth{
+		{ImageID: first.String(), Category: "text", Box: Box{X: 1, Y: 2, Width: 3, Height: 4}},
+	}, gts)
+
+	_, _, err = ParseCOCO([]byte(`{"images": [{"id": 1, "file_name": "photo.jpg"}]}`))
+	require.Error(t, err)
+}
@@ -0,0 +1,210 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// UnknownModelVersion groups jobs created before model versions were recorded
+const UnknownModelVersion = "unknown"
+
+// ModelReport is the evaluation of the jobs detected by one model version
+type ModelReport struct {
+	ModelVersion string `json:"model_version"`
+	Jobs         int    `json:"jobs"`
+	Report
+}
+
+// SkippedJob is a requested job that could not be evaluated
+type SkippedJob struct {
+	JobID  uuid.UUID `json:"job_id"`
+	Reason string    `json:"reason"`
+}
+
+// Result is the evaluation of a job set, split by model version
+type Result struct {
+	Models      []ModelReport `json:"models"`
+	SkippedJobs []SkippedJob  `json:"skipped_jobs"`
+}
+
+// EvaluateJobs compares the detections of jobs, as the model made them
+// before any review, with groundTruths, whose image IDs are job IDs. Only
+// completed jobs are evaluated, and each model version gets its own report.
+func EvaluateJobs(ctx context.Context, store db.Store, jobIDs []uuid.UUID, groundTruths []GroundTruth, iouThreshold float64) (Result, error) {
+	result := Result{Models: []ModelReport{}, SkippedJobs: []SkippedJob{}}
+
+	jobs, err := store.ListJobsByIDs(ctx, jobIDs)
+	if err != nil {
+		return result, err
+	}
+	found := map[uuid.UUID]bool{}
+	for _, job := range jobs {
+		found[job.ID] = true
+	}
+	for _, id := range jobIDs {
+		if !found[id] {
+			result.SkippedJobs = append(result.SkippedJobs, SkippedJob{JobID: id, Reason: "job not found"})
+			found[id] = true
+		}
+	}
+
+	var versions []string
+	jobsByVersion := map[string][]uuid.UUID{}
+	versionByJob := map[string]string{}
+	for _, job := range jobs {
+		if job.Status != models.JobStatusCompleted {
+			result.SkippedJobs = append(result.SkippedJobs, SkippedJob{JobID: job.ID, Reason: "job is " + job.Status})
+			continue
+		}
+		version := UnknownModelVersion
+		if job.ModelVersion.Valid && job.ModelVersion.String != "" {
+			version = job.ModelVersion.String
+		}
+		if _, ok := jobsByVersion[version]; !ok {
+			versions = append(versions, version)
+		}
+		jobsByVersion[version] = append(jobsByVersion[version], job.ID)
+		versionByJob[job.ID.String()] = version
+	}
+
+	// Reviewers correct the logos, the model is judged on what it detected
+	logos, err := store.ListDetectionsByJobIDs(ctx, jobIDs)
+	if err != nil {
+		return result, err
+	}
+	detectionsByVersion := map[string][]Detection{}
+	for _, logo := range logos {
+		version, ok := versionByJob[logo.JobID.String()]
+		if !ok {
+			continue
+		}
+		detectionsByVersion[version] = append(detectionsByVersion[version], Detection{
+			ImageID:  logo.JobID.String(),
+			Category: logo.LogoType,
+			Box:      boxFromBBox(models.BBox(logo.BoundingBox)),
+			Score:    float64(logo.Confidence),
+		})
+	}
+
+	groundTruthsByVersion := map[string][]GroundTruth{}
+	for _, gt := range groundTruths {
+		if version, ok := versionByJob[gt.ImageID]; ok {
+			groundTruthsByVersion[version] = append(groundTruthsByVersion[version], gt)
+		}
+	}
+
+	for _, version := range versions {
+		result.Models = append(result.Models, ModelReport{
+			ModelVersion: version,
+			Jobs:         len(jobsByVersion[version]),
+			Report:       Evaluate(groundTruthsByVersion[version], detectionsByVersion[version], iouThreshold),
+		})
+	}
+	return result, nil
+}
+
+// LoadGroundTruth returns the imported ground-truth annotations of jobs
+func LoadGroundTruth(ctx context.Context, store db.Store, jobIDs []uuid.UUID) ([]GroundTruth, error) {
+	annotations, err := store.ListGroundTruthByJobIDs(ctx, jobIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	groundTruths := make([]GroundTruth, 0, len(annotations))
+	for _, annotation := range annotations {
+		var box models.BBox
+		if err := json.Unmarshal([]byte(annotation.BoundingBox), &box); err != nil {
+			return nil, fmt.Errorf("invalid bounding box of annotation %d: %w", annotation.ID, err)
+		}
+		groundTruths = append(groundTruths, GroundTruth{
+			ImageID:  annotation.JobID.String(),
+			Category: annotation.LogoType,
+			Box:      boxFromBBox(box),
+		})
+	}
+	return groundTruths, nil
+}
+
+type cocoGroundTruth struct {
+	Images []struct {
+		ID       int    `json:"id"`
+		FileName string `json:"file_name"`
+		JobID    string `json:"job_id"`
+	} `json:"images"`
+	Annotations []struct {
+		ImageID    int       `json:"image_id"`
+		CategoryID int       `json:"category_id"`
+		BBox       []float64 `json:"bbox"`
+		IsCrowd    int       `json:"iscrowd"`
+	} `json:"annotations"`
+	Categories []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"categories"`
+}
+
+// ParseCOCO reads ground truth from a COCO annotation file. Each image is a
+// job, given by a job_id field or by a file_name like <job_id>.jpg as in
+// dataset exports. It returns the ground truth and the job IDs of the images.
+func ParseCOCO(data []byte) ([]GroundTruth, []uuid.UUID, error) {
+	var input cocoGroundTruth
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, nil, err
+	}
+
+	jobByImage := map[int]uuid.UUID{}
+	jobIDs := make([]uuid.UUID, 0, len(input.Images))
+	for _, img := range input.Images {
+		value := img.JobID
+		if value == "" {
+			base := path.Base(img.FileName)
+			value = strings.TrimSuffix(base, path.Ext(base))
+		}
+		jobID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("image %d is not named after a job ID", img.ID)
+		}
+		jobByImage[img.ID] = jobID
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	categories := map[int]string{}
+	for _, category := range input.Categories {
+		categories[category.ID] = category.Name
+	}
+
+	groundTruths := make([]GroundTruth, 0, len(input.Annotations))
+	for _, annotation := range input.Annotations {
+		if annotation.IsCrowd != 0 {
+			continue
+		}
+		jobID, ok := jobByImage[annotation.ImageID]
+		if !ok {
+			return nil, nil, fmt.Errorf("annotation references unknown image %d", annotation.ImageID)
+		}
+		category, ok := categories[annotation.CategoryID]
+		if !ok {
+			return nil, nil, fmt.Errorf("annotation references unknown category %d", annotation.CategoryID)
+		}
+		if len(annotation.BBox) != 4 {
+			return nil, nil, fmt.Errorf("annotation bbox must have 4 values")
+		}
+		groundTruths = append(groundTruths, GroundTruth{
+			ImageID:  jobID.String(),
+			Category: category,
+			Box:      Box{X: annotation.BBox[0], Y: annotation.BBox[1], Width: annotation.BBox[2], Height: annotation.BBox[3]},
+		})
+	}
+	return groundTruths, jobIDs, nil
+}
+
+func boxFromBBox(box models.BBox) Box {
+	return Box{X: float64(box.X), Y: float64(box.Y), Width: float64(box.Width), Height: float64(box.Height)}
+}
@@ -0,0 +1,48 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	dbtypes "github.com/Viczdera/ai-logo-preserve/backend/internal/db"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type detectionStore struct {
+	db.Store
+	jobs       []db.Job
+	detections []db.ListDetectionsByJobIDsRow
+}
+
+func (s *detectionStore) ListJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]db.Job, error) {
+	return s.jobs, nil
+}
+
+func (s *detectionStore) ListDetectionsByJobIDs(ctx context.Context, jobIds []uuid.UUID) ([]db.ListDetectionsByJobIDsRow, error) {
+	return s.detections, nil
+}
+
+func TestEvaluateJobsScoresModelOutput(t *testing.T) {
+	job := db.Job{ID: uuid.New(), Status: models.JobStatusCompleted}
+	// A reviewer moved the box onto the ground truth, the model's own box misses it
+	store := &detectionStore{
+		jobs: []db.Job{job},
+		detections: []db.ListDetectionsByJobIDsRow{{
+			JobID:       job.ID,
+			LogoType:    "text",
+			Confidence:  0.9,
+			BoundingBox: dbtypes.BoundingBox{X: 50, Y: 50, Width: 10, Height: 10},
+		}},
+	}
+	gts := []GroundTruth{{ImageID: job.ID.String(), Category: "text", Box: Box{X: 0, Y: 0, Width: 10, Height: 10}}}
+
+	result, err := EvaluateJobs(context.Background(), store, []uuid.UUID{job.ID}, gts, 0.5)
+	require.NoError(t, err)
+	require.Len(t, result.Models, 1)
+	require.Equal(t, UnknownModelVersion, result.Models[0].ModelVersion)
+	require.Zero(t, result.Models[0].Overall.TruePositives)
+	require.Equal(t, 1, result.Models[0].Overall.FalsePositives)
+}
@@ -0,0 +1,84 @@
+package extraction
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRectifiedKey(t *testing.T) {
+	require.Equal(t, "extracted/job/logo_0_rectified.png", RectifiedKey("extracted/job/logo_0.jpg"))
+	require.Equal(t, "extracted/job/logo_rectified.png", RectifiedKey("extracted/job/logo"))
+}
+
+func TestMaskedKey(t *testing.T) {
+	require.Equal(t, "extracted/job/logo_0_masked.png", MaskedKey("extracted/job/logo_0.jpg"))
+}
+
+func TestVectorKey(t *testing.T) {
+	require.Equal(t, "extracted/job/logo_0_vector.svg", VectorKey("extracted/job/logo_0.jpg"))
+}
+
+func TestPaletteColorsRoundTrip(t *testing.T) {
+	red, err := imaging.ParseHexColor("#e30613")
+	require.NoError(t, err)
+	palette := []imaging.PaletteColor{{Color: red, Proportion: 0.6}, {Color: imaging.LabFromRGB(255, 255, 255), Proportion: 0.4}}
+
+	colors := PaletteColors(palette)
+	require.Equal(t, "#e30613", colors[0].Hex)
+	require.Equal(t, "#ffffff", colors[1].Hex)
+	require.Equal(t, 0.4, colors[1].Proportion)
+	require.Equal(t, palette, ImagingPalette(colors))
+}
+
+type jobStore struct {
+	db.Store
+	job db.Job
+}
+
+func (s jobStore) GetJob(ctx context.Context, id uuid.UUID) (db.Job, error) {
+	return s.job, nil
+}
+
+// imageStorage serves one image and fails the test on uploads
+type imageStorage struct {
+	storage.Client
+	t     *testing.T
+	image []byte
+}
+
+func (s imageStorage) DownloadFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.image)), nil
+}
+
+func (s imageStorage) UploadFile(ctx context.Context, key string, file io.Reader, size int64) (*storage.UploadOutput, error) {
+	s.t.Fatalf("unexpected upload of %s", key)
+	return nil, nil
+}
+
+func TestRectifyRejectsOversizedCorners(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 300))))
+	store := jobStore{job: db.Job{ID: uuid.New(), S3Key: "original/job/image.png"}}
+	rectifier := NewRectifier(utils.RectifyConfig{MaxPixels: 10_000}, store, imageStorage{t: t, image: buf.Bytes()})
+	logo := db.Logo{ID: 1, JobID: store.job.ID, S3Key: "extracted/job/logo_0.png"}
+
+	// Inside the image, but 400x300 is over the limit
+	_, err := rectifier.Rectify(context.Background(), logo, []models.Point{{X: 0, Y: 0}, {X: 400, Y: 0}, {X: 400, Y: 300}, {X: 0, Y: 300}})
+	require.ErrorIs(t, err, ErrRectifiedTooLarge)
+
+	// Far outside the image, rejected before anything is sized
+	_, err = rectifier.Rectify(context.Background(), logo, []models.Point{{X: 0, Y: 0}, {X: 1e9, Y: 0}, {X: 1e9, Y: 1e9}, {X: 0, Y: 1e9}})
+	require.ErrorIs(t, err, imaging.ErrCornersOutOfBounds)
+}
@@ -0,0 +1,91 @@
+package extraction
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"image"
+	"image/png"
+	"path"
+	"strings"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+)
+
+// Where the alpha mask of a logo came from
+const (
+	MaskSourceGenerated = "generated"
+	MaskSourceExternal  = "external"
+)
+
+// MaskedKey is where the transparent crop is stored, next to its raw crop
+func MaskedKey(cropKey string) string {
+	return strings.TrimSuffix(cropKey, path.Ext(cropKey)) + "_masked.png"
+}
+
+// Masker removes the background from extracted crops
+type Masker struct {
+	store         db.Store
+	storageClient storage.Client
+}
+
+func NewMasker(store db.Store, storageClient storage.Client) *Masker {
+	return &Masker{
+		store:         store,
+		storageClient: storageClient,
+	}
+}
+
+// Mask writes a transparent PNG of the logo's crop and records the mask
+// quality on the logo. The mask is read from maskKey when the worker
+// provided a segmentation mask, and generated from the crop otherwise.
+func (m *Masker) Mask(ctx context.Context, logo db.Logo, maskKey string) (db.Logo, error) {
+	crop, err := downloadImage(ctx, m.storageClient, logo.S3Key)
+	if err != nil {
+		return logo, fmt.Errorf("failed to load crop: %w", err)
+	}
+
+	var mask *image.Alpha
+	var quality float64
+	source := MaskSourceGenerated
+	if maskKey != "" {
+		external, err := downloadImage(ctx, m.storageClient, maskKey)
+		if err != nil {
+			return logo, fmt.Errorf("failed to load mask: %w", err)
+		}
+		mask = imaging.MaskFromImage(external, crop.Bounds())
+		quality = imaging.MaskQuality(crop, mask)
+		source = MaskSourceExternal
+	} else {
+		mask, quality = imaging.GenerateMask(crop)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, imaging.ApplyMask(crop, mask)); err != nil {
+		return logo, fmt.Errorf("failed to encode masked logo: %w", err)
+	}
+	key := MaskedKey(logo.S3Key)
+	if _, err := m.storageClient.UploadFile(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		return logo, err
+	}
+
+	return m.store.UpdateLogoMask(ctx, db.UpdateLogoMaskParams{
+		ID:          logo.ID,
+		MaskedS3Key: sql.NullString{String: key, Valid: true},
+		MaskQuality: sql.NullFloat64{Float64: quality, Valid: true},
+		MaskSource:  sql.NullString{String: source, Valid: true},
+	})
+}
+
+// downloadImage fetches and decodes an image from the bucket
+func downloadImage(ctx context.Context, storageClient storage.Client, key string) (image.Image, error) {
+	body, err := storageClient.DownloadFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return imaging.Decode(body)
+}
@@ -0,0 +1,71 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+
+	dbtypes "github.com/Viczdera/ai-logo-preserve/backend/internal/db"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+)
+
+// PaletteExtractor finds the dominant colors of extracted logos
+type PaletteExtractor struct {
+	store         db.Store
+	storageClient storage.Client
+	colors        int
+}
+
+func NewPaletteExtractor(store db.Store, storageClient storage.Client, colors int) *PaletteExtractor {
+	return &PaletteExtractor{
+		store:         store,
+		storageClient: storageClient,
+		colors:        colors,
+	}
+}
+
+// Extract stores the palette of the logo's masked crop on the logo. Logos
+// without a mask fall back to the raw crop, background included.
+func (e *PaletteExtractor) Extract(ctx context.Context, logo db.Logo) (db.Logo, error) {
+	key := logo.S3Key
+	if logo.MaskedS3Key.Valid {
+		key = logo.MaskedS3Key.String
+	}
+
+	crop, err := downloadImage(ctx, e.storageClient, key)
+	if err != nil {
+		return logo, fmt.Errorf("failed to load crop: %w", err)
+	}
+
+	return e.store.UpdateLogoPalette(ctx, db.UpdateLogoPaletteParams{
+		ID:      logo.ID,
+		Palette: dbtypes.Palette(PaletteColors(imaging.ExtractPalette(crop, e.colors))),
+	})
+}
+
+// PaletteColors converts an extracted palette to its stored form
+func PaletteColors(palette []imaging.PaletteColor) []models.PaletteColor {
+	colors := make([]models.PaletteColor, 0, len(palette))
+	for _, color := range palette {
+		colors = append(colors, models.PaletteColor{
+			Hex:        color.Color.Hex(),
+			Lab:        [3]float64{color.Color.L, color.Color.A, color.Color.B},
+			Proportion: color.Proportion,
+		})
+	}
+	return colors
+}
+
+// ImagingPalette converts a stored palette back for comparisons
+func ImagingPalette(colors []models.PaletteColor) []imaging.PaletteColor {
+	palette := make([]imaging.PaletteColor, 0, len(colors))
+	for _, color := range colors {
+		palette = append(palette, imaging.PaletteColor{
+			Color:      imaging.Lab{L: color.Lab[0], A: color.Lab[1], B: color.Lab[2]},
+			Proportion: color.Proportion,
+		})
+	}
+	return palette
+}
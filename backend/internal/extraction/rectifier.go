@@ -0,0 +1,108 @@
+// Package extraction refines extracted logos. A logo photographed in
+// perspective is outlined by four corners and unwarped into a flat rectangle
+// through the homography between the corners and the rectangle, and the
+// background behind a logo is masked out.
+package extraction
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"path"
+	"strings"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+)
+
+// ErrRectifiedTooLarge is returned for corners that would unwarp into more
+// pixels than RectifyConfig.MaxPixels
+var ErrRectifiedTooLarge = errors.New("rectified logo would be too large")
+
+// RectifiedKey is where the rectified logo is stored, next to its raw crop
+func RectifiedKey(cropKey string) string {
+	return strings.TrimSuffix(cropKey, path.Ext(cropKey)) + "_rectified.png"
+}
+
+// Rectifier unwarps logos from their original images
+type Rectifier struct {
+	config        utils.RectifyConfig
+	store         db.Store
+	storageClient storage.Client
+}
+
+func NewRectifier(config utils.RectifyConfig, store db.Store, storageClient storage.Client) *Rectifier {
+	return &Rectifier{
+		config:        config,
+		store:         store,
+		storageClient: storageClient,
+	}
+}
+
+// Rectify unwarps the region of the logo's original image inside corners,
+// given in top-left, top-right, bottom-right, bottom-left order, uploads it
+// next to the raw crop and stores the corners and key on the logo. Corners
+// must lie inside the image, and the rectified logo within MaxPixels.
+func (r *Rectifier) Rectify(ctx context.Context, logo db.Logo, corners []models.Point) (db.Logo, error) {
+	if len(corners) != 4 {
+		return logo, fmt.Errorf("expected 4 corners, got %d", len(corners))
+	}
+	var quad [4]imaging.Point
+	for i, corner := range corners {
+		quad[i] = imaging.Point{X: corner.X, Y: corner.Y}
+	}
+
+	job, err := r.store.GetJob(ctx, logo.JobID)
+	if err != nil {
+		return logo, fmt.Errorf("failed to get job: %w", err)
+	}
+	body, err := r.storageClient.DownloadFile(ctx, job.S3Key)
+	if err != nil {
+		return logo, err
+	}
+	defer body.Close()
+
+	img, err := imaging.Decode(body)
+	if err != nil {
+		return logo, err
+	}
+
+	// Checked before sizing, so the edges are bounded by the image
+	if err := imaging.CheckCorners(quad, img.Bounds()); err != nil {
+		return logo, err
+	}
+	width, height := imaging.RectifiedSize(quad)
+	if int64(width)*int64(height) > r.config.MaxPixels {
+		return logo, fmt.Errorf("%w: %dx%d is over %d pixels", ErrRectifiedTooLarge, width, height, r.config.MaxPixels)
+	}
+	rectified, err := imaging.Unwarp(img, quad, width, height)
+	if err != nil {
+		return logo, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, rectified); err != nil {
+		return logo, fmt.Errorf("failed to encode rectified logo: %w", err)
+	}
+	key := RectifiedKey(logo.S3Key)
+	if _, err := r.storageClient.UploadFile(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		return logo, err
+	}
+
+	cornersJSON, err := json.Marshal(corners)
+	if err != nil {
+		return logo, fmt.Errorf("failed to marshal corners: %w", err)
+	}
+	return r.store.UpdateLogoRectification(ctx, db.UpdateLogoRectificationParams{
+		ID:             logo.ID,
+		Corners:        sql.NullString{String: string(cornersJSON), Valid: true},
+		RectifiedS3Key: sql.NullString{String: key, Valid: true},
+	})
+}
@@ -0,0 +1,64 @@
+package extraction
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"strings"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+)
+
+// VectorKey is where the SVG of a logo is stored, next to its raw crop
+func VectorKey(cropKey string) string {
+	return strings.TrimSuffix(cropKey, path.Ext(cropKey)) + "_vector.svg"
+}
+
+// Vectorizer traces extracted logos into SVG
+type Vectorizer struct {
+	store         db.Store
+	storageClient storage.Client
+	options       imaging.VectorOptions
+}
+
+func NewVectorizer(config utils.VectorConfig, store db.Store, storageClient storage.Client) *Vectorizer {
+	return &Vectorizer{
+		store:         store,
+		storageClient: storageClient,
+		options: imaging.VectorOptions{
+			Colors:    config.Colors,
+			Tolerance: config.Tolerance,
+			MinArea:   config.MinArea,
+		},
+	}
+}
+
+// Vectorize traces the logo's masked crop, or the raw crop when it has no
+// mask, and records the SVG on the logo
+func (v *Vectorizer) Vectorize(ctx context.Context, logo db.Logo) (db.Logo, error) {
+	key := logo.S3Key
+	if logo.MaskedS3Key.Valid {
+		key = logo.MaskedS3Key.String
+	}
+
+	crop, err := downloadImage(ctx, v.storageClient, key)
+	if err != nil {
+		return logo, fmt.Errorf("failed to load crop: %w", err)
+	}
+
+	svg := imaging.Vectorize(crop, v.options)
+	vectorKey := VectorKey(logo.S3Key)
+	if _, err := v.storageClient.UploadFile(ctx, vectorKey, bytes.NewReader(svg), int64(len(svg))); err != nil {
+		return logo, err
+	}
+
+	return v.store.UpdateLogoVector(ctx, db.UpdateLogoVectorParams{
+		ID:          logo.ID,
+		VectorS3Key: sql.NullString{String: vectorKey, Valid: true},
+	})
+}
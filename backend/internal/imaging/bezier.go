@@ -0,0 +1,171 @@
+package imaging
+
+import "math"
+
+const (
+	// bezierMaxIterations bounds the reparameterization rounds of a fit
+	bezierMaxIterations = 4
+	// bezierIterationFactor is how far above the tolerance a fit still
+	// gets reparameterized before the curve is split instead
+	bezierIterationFactor = 4
+)
+
+func (p Point) add(w Point) Point        { return Point{X: p.X + w.X, Y: p.Y + w.Y} }
+func (p Point) sub(w Point) Point        { return Point{X: p.X - w.X, Y: p.Y - w.Y} }
+func (p Point) scale(s float64) Point    { return Point{X: p.X * s, Y: p.Y * s} }
+func (p Point) dot(w Point) float64      { return p.X*w.X + p.Y*w.Y }
+func (p Point) length() float64          { return math.Hypot(p.X, p.Y) }
+func (p Point) distance(w Point) float64 { return p.sub(w).length() }
+
+func (p Point) normalize() Point {
+	if l := p.length(); l > 0 {
+		return p.scale(1 / l)
+	}
+	return p
+}
+
+// Bezier is a cubic Bézier curve from P0 to P3
+type Bezier struct {
+	P0, P1, P2, P3 Point
+}
+
+// At evaluates the curve at t in [0, 1]
+func (b Bezier) At(t float64) Point {
+	mt := 1 - t
+	return b.P0.scale(mt * mt * mt).
+		add(b.P1.scale(3 * mt * mt * t)).
+		add(b.P2.scale(3 * mt * t * t)).
+		add(b.P3.scale(t * t * t))
+}
+
+func (b Bezier) derivative(t float64) Point {
+	mt := 1 - t
+	return b.P1.sub(b.P0).scale(3 * mt * mt).
+		add(b.P2.sub(b.P1).scale(6 * mt * t)).
+		add(b.P3.sub(b.P2).scale(3 * t * t))
+}
+
+func (b Bezier) secondDerivative(t float64) Point {
+	return b.P2.sub(b.P1.scale(2)).add(b.P0).scale(6 * (1 - t)).
+		add(b.P3.sub(b.P2.scale(2)).add(b.P1).scale(6 * t))
+}
+
+// FitBezier fits cubic Bézier curves through points so that no point is
+// further than tolerance from the curves, using Schneider's algorithm from
+// Graphics Gems. leftTangent is the direction the curve leaves the first
+// point in, rightTangent the direction from the last point back into it.
+func FitBezier(points []Point, leftTangent, rightTangent Point, tolerance float64) []Bezier {
+	if len(points) < 2 {
+		return nil
+	}
+	if len(points) == 2 {
+		d := points[0].distance(points[1]) / 3
+		return []Bezier{{
+			P0: points[0],
+			P1: points[0].add(leftTangent.scale(d)),
+			P2: points[1].add(rightTangent.scale(d)),
+			P3: points[1],
+		}}
+	}
+
+	u := chordLengths(points)
+	curve := generateBezier(points, u, leftTangent, rightTangent)
+	maxError, split := maxFitError(points, curve, u)
+	if maxError <= tolerance*tolerance {
+		return []Bezier{curve}
+	}
+
+	if maxError <= tolerance*tolerance*bezierIterationFactor*bezierIterationFactor {
+		for i := 0; i < bezierMaxIterations; i++ {
+			u = reparameterize(points, u, curve)
+			curve = generateBezier(points, u, leftTangent, rightTangent)
+			maxError, split = maxFitError(points, curve, u)
+			if maxError <= tolerance*tolerance {
+				return []Bezier{curve}
+			}
+		}
+	}
+
+	center := points[split-1].sub(points[split+1]).normalize()
+	curves := FitBezier(points[:split+1], leftTangent, center, tolerance)
+	return append(curves, FitBezier(points[split:], center.scale(-1), rightTangent, tolerance)...)
+}
+
+// chordLengths parameterizes the points by their distance along the polyline
+func chordLengths(points []Point) []float64 {
+	u := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		u[i] = u[i-1] + points[i].distance(points[i-1])
+	}
+	if total := u[len(u)-1]; total > 0 {
+		for i := range u {
+			u[i] /= total
+		}
+	}
+	return u
+}
+
+// generateBezier finds the control points along the given end tangents
+// that fit the points best in the least-squares sense
+func generateBezier(points []Point, u []float64, leftTangent, rightTangent Point) Bezier {
+	first, last := points[0], points[len(points)-1]
+
+	var c00, c01, c11, x0, x1 float64
+	for i, p := range points {
+		t, mt := u[i], 1-u[i]
+		b0, b1, b2, b3 := mt*mt*mt, 3*mt*mt*t, 3*mt*t*t, t*t*t
+		a0, a1 := leftTangent.scale(b1), rightTangent.scale(b2)
+		c00 += a0.dot(a0)
+		c01 += a0.dot(a1)
+		c11 += a1.dot(a1)
+		rest := p.sub(first.scale(b0 + b1)).sub(last.scale(b2 + b3))
+		x0 += a0.dot(rest)
+		x1 += a1.dot(rest)
+	}
+
+	segment := first.distance(last)
+	alphaLeft, alphaRight := segment/3, segment/3
+	if det := c00*c11 - c01*c01; det != 0 {
+		left, right := (x0*c11-x1*c01)/det, (c00*x1-c01*x0)/det
+		// Negative or tiny handles make loops, fall back to the heuristic
+		if epsilon := 1e-6 * segment; left >= epsilon && right >= epsilon {
+			alphaLeft, alphaRight = left, right
+		}
+	}
+
+	return Bezier{
+		P0: first,
+		P1: first.add(leftTangent.scale(alphaLeft)),
+		P2: last.add(rightTangent.scale(alphaRight)),
+		P3: last,
+	}
+}
+
+// reparameterize moves each parameter to the closest point on the curve
+// with one Newton-Raphson step
+func reparameterize(points []Point, u []float64, curve Bezier) []float64 {
+	next := make([]float64, len(u))
+	for i, p := range points {
+		diff := curve.At(u[i]).sub(p)
+		d1, d2 := curve.derivative(u[i]), curve.secondDerivative(u[i])
+		denominator := d1.dot(d1) + diff.dot(d2)
+		next[i] = u[i]
+		if denominator != 0 {
+			next[i] = u[i] - diff.dot(d1)/denominator
+		}
+	}
+	return next
+}
+
+// maxFitError returns the largest squared distance between a point and the
+// curve, and the index of that point to split at
+func maxFitError(points []Point, curve Bezier, u []float64) (float64, int) {
+	maxError, split := 0.0, len(points)/2
+	for i := 1; i < len(points)-1; i++ {
+		diff := curve.At(u[i]).sub(points[i])
+		if d := diff.dot(diff); d >= maxError {
+			maxError, split = d, i
+		}
+	}
+	return maxError, split
+}
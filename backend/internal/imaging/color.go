@@ -0,0 +1,61 @@
+package imaging
+
+import (
+	"image"
+)
+
+const (
+	// histogramBins is the number of bins per RGB channel
+	histogramBins = 4
+	// maxHistogramSamples bounds the pixels sampled along each axis
+	maxHistogramSamples = 256
+)
+
+// ColorHistogram returns a normalized RGB histogram with histogramBins bins
+// per channel. Pixels are weighted by their alpha, so the transparent
+// background of a crop does not count.
+func ColorHistogram(img image.Image) []float64 {
+	histogram := make([]float64, histogramBins*histogramBins*histogramBins)
+	bounds := img.Bounds()
+	stepX := max(1, bounds.Dx()/maxHistogramSamples)
+	stepY := max(1, bounds.Dy()/maxHistogramSamples)
+
+	total := 0.0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a == 0 {
+				continue
+			}
+			// Colors are alpha-premultiplied, undo it before binning
+			r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+			bin := int(r*histogramBins/0x10000)*histogramBins*histogramBins +
+				int(g*histogramBins/0x10000)*histogramBins +
+				int(b*histogramBins/0x10000)
+			weight := float64(a) / 0xffff
+			histogram[bin] += weight
+			total += weight
+		}
+	}
+
+	if total > 0 {
+		for i := range histogram {
+			histogram[i] /= total
+		}
+	}
+	return histogram
+}
+
+// HistogramIntersection returns how much two normalized histograms overlap,
+// from 0 (no shared colors) to 1 (identical distributions)
+func HistogramIntersection(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	overlap := 0.0
+	for i := range a {
+		overlap += min(a[i], b[i])
+	}
+	return overlap
+}
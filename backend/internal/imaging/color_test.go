@@ -0,0 +1,40 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/draw"
+)
+
+func TestColorHistogramIgnoresTransparentPixels(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if x < 2 {
+				img.Set(x, y, color.NRGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.NRGBA{G: 255, A: 0})
+			}
+		}
+	}
+
+	histogram := ColorHistogram(img)
+	red := (histogramBins - 1) * histogramBins * histogramBins
+
+	require.Len(t, histogram, histogramBins*histogramBins*histogramBins)
+	require.InDelta(t, 1.0, histogram[red], 1e-9)
+}
+
+func TestHistogramIntersection(t *testing.T) {
+	logo := testLogo(64)
+	green := image.NewUniform(color.RGBA{G: 200, A: 255})
+	greenImage := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	draw.Draw(greenImage, greenImage.Bounds(), green, image.Point{}, draw.Src)
+
+	require.InDelta(t, 1.0, HistogramIntersection(ColorHistogram(logo), ColorHistogram(logo)), 1e-9)
+	require.Less(t, HistogramIntersection(ColorHistogram(logo), ColorHistogram(greenImage)), 0.1)
+	require.Zero(t, HistogramIntersection([]float64{1}, []float64{0.5, 0.5}))
+}
@@ -0,0 +1,47 @@
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// Decode reads a JPEG or PNG image
+func Decode(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// DecodeConfig reads the dimensions of a JPEG or PNG image without decoding its pixels
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return config, fmt.Errorf("failed to decode image header: %w", err)
+	}
+	return config, nil
+}
+
+// ErrTooManyPixels is returned by CheckPixels for images above the limit
+var ErrTooManyPixels = errors.New("image has too many pixels")
+
+// CheckPixels reads the dimensions of an image and rewinds it. Images
+// declaring more than maxPixels are rejected before anything decodes them.
+func CheckPixels(r io.ReadSeeker, maxPixels int64) (image.Config, error) {
+	config, err := DecodeConfig(r)
+	if err != nil {
+		return config, err
+	}
+	if int64(config.Width)*int64(config.Height) > maxPixels {
+		return config, fmt.Errorf("%w: %dx%d is more than %d pixels", ErrTooManyPixels, config.Width, config.Height, maxPixels)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return config, err
+	}
+	return config, nil
+}
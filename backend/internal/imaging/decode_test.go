@@ -0,0 +1,33 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPixels(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 40, 30))))
+	file := bytes.NewReader(buf.Bytes())
+
+	config, err := CheckPixels(file, 1200)
+	require.NoError(t, err)
+	require.Equal(t, 40, config.Width)
+	require.Equal(t, 30, config.Height)
+
+	// The image is rewound for whoever reads it next
+	body, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.Equal(t, buf.Bytes(), body)
+
+	_, err = CheckPixels(bytes.NewReader(buf.Bytes()), 1199)
+	require.ErrorIs(t, err, ErrTooManyPixels)
+
+	_, err = CheckPixels(bytes.NewReader([]byte("not an image")), 1200)
+	require.Error(t, err)
+}
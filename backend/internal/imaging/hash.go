@@ -0,0 +1,115 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"sort"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// pHashSize is the side of the downscaled image the DCT is computed on
+	pHashSize = 32
+	// hashSide is the side of the low-frequency block kept in a pHash
+	hashSide = 8
+)
+
+// dctCosines[u][x] holds cos((2x+1)uπ / 2N) for the pHash DCT
+var dctCosines = func() [pHashSize][pHashSize]float64 {
+	var table [pHashSize][pHashSize]float64
+	for u := 0; u < pHashSize; u++ {
+		for x := 0; x < pHashSize; x++ {
+			table[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * pHashSize))
+		}
+	}
+	return table
+}()
+
+// DHash computes a 64-bit difference hash. Each bit tells whether a pixel of
+// the 9x8 grayscale thumbnail is brighter than its right neighbour.
+func DHash(img image.Image) uint64 {
+	pixels := Grayscale(img, hashSide+1, hashSide)
+
+	var hash uint64
+	for y := 0; y < hashSide; y++ {
+		for x := 0; x < hashSide; x++ {
+			hash <<= 1
+			if pixels[y*(hashSide+1)+x] > pixels[y*(hashSide+1)+x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// PHash computes a 64-bit perceptual hash from the lowest frequencies of the
+// DCT of a 32x32 grayscale thumbnail. Each bit tells whether a coefficient is
+// above the median, which makes the hash robust to resizing and recompression.
+func PHash(img image.Image) uint64 {
+	pixels := Grayscale(img, pHashSize, pHashSize)
+
+	// Separable 2D DCT-II, only the low-frequency rows and columns are needed
+	var rows [pHashSize][hashSide]float64
+	for y := 0; y < pHashSize; y++ {
+		for u := 0; u < hashSide; u++ {
+			sum := 0.0
+			for x := 0; x < pHashSize; x++ {
+				sum += pixels[y*pHashSize+x] * dctCosines[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+
+	coefficients := make([]float64, 0, hashSide*hashSide)
+	for v := 0; v < hashSide; v++ {
+		for u := 0; u < hashSide; u++ {
+			sum := 0.0
+			for y := 0; y < pHashSize; y++ {
+				sum += rows[y][u] * dctCosines[v][y]
+			}
+			coefficients = append(coefficients, sum)
+		}
+	}
+
+	// The DC term only carries the average brightness, leave it out of the median
+	sorted := append([]float64(nil), coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var hash uint64
+	for _, coefficient := range coefficients {
+		hash <<= 1
+		if coefficient > median {
+			hash |= 1
+		}
+	}
+	return hash
+}
+
+// Distance returns the Hamming distance between two hashes
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Grayscale flattens img onto white, scales it to width x height and returns
+// its luma row by row
+func Grayscale(img image.Image, width, height int) []float64 {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Over)
+
+	thumbnail := image.NewGray(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), flat, flat.Bounds(), draw.Src, nil)
+
+	pixels := make([]float64, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixels = append(pixels, float64(thumbnail.GrayAt(x, y).Y))
+		}
+	}
+	return pixels
+}
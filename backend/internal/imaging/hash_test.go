@@ -0,0 +1,90 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/draw"
+)
+
+// testLogo draws a simple logo-like shape: a dark disc with a bar on a light background
+func testLogo(size int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	center, radius := size/2, size/3
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := color.RGBA{R: 240, G: 240, B: 230, A: 255}
+			dx, dy := x-center, y-center
+			if dx*dx+dy*dy < radius*radius {
+				c = color.RGBA{R: 20, G: 40, B: 160, A: 255}
+			}
+			if y > size*3/4 && x > size/8 && x < size*7/8 {
+				c = color.RGBA{R: 200, G: 30, B: 30, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// testStripes draws vertical stripes, which look nothing like testLogo
+func testStripes(size int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if (x/(size/8))%2 == 0 {
+				c = color.RGBA{A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func resized(img image.Image, size int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.BiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+func recompressed(t *testing.T, img image.Image, quality int) image.Image {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}))
+	decoded, err := Decode(&buf)
+	require.NoError(t, err)
+	return decoded
+}
+
+func TestHashesAreStableUnderResizeAndRecompression(t *testing.T) {
+	original := testLogo(256)
+	variants := map[string]image.Image{
+		"downscaled":   resized(original, 97),
+		"upscaled":     resized(original, 400),
+		"recompressed": recompressed(t, original, 40),
+	}
+
+	for name, variant := range variants {
+		t.Run(name, func(t *testing.T) {
+			require.LessOrEqual(t, Distance(PHash(original), PHash(variant)), 6)
+			require.LessOrEqual(t, Distance(DHash(original), DHash(variant)), 6)
+		})
+	}
+}
+
+func TestHashesSeparateDifferentImages(t *testing.T) {
+	logo, stripes := testLogo(128), testStripes(128)
+
+	require.Greater(t, Distance(PHash(logo), PHash(stripes)), 16)
+	require.Greater(t, Distance(DHash(logo), DHash(stripes)), 16)
+}
+
+func TestDistance(t *testing.T) {
+	require.Equal(t, 0, Distance(0xFF, 0xFF))
+	require.Equal(t, 64, Distance(0, ^uint64(0)))
+	require.Equal(t, 2, Distance(0b1010, 0b0110))
+}
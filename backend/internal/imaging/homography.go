@@ -0,0 +1,178 @@
+package imaging
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"math"
+)
+
+// ErrDegenerateCorners is returned for corners that do not form a convex quadrilateral
+var ErrDegenerateCorners = errors.New("corners must form a convex quadrilateral")
+
+// ErrCornersOutOfBounds is returned for corners outside the image they outline
+var ErrCornersOutOfBounds = errors.New("corners must lie inside the image")
+
+// Point is a position in image pixels
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Homography is a projective transform between two planes, as a row-major
+// 3x3 matrix
+type Homography [9]float64
+
+// Apply maps a point through the homography
+func (h Homography) Apply(p Point) Point {
+	w := h[6]*p.X + h[7]*p.Y + h[8]
+	return Point{
+		X: (h[0]*p.X + h[1]*p.Y + h[2]) / w,
+		Y: (h[3]*p.X + h[4]*p.Y + h[5]) / w,
+	}
+}
+
+// EstimateHomography finds the homography that maps every src point to the
+// dst point at the same index with the direct linear transform, fixing the
+// bottom-right entry to 1
+func EstimateHomography(src, dst [4]Point) (Homography, error) {
+	var a [8][9]float64
+	for i := range src {
+		x, y, u, v := src[i].X, src[i].Y, dst[i].X, dst[i].Y
+		a[2*i] = [9]float64{x, y, 1, 0, 0, 0, -u * x, -u * y, u}
+		a[2*i+1] = [9]float64{0, 0, 0, x, y, 1, -v * x, -v * y, v}
+	}
+
+	// Gaussian elimination with partial pivoting on the augmented matrix
+	for col := 0; col < 8; col++ {
+		pivot := col
+		for row := col + 1; row < 8; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return Homography{}, ErrDegenerateCorners
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+
+		for row := 0; row < 8; row++ {
+			if row == col {
+				continue
+			}
+			factor := a[row][col] / a[col][col]
+			for k := col; k < 9; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+		}
+	}
+
+	var h Homography
+	for i := 0; i < 8; i++ {
+		h[i] = a[i][8] / a[i][i]
+	}
+	h[8] = 1
+	return h, nil
+}
+
+// CheckCorners reports ErrCornersOutOfBounds unless every corner lies within
+// bounds, in coordinates relative to its top-left
+func CheckCorners(corners [4]Point, bounds image.Rectangle) error {
+	for _, corner := range corners {
+		// Written so NaN fails too
+		if !(corner.X >= 0 && corner.Y >= 0 && corner.X <= float64(bounds.Dx()) && corner.Y <= float64(bounds.Dy())) {
+			return ErrCornersOutOfBounds
+		}
+	}
+	return nil
+}
+
+// RectifiedSize is the size of the rectangle the corners are unwarped to:
+// the longer of each pair of opposite edges
+func RectifiedSize(corners [4]Point) (width, height int) {
+	edge := func(a, b Point) float64 { return math.Hypot(b.X-a.X, b.Y-a.Y) }
+	width = int(math.Round(max(edge(corners[0], corners[1]), edge(corners[3], corners[2]))))
+	height = int(math.Round(max(edge(corners[0], corners[3]), edge(corners[1], corners[2]))))
+	return max(width, 1), max(height, 1)
+}
+
+// Unwarp resamples the quadrilateral of img with the given corners, in
+// top-left, top-right, bottom-right, bottom-left order, into a flat
+// width x height image with bilinear interpolation. Pixels that fall outside
+// img are transparent. Corners outside img are rejected, callers bound width
+// and height themselves.
+func Unwarp(img image.Image, corners [4]Point, width, height int) (*image.RGBA, error) {
+	if err := CheckCorners(corners, img.Bounds()); err != nil {
+		return nil, err
+	}
+	if !convex(corners) {
+		return nil, ErrDegenerateCorners
+	}
+
+	// Map output pixels back into the source, so every output pixel is sampled
+	rect := [4]Point{{0, 0}, {float64(width), 0}, {float64(width), float64(height)}, {0, float64(height)}}
+	h, err := EstimateHomography(rect, corners)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := h.Apply(Point{X: float64(x) + 0.5, Y: float64(y) + 0.5})
+			i := out.PixOffset(x, y)
+			copy(out.Pix[i:i+4], bilinear(src, p.X-0.5, p.Y-0.5))
+		}
+	}
+	return out, nil
+}
+
+// bilinear interpolates the premultiplied color of img at x, y in pixel
+// index coordinates, treating everything outside img as transparent
+func bilinear(img *image.RGBA, x, y float64) []uint8 {
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	var channels [4]float64
+	for _, corner := range [4]struct {
+		dx, dy int
+		weight float64
+	}{
+		{0, 0, (1 - fx) * (1 - fy)},
+		{1, 0, fx * (1 - fy)},
+		{0, 1, (1 - fx) * fy},
+		{1, 1, fx * fy},
+	} {
+		px, py := x0+corner.dx, y0+corner.dy
+		if !(image.Point{X: px, Y: py}.In(img.Rect)) {
+			continue
+		}
+		i := img.PixOffset(px, py)
+		for c := range channels {
+			channels[c] += float64(img.Pix[i+c]) * corner.weight
+		}
+	}
+	return []uint8{
+		uint8(math.Round(channels[0])), uint8(math.Round(channels[1])),
+		uint8(math.Round(channels[2])), uint8(math.Round(channels[3])),
+	}
+}
+
+// convex reports whether the corners, in order, turn the same way at every
+// corner
+func convex(corners [4]Point) bool {
+	sign := 0.0
+	for i := range corners {
+		a, b, c := corners[i], corners[(i+1)%4], corners[(i+2)%4]
+		cross := (b.X-a.X)*(c.Y-b.Y) - (b.Y-a.Y)*(c.X-b.X)
+		if math.Abs(cross) < 1e-9 || cross*sign < 0 {
+			return false
+		}
+		sign = cross
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateHomography(t *testing.T) {
+	src := [4]Point{{0, 0}, {100, 0}, {100, 50}, {0, 50}}
+	dst := [4]Point{{10, 20}, {120, 10}, {130, 90}, {5, 70}}
+
+	h, err := EstimateHomography(src, dst)
+	require.NoError(t, err)
+	for i := range src {
+		p := h.Apply(src[i])
+		require.InDelta(t, dst[i].X, p.X, 1e-6)
+		require.InDelta(t, dst[i].Y, p.Y, 1e-6)
+	}
+
+	_, err = EstimateHomography(src, [4]Point{{0, 0}, {1, 1}, {2, 2}, {3, 3}})
+	require.ErrorIs(t, err, ErrDegenerateCorners)
+}
+
+func TestUnwarp(t *testing.T) {
+	// A red square on white, seen as a rotated diamond
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if abs(x-50)+abs(y-50) < 30 {
+				c = color.RGBA{R: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	corners := [4]Point{{50, 20}, {80, 50}, {50, 80}, {20, 50}}
+
+	width, height := RectifiedSize(corners)
+	require.Equal(t, 42, width)
+	require.Equal(t, 42, height)
+
+	rectified, err := Unwarp(img, corners, width, height)
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 42, 42), rectified.Bounds())
+	// The diamond fills the rectangle
+	for _, p := range []image.Point{{21, 21}, {3, 3}, {38, 3}, {3, 38}, {38, 38}} {
+		c := rectified.RGBAAt(p.X, p.Y)
+		require.Equal(t, uint8(255), c.R, p)
+		require.Less(t, c.G, uint8(64), p)
+	}
+
+	_, err = Unwarp(img, [4]Point{{0, 0}, {50, 50}, {50, 0}, {0, 50}}, 10, 10)
+	require.ErrorIs(t, err, ErrDegenerateCorners)
+}
+
+func TestCheckCorners(t *testing.T) {
+	bounds := image.Rect(10, 10, 110, 60)
+	require.NoError(t, CheckCorners([4]Point{{0, 0}, {100, 0}, {100, 50}, {0, 50}}, bounds))
+	require.ErrorIs(t, CheckCorners([4]Point{{0, 0}, {1e12, 0}, {1e12, 1e12}, {0, 1e12}}, bounds), ErrCornersOutOfBounds)
+	require.ErrorIs(t, CheckCorners([4]Point{{-1, 0}, {100, 0}, {100, 50}, {0, 50}}, bounds), ErrCornersOutOfBounds)
+	require.ErrorIs(t, CheckCorners([4]Point{{0, 0}, {math.NaN(), 0}, {100, 50}, {0, 50}}, bounds), ErrCornersOutOfBounds)
+
+	_, err := Unwarp(image.NewRGBA(bounds), [4]Point{{0, 0}, {101, 0}, {100, 50}, {0, 50}}, 10, 10)
+	require.ErrorIs(t, err, ErrCornersOutOfBounds)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,222 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"slices"
+
+	"golang.org/x/image/draw"
+)
+
+// EstimateBackground returns the per-channel median color of the border
+// pixels of img, which for a crop is mostly the surface behind the logo
+func EstimateBackground(img image.Image) color.RGBA {
+	bounds := img.Bounds()
+	var rs, gs, bs []uint8
+	add := func(x, y int) {
+		c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+		rs, gs, bs = append(rs, c.R), append(gs, c.G), append(bs, c.B)
+	}
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		add(x, bounds.Min.Y)
+		if bounds.Dy() > 1 {
+			add(x, bounds.Max.Y-1)
+		}
+	}
+	for y := bounds.Min.Y + 1; y < bounds.Max.Y-1; y++ {
+		add(bounds.Min.X, y)
+		if bounds.Dx() > 1 {
+			add(bounds.Max.X-1, y)
+		}
+	}
+	if len(rs) == 0 {
+		return color.RGBA{}
+	}
+	median := func(values []uint8) uint8 {
+		slices.Sort(values)
+		return values[len(values)/2]
+	}
+	return color.RGBA{R: median(rs), G: median(gs), B: median(bs), A: 255}
+}
+
+// GenerateMask separates the logo in img from its background. Pixels are
+// scored by their color distance to the background estimated from the
+// border, split with Otsu's threshold and given a soft edge. It returns the
+// mask and its quality.
+func GenerateMask(img image.Image) (*image.Alpha, float64) {
+	bounds := img.Bounds()
+	distances := backgroundDistances(img, EstimateBackground(img))
+
+	threshold := otsuThreshold(distances)
+	// Distances within band of the threshold fade in, so edges are not jagged
+	band := max(4, threshold/4)
+
+	mask := image.NewAlpha(bounds)
+	for i, d := range distances {
+		alpha := (float64(d) - (threshold - band)) / (2 * band)
+		mask.Pix[i] = uint8(math.Round(255 * min(max(alpha, 0), 1)))
+	}
+	refineEdges(mask)
+
+	return mask, MaskQuality(img, mask)
+}
+
+// MaskFromImage turns a segmentation mask, such as one from SAM, into an
+// alpha mask of the given size. White, opaque pixels are foreground.
+func MaskFromImage(src image.Image, bounds image.Rectangle) *image.Alpha {
+	gray := image.NewGray(src.Bounds())
+	for y := src.Bounds().Min.Y; y < src.Bounds().Max.Y; y++ {
+		for x := src.Bounds().Min.X; x < src.Bounds().Max.X; x++ {
+			// Gray conversion of a premultiplied color also accounts for alpha
+			gray.Set(x, y, color.GrayModel.Convert(src.At(x, y)))
+		}
+	}
+
+	resized := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.BiLinear.Scale(resized, resized.Bounds(), gray, gray.Bounds(), draw.Src, nil)
+
+	mask := image.NewAlpha(bounds)
+	copy(mask.Pix, resized.Pix)
+	return mask
+}
+
+// MaskQuality scores from 0 to 1 how cleanly mask separates the logo from
+// the background: the share of the variance in background distance that lies
+// between the masked and unmasked pixels, reduced by the share of border
+// pixels the mask keeps
+func MaskQuality(img image.Image, mask *image.Alpha) float64 {
+	distances := backgroundDistances(img, EstimateBackground(img))
+	bounds := mask.Rect
+	width := bounds.Dx()
+	if len(distances) == 0 || len(distances) != len(mask.Pix) {
+		return 0
+	}
+
+	var n [2]float64
+	var sum [2]float64
+	var total, totalSquares float64
+	borderPixels, borderForeground := 0, 0
+	for i, d := range distances {
+		value := float64(d)
+		class := 0
+		if mask.Pix[i] >= 128 {
+			class = 1
+		}
+		n[class]++
+		sum[class] += value
+		total += value
+		totalSquares += value * value
+
+		x, y := i%width, i/width
+		if x == 0 || y == 0 || x == width-1 || y == bounds.Dy()-1 {
+			borderPixels++
+			borderForeground += class
+		}
+	}
+
+	count := float64(len(distances))
+	mean := total / count
+	variance := totalSquares/count - mean*mean
+	if n[0] == 0 || n[1] == 0 || variance <= 0 {
+		return 0
+	}
+	w0, w1 := n[0]/count, n[1]/count
+	diff := sum[1]/n[1] - sum[0]/n[0]
+	separability := min(w0*w1*diff*diff/variance, 1)
+
+	return separability * (1 - float64(borderForeground)/float64(borderPixels))
+}
+
+// ApplyMask returns img with the mask as its alpha channel
+func ApplyMask(img image.Image, mask *image.Alpha) *image.NRGBA {
+	bounds := img.Bounds()
+	out := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			c.A = uint8(uint16(c.A) * uint16(mask.AlphaAt(x, y).A) / 255)
+			out.SetNRGBA(x, y, c)
+		}
+	}
+	return out
+}
+
+// backgroundDistances returns the color distance of every pixel of img to
+// background, scaled to 0-255, in row-major order
+func backgroundDistances(img image.Image, background color.RGBA) []uint8 {
+	bounds := img.Bounds()
+	distances := make([]uint8, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			dr := float64(c.R) - float64(background.R)
+			dg := float64(c.G) - float64(background.G)
+			db := float64(c.B) - float64(background.B)
+			distances = append(distances, uint8(math.Round(math.Sqrt((dr*dr+dg*dg+db*db)/3))))
+		}
+	}
+	return distances
+}
+
+// otsuThreshold returns the threshold that maximizes the between-class
+// variance of values
+func otsuThreshold(values []uint8) float64 {
+	var histogram [256]float64
+	var sum float64
+	for _, v := range values {
+		histogram[v]++
+		sum += float64(v)
+	}
+
+	count := float64(len(values))
+	var weight, partialSum, bestVariance float64
+	// Between separated clusters every threshold is equally good, take the middle
+	best, bestEnd := 0, 0
+	for t := 0; t < 256; t++ {
+		weight += histogram[t]
+		if weight == 0 || weight == count {
+			continue
+		}
+		partialSum += float64(t) * histogram[t]
+		mean0 := partialSum / weight
+		mean1 := (sum - partialSum) / (count - weight)
+		variance := weight * (count - weight) * (mean0 - mean1) * (mean0 - mean1)
+		switch {
+		case variance > bestVariance*(1+1e-9):
+			best, bestEnd, bestVariance = t, t, variance
+		case variance >= bestVariance*(1-1e-9):
+			bestEnd = t
+		}
+	}
+	// Values above the threshold are foreground
+	return float64(best+bestEnd)/2 + 0.5
+}
+
+// refineEdges smooths the mask where it changes between neighbors, leaving
+// solid regions untouched
+func refineEdges(mask *image.Alpha) {
+	bounds := mask.Rect
+	original := slices.Clone(mask.Pix)
+	width, height := bounds.Dx(), bounds.Dy()
+	at := func(x, y int) int {
+		x = min(max(x, 0), width-1)
+		y = min(max(y, 0), height-1)
+		return int(original[y*width+x])
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			lowest, highest, sum := 255, 0, 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					v := at(x+dx, y+dy)
+					lowest, highest, sum = min(lowest, v), max(highest, v), sum+v
+				}
+			}
+			if lowest != highest {
+				mask.Pix[y*width+x] = uint8((sum + 4) / 9)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fabricWithLogo draws a dark disc on a light, slightly noisy surface
+func fabricWithLogo(size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	center, radius := size/2, size/4
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			shade := uint8(200 + (x*7+y*13)%10)
+			c := color.RGBA{R: shade, G: shade, B: shade - 20, A: 255}
+			dx, dy := x-center, y-center
+			if dx*dx+dy*dy < radius*radius {
+				c = color.RGBA{R: 20, G: 40, B: 160, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestEstimateBackground(t *testing.T) {
+	background := EstimateBackground(fabricWithLogo(64))
+	require.InDelta(t, 204, int(background.R), 5)
+	require.InDelta(t, 184, int(background.B), 5)
+}
+
+func TestGenerateMask(t *testing.T) {
+	img := fabricWithLogo(64)
+
+	mask, quality := GenerateMask(img)
+	require.Equal(t, uint8(255), mask.AlphaAt(32, 32).A)
+	require.Equal(t, uint8(0), mask.AlphaAt(2, 2).A)
+	require.Greater(t, quality, 0.8)
+
+	masked := ApplyMask(img, mask)
+	require.Equal(t, color.NRGBA{R: 20, G: 40, B: 160, A: 255}, masked.NRGBAAt(32, 32))
+	require.Zero(t, masked.NRGBAAt(2, 2).A)
+
+	// A plain crop has nothing to separate
+	_, quality = GenerateMask(image.NewGray(image.Rect(0, 0, 16, 16)))
+	require.Zero(t, quality)
+}
+
+func TestMaskFromImage(t *testing.T) {
+	sam := image.NewGray(image.Rect(0, 0, 32, 32))
+	for y := 8; y < 24; y++ {
+		for x := 8; x < 24; x++ {
+			sam.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+
+	mask := MaskFromImage(sam, image.Rect(0, 0, 64, 64))
+	require.Equal(t, image.Rect(0, 0, 64, 64), mask.Bounds())
+	require.Equal(t, uint8(255), mask.AlphaAt(32, 32).A)
+	require.Equal(t, uint8(0), mask.AlphaAt(4, 4).A)
+
+	require.Greater(t, MaskQuality(fabricWithLogo(64), mask), 0.5)
+}
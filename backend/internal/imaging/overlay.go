@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// OverlayBox is a detection to draw on an overlay
+type OverlayBox struct {
+	Rect       image.Rectangle
+	Label      string
+	Confidence float64
+}
+
+// ConfidenceColor goes from red for a confidence of 0 through yellow to
+// green for a confidence of 1
+func ConfidenceColor(confidence float64) color.RGBA {
+	confidence = min(max(confidence, 0), 1)
+	if confidence < 0.5 {
+		return color.RGBA{R: 220, G: uint8(440 * confidence), A: 255}
+	}
+	return color.RGBA{R: uint8(440 * (1 - confidence)), G: 220, A: 255}
+}
+
+// DrawOverlay returns a copy of img with every box outlined in the color of
+// its confidence and labeled with its label and confidence
+func DrawOverlay(img image.Image, boxes []OverlayBox) *image.RGBA {
+	bounds := img.Bounds()
+	canvas := image.NewRGBA(bounds)
+	draw.Draw(canvas, bounds, img, bounds.Min, draw.Src)
+
+	// Keep outlines visible on large images
+	thickness := max(2, min(bounds.Dx(), bounds.Dy())/300)
+	face := basicfont.Face7x13
+
+	for _, box := range boxes {
+		rect := box.Rect.Add(bounds.Min).Intersect(bounds)
+		if rect.Empty() {
+			continue
+		}
+		fill := image.NewUniform(ConfidenceColor(box.Confidence))
+		for i := 0; i < thickness; i++ {
+			outline := rect.Inset(i)
+			if outline.Empty() {
+				break
+			}
+			drawRect(canvas, outline, fill)
+		}
+
+		label := fmt.Sprintf("%s %.0f%%", box.Label, box.Confidence*100)
+		width := font.MeasureString(face, label).Ceil() + 4
+		height := face.Metrics().Height.Ceil() + 2
+		// Above the box, or inside it when the box touches the top edge
+		top := rect.Min.Y - height
+		if top < bounds.Min.Y {
+			top = rect.Min.Y
+		}
+		background := image.Rect(rect.Min.X, top, rect.Min.X+width, top+height).Intersect(bounds)
+		draw.Draw(canvas, background, fill, image.Point{}, draw.Src)
+
+		drawer := font.Drawer{
+			Dst:  canvas,
+			Src:  image.White,
+			Face: face,
+			Dot:  fixed.P(rect.Min.X+2, top+face.Metrics().Ascent.Ceil()+1),
+		}
+		drawer.DrawString(label)
+	}
+	return canvas
+}
+
+// drawRect draws the one pixel wide outline of rect
+func drawRect(dst draw.Image, rect image.Rectangle, src image.Image) {
+	edges := []image.Rectangle{
+		image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+1),
+		image.Rect(rect.Min.X, rect.Max.Y-1, rect.Max.X, rect.Max.Y),
+		image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+1, rect.Max.Y),
+		image.Rect(rect.Max.X-1, rect.Min.Y, rect.Max.X, rect.Max.Y),
+	}
+	for _, edge := range edges {
+		draw.Draw(dst, edge, src, image.Point{}, draw.Src)
+	}
+}
@@ -0,0 +1,33 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfidenceColor(t *testing.T) {
+	require.Equal(t, color.RGBA{R: 220, A: 255}, ConfidenceColor(0))
+	require.Equal(t, color.RGBA{R: 220, G: 220, A: 255}, ConfidenceColor(0.5))
+	require.Equal(t, color.RGBA{G: 220, A: 255}, ConfidenceColor(1))
+	require.Equal(t, ConfidenceColor(1), ConfidenceColor(3))
+}
+
+func TestDrawOverlay(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	box := OverlayBox{Rect: image.Rect(50, 40, 150, 90), Label: "text", Confidence: 0.9}
+
+	overlay := DrawOverlay(img, []OverlayBox{box, {Rect: image.Rect(300, 300, 400, 400), Label: "outside"}})
+
+	boxColor := ConfidenceColor(0.9)
+	require.Equal(t, boxColor, overlay.RGBAAt(100, 40))
+	require.Equal(t, boxColor, overlay.RGBAAt(50, 60))
+	require.Equal(t, boxColor, overlay.RGBAAt(149, 89))
+	// The label sits above the box
+	require.Equal(t, boxColor, overlay.RGBAAt(51, 30))
+	require.Equal(t, color.RGBA{}, overlay.RGBAAt(100, 65))
+	// The source image is left untouched
+	require.Equal(t, color.RGBA{}, img.RGBAAt(100, 40))
+}
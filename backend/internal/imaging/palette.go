@@ -0,0 +1,389 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxPaletteSamples bounds the pixels sampled along each axis
+	maxPaletteSamples = 128
+	// kMeansIterations bounds the refinement rounds of the clustering
+	kMeansIterations = 20
+)
+
+// D65 reference white in XYZ
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// Lab is a color in CIE L*a*b* with a D65 white point
+type Lab struct {
+	L float64
+	A float64
+	B float64
+}
+
+// PaletteColor is one dominant color and the share of the logo's pixels
+// that belong to it
+type PaletteColor struct {
+	Color      Lab
+	Proportion float64
+}
+
+// ColorMatch pairs a color with the index of its nearest color in the
+// other palette and their CIEDE2000 distance
+type ColorMatch struct {
+	Nearest int
+	DeltaE  float64
+}
+
+// PaletteComparison reports how far a logo's palette is from a reference
+type PaletteComparison struct {
+	// ReferenceMatches has the nearest palette color for each reference color
+	ReferenceMatches []ColorMatch
+	// PaletteMatches has the nearest reference color for each palette color
+	PaletteMatches []ColorMatch
+	// MeanDeltaE is the palette's distance to the reference, weighted by proportion
+	MeanDeltaE float64
+	// MaxDeltaE is the largest distance in either direction
+	MaxDeltaE float64
+}
+
+// srgbToLinear maps every 8-bit sRGB value to linear light
+var srgbToLinear = func() [256]float64 {
+	var table [256]float64
+	for i := range table {
+		c := float64(i) / 255
+		if c <= 0.04045 {
+			table[i] = c / 12.92
+		} else {
+			table[i] = math.Pow((c+0.055)/1.055, 2.4)
+		}
+	}
+	return table
+}()
+
+// LabFromRGB converts an 8-bit sRGB color to Lab
+func LabFromRGB(r, g, b uint8) Lab {
+	lr, lg, lb := srgbToLinear[r], srgbToLinear[g], srgbToLinear[b]
+	x := (0.4124564*lr + 0.3575761*lg + 0.1804375*lb) / whiteX
+	y := (0.2126729*lr + 0.7151522*lg + 0.0721750*lb) / whiteY
+	z := (0.0193339*lr + 0.1191920*lg + 0.9503041*lb) / whiteZ
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+	return Lab{L: 116*fy - 16, A: 500 * (fx - fy), B: 200 * (fy - fz)}
+}
+
+// ParseHexColor parses a #rgb or #rrggbb sRGB color into Lab
+func ParseHexColor(hex string) (Lab, error) {
+	digits := strings.TrimPrefix(hex, "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	if len(digits) != 6 {
+		return Lab{}, fmt.Errorf("invalid hex color %q", hex)
+	}
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return Lab{}, fmt.Errorf("invalid hex color %q", hex)
+	}
+	return LabFromRGB(uint8(value>>16), uint8(value>>8), uint8(value)), nil
+}
+
+// RGB converts the color back to 8-bit sRGB, clamping colors outside the gamut
+func (c Lab) RGB() (r, g, b uint8) {
+	fy := (c.L + 16) / 116
+	fx := fy + c.A/500
+	fz := fy - c.B/200
+	x, y, z := labFInverse(fx)*whiteX, labFInverse(fy)*whiteY, labFInverse(fz)*whiteZ
+
+	lr := 3.2404542*x - 1.5371385*y - 0.4985314*z
+	lg := -0.9692660*x + 1.8760108*y + 0.0415560*z
+	lb := 0.0556434*x - 0.2040259*y + 1.0572252*z
+	return linearToSRGB(lr), linearToSRGB(lg), linearToSRGB(lb)
+}
+
+// Hex formats the color as #rrggbb
+func (c Lab) Hex() string {
+	r, g, b := c.RGB()
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+func labF(t float64) float64 {
+	const delta = 6.0 / 29
+	if t > delta*delta*delta {
+		return math.Cbrt(t)
+	}
+	return t/(3*delta*delta) + 4.0/29
+}
+
+func labFInverse(t float64) float64 {
+	const delta = 6.0 / 29
+	if t > delta {
+		return t * t * t
+	}
+	return 3 * delta * delta * (t - 4.0/29)
+}
+
+func linearToSRGB(c float64) uint8 {
+	if c <= 0.0031308 {
+		c *= 12.92
+	} else {
+		c = 1.055*math.Pow(c, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(math.Max(0, math.Min(1, c)) * 255))
+}
+
+// DeltaE2000 returns the CIEDE2000 color difference between two colors.
+// A difference below about 1 is not noticeable, above 5 clearly visible.
+func DeltaE2000(c1, c2 Lab) float64 {
+	chroma1, chroma2 := math.Hypot(c1.A, c1.B), math.Hypot(c2.A, c2.B)
+	meanChroma7 := math.Pow((chroma1+chroma2)/2, 7)
+	g := 0.5 * (1 - math.Sqrt(meanChroma7/(meanChroma7+math.Pow(25, 7))))
+
+	a1, a2 := (1+g)*c1.A, (1+g)*c2.A
+	cp1, cp2 := math.Hypot(a1, c1.B), math.Hypot(a2, c2.B)
+	hp1, hp2 := hueAngle(a1, c1.B), hueAngle(a2, c2.B)
+
+	deltaL := c2.L - c1.L
+	deltaC := cp2 - cp1
+	var deltaHue float64
+	if cp1*cp2 != 0 {
+		deltaHue = hp2 - hp1
+		if deltaHue > 180 {
+			deltaHue -= 360
+		} else if deltaHue < -180 {
+			deltaHue += 360
+		}
+	}
+	deltaH := 2 * math.Sqrt(cp1*cp2) * math.Sin(radians(deltaHue/2))
+
+	meanL := (c1.L + c2.L) / 2
+	meanC := (cp1 + cp2) / 2
+	meanHue := hp1 + hp2
+	if cp1*cp2 != 0 {
+		switch {
+		case math.Abs(hp1-hp2) <= 180:
+			meanHue /= 2
+		case meanHue < 360:
+			meanHue = (meanHue + 360) / 2
+		default:
+			meanHue = (meanHue - 360) / 2
+		}
+	}
+
+	t := 1 - 0.17*math.Cos(radians(meanHue-30)) +
+		0.24*math.Cos(radians(2*meanHue)) +
+		0.32*math.Cos(radians(3*meanHue+6)) -
+		0.20*math.Cos(radians(4*meanHue-63))
+	deltaTheta := 30 * math.Exp(-math.Pow((meanHue-275)/25, 2))
+	meanC7 := math.Pow(meanC, 7)
+	rotationC := 2 * math.Sqrt(meanC7/(meanC7+math.Pow(25, 7)))
+	lightness := (meanL - 50) * (meanL - 50)
+
+	scaleL := 1 + 0.015*lightness/math.Sqrt(20+lightness)
+	scaleC := 1 + 0.045*meanC
+	scaleH := 1 + 0.015*meanC*t
+	rotation := -math.Sin(radians(2*deltaTheta)) * rotationC
+
+	l, c, h := deltaL/scaleL, deltaC/scaleC, deltaH/scaleH
+	return math.Sqrt(l*l + c*c + h*h + rotation*c*h)
+}
+
+func hueAngle(a, b float64) float64 {
+	if a == 0 && b == 0 {
+		return 0
+	}
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// ExtractPalette finds up to k dominant colors of an image with k-means in
+// Lab space. Pixels are weighted by their alpha, so the transparent
+// background of a masked crop is ignored. Colors are returned most common
+// first; the result is empty for a fully transparent image.
+func ExtractPalette(img image.Image, k int) []PaletteColor {
+	bounds := img.Bounds()
+	stepX := max(1, bounds.Dx()/maxPaletteSamples)
+	stepY := max(1, bounds.Dy()/maxPaletteSamples)
+
+	var samples []Lab
+	var weights []float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a == 0 {
+				continue
+			}
+			// Colors are alpha-premultiplied, undo it before converting
+			r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+			samples = append(samples, LabFromRGB(uint8(r>>8), uint8(g>>8), uint8(b>>8)))
+			weights = append(weights, float64(a)/0xffff)
+		}
+	}
+	if len(samples) == 0 || k <= 0 {
+		return []PaletteColor{}
+	}
+
+	centers := seedCenters(samples, weights, k)
+	assignments := make([]int, len(samples))
+	for iteration := 0; iteration < kMeansIterations; iteration++ {
+		changed := false
+		for i, sample := range samples {
+			if nearest := nearestCenter(sample, centers); nearest != assignments[i] {
+				assignments[i] = nearest
+				changed = true
+			}
+		}
+		if !changed && iteration > 0 {
+			break
+		}
+
+		sums := make([]Lab, len(centers))
+		totals := make([]float64, len(centers))
+		for i, sample := range samples {
+			c, w := assignments[i], weights[i]
+			sums[c].L += sample.L * w
+			sums[c].A += sample.A * w
+			sums[c].B += sample.B * w
+			totals[c] += w
+		}
+		for c := range centers {
+			if totals[c] > 0 {
+				centers[c] = Lab{L: sums[c].L / totals[c], A: sums[c].A / totals[c], B: sums[c].B / totals[c]}
+			}
+		}
+	}
+
+	totals := make([]float64, len(centers))
+	total := 0.0
+	for i := range samples {
+		totals[assignments[i]] += weights[i]
+		total += weights[i]
+	}
+
+	palette := make([]PaletteColor, 0, len(centers))
+	for c, center := range centers {
+		if totals[c] > 0 {
+			palette = append(palette, PaletteColor{Color: center, Proportion: totals[c] / total})
+		}
+	}
+	slices.SortStableFunc(palette, func(a, b PaletteColor) int {
+		switch {
+		case a.Proportion > b.Proportion:
+			return -1
+		case a.Proportion < b.Proportion:
+			return 1
+		}
+		return 0
+	})
+	return palette
+}
+
+// seedCenters picks k-means++ starting centers. The generator is seeded
+// with a constant so a crop always gets the same palette.
+func seedCenters(samples []Lab, weights []float64, k int) []Lab {
+	random := rand.New(rand.NewSource(1))
+	centers := []Lab{samples[weightedIndex(random, weights)]}
+
+	distances := make([]float64, len(samples))
+	for len(centers) < k {
+		total := 0.0
+		for i, sample := range samples {
+			distances[i] = labDistanceSquared(sample, centers[nearestCenter(sample, centers)]) * weights[i]
+			total += distances[i]
+		}
+		// Every sample already sits on a center, fewer colors than k
+		if total == 0 {
+			break
+		}
+		centers = append(centers, samples[weightedIndex(random, distances)])
+	}
+	return centers
+}
+
+func weightedIndex(random *rand.Rand, weights []float64) int {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	target := random.Float64() * total
+	for i, w := range weights {
+		target -= w
+		if target < 0 {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
+
+// nearestCenter uses the Euclidean distance in Lab, which is what k-means
+// minimizes; CIEDE2000 is only used to report differences
+func nearestCenter(sample Lab, centers []Lab) int {
+	nearest, best := 0, math.Inf(1)
+	for c, center := range centers {
+		if d := labDistanceSquared(sample, center); d < best {
+			nearest, best = c, d
+		}
+	}
+	return nearest
+}
+
+func labDistanceSquared(a, b Lab) float64 {
+	dl, da, db := a.L-b.L, a.A-b.A, a.B-b.B
+	return dl*dl + da*da + db*db
+}
+
+// ComparePalettes matches a logo's palette against reference colors, such
+// as those of a brand guide, in both directions
+func ComparePalettes(palette []PaletteColor, reference []Lab) PaletteComparison {
+	comparison := PaletteComparison{
+		ReferenceMatches: make([]ColorMatch, len(reference)),
+		PaletteMatches:   make([]ColorMatch, len(palette)),
+	}
+	if len(palette) == 0 || len(reference) == 0 {
+		return comparison
+	}
+
+	for i, color := range reference {
+		comparison.ReferenceMatches[i] = ColorMatch{Nearest: -1, DeltaE: math.Inf(1)}
+		for j, candidate := range palette {
+			if d := DeltaE2000(color, candidate.Color); d < comparison.ReferenceMatches[i].DeltaE {
+				comparison.ReferenceMatches[i] = ColorMatch{Nearest: j, DeltaE: d}
+			}
+		}
+		comparison.MaxDeltaE = max(comparison.MaxDeltaE, comparison.ReferenceMatches[i].DeltaE)
+	}
+
+	total := 0.0
+	for i, color := range palette {
+		comparison.PaletteMatches[i] = ColorMatch{Nearest: -1, DeltaE: math.Inf(1)}
+		for j, candidate := range reference {
+			if d := DeltaE2000(color.Color, candidate); d < comparison.PaletteMatches[i].DeltaE {
+				comparison.PaletteMatches[i] = ColorMatch{Nearest: j, DeltaE: d}
+			}
+		}
+		comparison.MeanDeltaE += comparison.PaletteMatches[i].DeltaE * color.Proportion
+		comparison.MaxDeltaE = max(comparison.MaxDeltaE, comparison.PaletteMatches[i].DeltaE)
+		total += color.Proportion
+	}
+	if total > 0 {
+		comparison.MeanDeltaE /= total
+	}
+	return comparison
+}
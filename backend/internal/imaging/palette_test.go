@@ -0,0 +1,90 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeltaE2000(t *testing.T) {
+	// Reference pairs from Sharma, Wu and Dalal, "The CIEDE2000 Color-Difference Formula"
+	cases := []struct {
+		a, b     Lab
+		expected float64
+	}{
+		{Lab{50, 2.6772, -79.7751}, Lab{50, 0, -82.7485}, 2.0425},
+		{Lab{50, 0, 0}, Lab{50, -1, 2}, 2.3669},
+		{Lab{50, 2.5, 0}, Lab{73, 25, -18}, 27.1492},
+		{Lab{60.2574, -34.0099, 36.2677}, Lab{60.4626, -34.1751, 39.4387}, 1.2644},
+		{Lab{22.7233, 20.0904, -46.6940}, Lab{23.0331, 14.9730, -42.5619}, 2.0373},
+	}
+	for _, c := range cases {
+		require.InDelta(t, c.expected, DeltaE2000(c.a, c.b), 1e-4)
+		require.InDelta(t, c.expected, DeltaE2000(c.b, c.a), 1e-4)
+	}
+	require.Zero(t, DeltaE2000(Lab{40, 10, -5}, Lab{40, 10, -5}))
+}
+
+func TestLabRoundTrip(t *testing.T) {
+	white := LabFromRGB(255, 255, 255)
+	require.InDelta(t, 100, white.L, 0.01)
+	require.InDelta(t, 0, white.A, 0.01)
+
+	for _, hex := range []string{"#000000", "#ffffff", "#e30613", "#0a5c36", "#123456"} {
+		lab, err := ParseHexColor(hex)
+		require.NoError(t, err)
+		require.Equal(t, hex, lab.Hex())
+	}
+
+	short, err := ParseHexColor("#f00")
+	require.NoError(t, err)
+	require.Equal(t, "#ff0000", short.Hex())
+
+	_, err = ParseHexColor("#12345")
+	require.Error(t, err)
+	_, err = ParseHexColor("#gggggg")
+	require.Error(t, err)
+}
+
+func TestExtractPaletteIgnoresTransparentPixels(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			switch {
+			case x < 10:
+				// Masked background
+				img.Set(x, y, color.NRGBA{R: 0, G: 255, B: 0, A: 0})
+			case x < 30:
+				img.Set(x, y, color.NRGBA{R: 227, G: 6, B: 19, A: 255})
+			default:
+				img.Set(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+			}
+		}
+	}
+
+	palette := ExtractPalette(img, 4)
+	require.Len(t, palette, 2)
+	require.Equal(t, "#e30613", palette[0].Color.Hex())
+	require.InDelta(t, 2.0/3, palette[0].Proportion, 1e-9)
+	require.Equal(t, "#ffffff", palette[1].Color.Hex())
+	require.InDelta(t, 1.0/3, palette[1].Proportion, 1e-9)
+
+	require.Empty(t, ExtractPalette(image.NewNRGBA(image.Rect(0, 0, 4, 4)), 4))
+}
+
+func TestComparePalettes(t *testing.T) {
+	red, _ := ParseHexColor("#e30613")
+	white, _ := ParseHexColor("#ffffff")
+	offRed, _ := ParseHexColor("#d0101c")
+	palette := []PaletteColor{{Color: offRed, Proportion: 0.75}, {Color: white, Proportion: 0.25}}
+
+	comparison := ComparePalettes(palette, []Lab{red, white})
+	require.Equal(t, 0, comparison.ReferenceMatches[0].Nearest)
+	require.Equal(t, 1, comparison.ReferenceMatches[1].Nearest)
+	require.InDelta(t, 0, comparison.ReferenceMatches[1].DeltaE, 1e-9)
+	require.Greater(t, comparison.ReferenceMatches[0].DeltaE, 1.0)
+	require.InDelta(t, comparison.ReferenceMatches[0].DeltaE*0.75, comparison.MeanDeltaE, 1e-9)
+	require.Equal(t, comparison.ReferenceMatches[0].DeltaE, comparison.MaxDeltaE)
+}
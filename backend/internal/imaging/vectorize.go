@@ -0,0 +1,284 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+)
+
+const (
+	// smoothingPasses is how often contours are smoothed with a [1 2 1] kernel
+	smoothingPasses = 2
+	// cornerSpan is how many points on each side measure a contour's turn
+	cornerSpan = 3
+	// cornerAngle is the turn in radians above which a contour has a corner
+	cornerAngle = math.Pi / 3
+)
+
+// Directions of contour steps, clockwise in image coordinates
+var steps = [4]image.Point{{X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 0, Y: -1}}
+
+// VectorOptions controls vectorization
+type VectorOptions struct {
+	// Colors is the number of colors the logo is quantized to
+	Colors int
+	// Tolerance is the largest distance in pixels between a contour and its curves
+	Tolerance float64
+	// MinArea drops specks and holes smaller than this many pixels
+	MinArea float64
+}
+
+// Vectorize converts a logo, typically a masked crop, to SVG. The colors
+// are quantized with ExtractPalette and stacked most common first: every
+// layer covers the pixels of its color and of all the colors drawn above
+// it, so neighboring shapes leave no gaps. Each layer's contours are traced
+// along pixel edges, smoothed, split at corners and fitted with cubic
+// Bézier curves. Pixels less than half opaque are left out.
+func Vectorize(img image.Image, options VectorOptions) []byte {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	palette := ExtractPalette(img, options.Colors)
+
+	// labels holds the palette index of every pixel, -1 when transparent
+	labels := make([]int, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			if a < 0x8000 {
+				labels[y*width+x] = -1
+				continue
+			}
+			r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+			color := LabFromRGB(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+			nearest, best := 0, math.Inf(1)
+			for i, candidate := range palette {
+				if d := labDistanceSquared(color, candidate.Color); d < best {
+					nearest, best = i, d
+				}
+			}
+			labels[y*width+x] = nearest
+		}
+	}
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+
+	mask := make([]bool, width*height)
+	for layer, color := range palette {
+		for i, label := range labels {
+			mask[i] = label >= layer
+		}
+
+		var path bytes.Buffer
+		for _, contour := range TraceContours(mask, width, height) {
+			if math.Abs(polygonArea(contour)) < options.MinArea {
+				continue
+			}
+			writeCurves(&path, fitContour(smoothContour(contour), options.Tolerance))
+		}
+		if path.Len() > 0 {
+			fmt.Fprintf(&svg, `<path fill="%s" fill-rule="evenodd" d="%s"/>`+"\n", color.Color.Hex(), bytes.TrimSpace(path.Bytes()))
+		}
+	}
+
+	svg.WriteString("</svg>\n")
+	return svg.Bytes()
+}
+
+// TraceContours follows the pixel edges between set and unset pixels of a
+// mask into closed contours of unit steps. Outer contours run clockwise
+// and holes counter-clockwise, so they fill correctly with the even-odd rule.
+func TraceContours(mask []bool, width, height int) [][]Point {
+	set := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < width && y < height && mask[y*width+x]
+	}
+
+	// Every corner of the pixel grid has at most two outgoing edges
+	stride := width + 1
+	outgoing := make([][2]int8, stride*(height+1))
+	for i := range outgoing {
+		outgoing[i] = [2]int8{-1, -1}
+	}
+	addEdge := func(x, y, direction int) {
+		edges := &outgoing[y*stride+x]
+		if edges[0] < 0 {
+			edges[0] = int8(direction)
+		} else {
+			edges[1] = int8(direction)
+		}
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !set(x, y) {
+				continue
+			}
+			if !set(x, y-1) {
+				addEdge(x, y, 0)
+			}
+			if !set(x+1, y) {
+				addEdge(x+1, y, 1)
+			}
+			if !set(x, y+1) {
+				addEdge(x+1, y+1, 2)
+			}
+			if !set(x-1, y) {
+				addEdge(x, y+1, 3)
+			}
+		}
+	}
+
+	var contours [][]Point
+	for start := range outgoing {
+		for outgoing[start][0] >= 0 {
+			var contour []Point
+			vertex, direction := start, -1
+			for {
+				edges := &outgoing[vertex]
+				// Where two contours touch diagonally, turn right to keep them apart
+				slot := 0
+				if edges[1] >= 0 && direction >= 0 && int(edges[1]) == (direction+1)%4 {
+					slot = 1
+				}
+				if edges[slot] < 0 {
+					break
+				}
+				direction = int(edges[slot])
+				edges[slot] = edges[1]
+				edges[1] = -1
+
+				contour = append(contour, Point{X: float64(vertex % stride), Y: float64(vertex / stride)})
+				vertex += steps[direction].Y*stride + steps[direction].X
+				if vertex == start {
+					break
+				}
+			}
+			contours = append(contours, contour)
+		}
+	}
+	return contours
+}
+
+// smoothContour replaces the pixel staircase of a closed contour by the
+// midpoints of its steps and smooths them, which turns diagonal staircases
+// into straight lines
+func smoothContour(contour []Point) []Point {
+	n := len(contour)
+	points := make([]Point, n)
+	for i := range contour {
+		points[i] = contour[i].add(contour[(i+1)%n]).scale(0.5)
+	}
+
+	next := make([]Point, n)
+	for pass := 0; pass < smoothingPasses; pass++ {
+		for i := range points {
+			previous, following := points[(i+n-1)%n], points[(i+1)%n]
+			next[i] = previous.add(points[i].scale(2)).add(following).scale(0.25)
+		}
+		points, next = next, points
+	}
+	return points
+}
+
+// fitContour splits a closed contour at its corners and fits Bézier curves
+// to the pieces in between
+func fitContour(points []Point, tolerance float64) []Bezier {
+	n := len(points)
+	corners := findCorners(points)
+	smooth := len(corners) == 0
+	if smooth {
+		// Split a round contour in two, keeping the tangent continuous
+		corners = []int{0, n / 2}
+	}
+
+	tangent := func(i int, forward bool) Point {
+		previous, current, following := points[(i+n-1)%n], points[i%n], points[(i+1)%n]
+		switch {
+		case smooth && forward:
+			return following.sub(previous).normalize()
+		case smooth:
+			return previous.sub(following).normalize()
+		case forward:
+			return following.sub(current).normalize()
+		default:
+			return previous.sub(current).normalize()
+		}
+	}
+
+	var curves []Bezier
+	for c, from := range corners {
+		to := corners[(c+1)%len(corners)]
+		if to <= from {
+			to += n
+		}
+		piece := make([]Point, 0, to-from+1)
+		for i := from; i <= to; i++ {
+			piece = append(piece, points[i%n])
+		}
+		curves = append(curves, FitBezier(piece, tangent(from, true), tangent(to, false), tolerance)...)
+	}
+	return curves
+}
+
+// findCorners returns the indexes where a contour turns sharply, at most
+// one within cornerSpan points
+func findCorners(points []Point) []int {
+	n := len(points)
+	if n < 4*cornerSpan {
+		return nil
+	}
+
+	turns := make([]float64, n)
+	for i := range points {
+		in := points[i].sub(points[(i+n-cornerSpan)%n])
+		out := points[(i+cornerSpan)%n].sub(points[i])
+		turns[i] = math.Abs(math.Atan2(in.X*out.Y-in.Y*out.X, in.dot(out)))
+	}
+
+	var corners []int
+	for i, turn := range turns {
+		if turn < cornerAngle {
+			continue
+		}
+		isPeak := true
+		for offset := -cornerSpan; offset <= cornerSpan && isPeak; offset++ {
+			other := turns[(i+offset+n)%n]
+			// Ties go to the first of a run of equal turns
+			isPeak = offset == 0 || other < turn || (other == turn && offset > 0)
+		}
+		if isPeak {
+			corners = append(corners, i)
+		}
+	}
+	return corners
+}
+
+// polygonArea returns the signed area of a closed polygon
+func polygonArea(points []Point) float64 {
+	area := 0.0
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	return area / 2
+}
+
+func writeCurves(path *bytes.Buffer, curves []Bezier) {
+	if len(curves) == 0 {
+		return
+	}
+	fmt.Fprintf(path, "M%s %s", formatCoordinate(curves[0].P0.X), formatCoordinate(curves[0].P0.Y))
+	for _, curve := range curves {
+		fmt.Fprintf(path, "C%s %s %s %s %s %s",
+			formatCoordinate(curve.P1.X), formatCoordinate(curve.P1.Y),
+			formatCoordinate(curve.P2.X), formatCoordinate(curve.P2.Y),
+			formatCoordinate(curve.P3.X), formatCoordinate(curve.P3.Y))
+	}
+	path.WriteString("Z ")
+}
+
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
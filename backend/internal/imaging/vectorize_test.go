@@ -0,0 +1,111 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func maskFromRows(rows ...string) ([]bool, int, int) {
+	width, height := len(rows[0]), len(rows)
+	mask := make([]bool, width*height)
+	for y, row := range rows {
+		for x, c := range row {
+			mask[y*width+x] = c == '#'
+		}
+	}
+	return mask, width, height
+}
+
+func TestTraceContours(t *testing.T) {
+	mask, width, height := maskFromRows(
+		"###",
+		"###",
+	)
+	contours := TraceContours(mask, width, height)
+	require.Len(t, contours, 1)
+	require.Len(t, contours[0], 10)
+	require.Equal(t, 6.0, polygonArea(contours[0]))
+
+	// Holes run the other way round
+	mask, width, height = maskFromRows(
+		"###",
+		"#.#",
+		"###",
+	)
+	contours = TraceContours(mask, width, height)
+	require.Len(t, contours, 2)
+	areas := []float64{polygonArea(contours[0]), polygonArea(contours[1])}
+	require.ElementsMatch(t, []float64{9, -1}, areas)
+
+	// Diagonal neighbors are separate shapes
+	mask, width, height = maskFromRows(
+		"#.",
+		".#",
+	)
+	contours = TraceContours(mask, width, height)
+	require.Len(t, contours, 2)
+	require.Len(t, contours[0], 4)
+	require.Len(t, contours[1], 4)
+}
+
+func TestFitBezier(t *testing.T) {
+	var points []Point
+	for i := 0; i <= 40; i++ {
+		angle := math.Pi / 2 * float64(i) / 40
+		points = append(points, Point{X: 50 * math.Cos(angle), Y: 50 * math.Sin(angle)})
+	}
+	curves := FitBezier(points, Point{X: 0, Y: 1}, Point{X: 1, Y: 0}, 0.5)
+	require.NotEmpty(t, curves)
+	require.Equal(t, points[0], curves[0].P0)
+	require.Equal(t, points[len(points)-1], curves[len(curves)-1].P3)
+
+	// Every point lies close to some point on the curves
+	for _, p := range points {
+		best := math.Inf(1)
+		for _, curve := range curves {
+			for i := 0; i <= 200; i++ {
+				best = min(best, curve.At(float64(i)/200).distance(p))
+			}
+		}
+		require.Less(t, best, 0.6)
+	}
+
+	// A straight line needs a single curve
+	line := []Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}}
+	direction := Point{X: 1, Y: 1}.normalize()
+	require.Len(t, FitBezier(line, direction, direction.scale(-1), 0.1), 1)
+}
+
+func TestVectorize(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	for y := 5; y < 25; y++ {
+		for x := 5; x < 35; x++ {
+			c := color.NRGBA{R: 227, G: 6, B: 19, A: 255}
+			if x >= 15 && x < 25 && y >= 10 && y < 20 {
+				c = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	// A speck that is dropped
+	img.Set(1, 1, color.NRGBA{R: 227, G: 6, B: 19, A: 255})
+
+	svg := string(Vectorize(img, VectorOptions{Colors: 4, Tolerance: 1, MinArea: 4}))
+	require.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="40" height="30" viewBox="0 0 40 30">`))
+	require.True(t, strings.HasSuffix(svg, "</svg>\n"))
+	require.Equal(t, 2, strings.Count(svg, "<path"))
+	require.Contains(t, svg, `fill="#e30613"`)
+	require.Contains(t, svg, `fill="#ffffff"`)
+	// The red layer has the outer rectangle only, the speck is gone
+	red := svg[strings.Index(svg, `fill="#e30613"`):]
+	red = red[:strings.Index(red, "/>")]
+	require.Equal(t, 1, strings.Count(red, "M"))
+
+	empty := string(Vectorize(image.NewNRGBA(image.Rect(0, 0, 8, 8)), VectorOptions{Colors: 4, Tolerance: 1}))
+	require.NotContains(t, empty, "<path")
+}
@@ -0,0 +1,35 @@
+package models
+
+import "github.com/google/uuid"
+
+// Status of a composition. A composition is pending until the compositor
+// claims it and ends up completed or failed.
+const (
+	CompositionStatusPending    = "pending"
+	CompositionStatusProcessing = "processing"
+	CompositionStatusCompleted  = "completed"
+	CompositionStatusFailed     = "failed"
+)
+
+// Placement positions an extracted logo on the target image of a composition
+type Placement struct {
+	LogoID int64 `json:"logo_id"`
+	// X and Y are the center of the logo on the target, in pixels
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	// Scale multiplies the size of the logo crop
+	Scale float64 `json:"scale"`
+	// Rotation is clockwise, in degrees
+	Rotation float64 `json:"rotation"`
+	// Opacity goes from 0 (invisible) to 1 (opaque)
+	Opacity float64 `json:"opacity"`
+	// Feather is the width of the soft edge around the logo, in target pixels
+	Feather float64 `json:"feather"`
+}
+
+// CompositionMessage is published to the compositor for every new composition
+type CompositionMessage struct {
+	CompositionID uuid.UUID `json:"composition_id"`
+	// SchemaVersion is the message contract version, set by the publisher
+	SchemaVersion int `json:"schema_version,omitempty"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ModelVariants are the YOLOv8 sizes a job can be detected with, from nano to extra large
+var ModelVariants = []string{"n", "s", "m", "l", "x"}
+
+// DetectionOptions tune the detection of one job. Unset fields fall back to
+// the worker's configuration.
+type DetectionOptions struct {
+	// ConfidenceThreshold is the lowest confidence a detection is kept with
+	ConfidenceThreshold float64 `json:"confidence_threshold,omitempty"`
+	// ModelVariant is one of ModelVariants
+	ModelVariant string `json:"model_variant,omitempty"`
+	// Classes keeps only detections of these logo types
+	Classes []string `json:"classes,omitempty"`
+	// MaxDetections keeps at most this many detections, the most confident first
+	MaxDetections int `json:"max_detections,omitempty"`
+	// Tiling forces tiled detection on or off instead of deciding by image size
+	Tiling *bool `json:"tiling,omitempty"`
+}
+
+// IsZero reports whether no option is set
+func (o DetectionOptions) IsZero() bool {
+	return o.ConfidenceThreshold == 0 && o.ModelVariant == "" && len(o.Classes) == 0 && o.MaxDetections == 0 && o.Tiling == nil
+}
+
+// ModelVersion returns the detector version the options select, as the
+// worker reports it, or fallback when they do not choose a variant
+func (o DetectionOptions) ModelVersion(fallback string) string {
+	if o.ModelVariant == "" {
+		return fallback
+	}
+	return "yolov8" + o.ModelVariant
+}
+
+// DetectionParams are the settings that determine a detection result. Jobs
+// for the same image with the same parameters produce the same logos, so
+// their results can be reused.
+type DetectionParams struct {
+	ModelVersion string `json:"model_version"`
+	// Options is set when a job overrides the detector's defaults. Tiling is
+	// left out, it is described by the Tiling field.
+	Options *DetectionOptions `json:"options,omitempty"`
+	// Tiling is set for images detected tile by tile
+	Tiling *TilingParams `json:"tiling,omitempty"`
+}
+
+// TilingParams are the settings a tiled detection was split and merged with
+type TilingParams struct {
+	TileSize       int     `json:"tile_size"`
+	Overlap        int     `json:"overlap"`
+	MergeThreshold float64 `json:"merge_threshold"`
+}
+
+// WithOptions returns the parameters of a job detected with the given options
+func (p DetectionParams) WithOptions(options DetectionOptions) DetectionParams {
+	options.Tiling = nil
+	if !options.IsZero() {
+		p.Options = &options
+	}
+	return p
+}
+
+// Fingerprint returns a stable hash of the parameters
+func (p DetectionParams) Fingerprint() string {
+	// Struct fields marshal in declaration order, so the encoding is stable
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
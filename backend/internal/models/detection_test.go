@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectionParamsFingerprint(t *testing.T) {
+	params := DetectionParams{ModelVersion: "yolov8n"}
+
+	require.Equal(t, params.Fingerprint(), DetectionParams{ModelVersion: "yolov8n"}.Fingerprint())
+	require.NotEqual(t, params.Fingerprint(), DetectionParams{ModelVersion: "yolov8s"}.Fingerprint())
+	require.Len(t, params.Fingerprint(), 64)
+
+	// Options change the fingerprint, except for the tiling flag
+	withOptions := params.WithOptions(DetectionOptions{ConfidenceThreshold: 0.5})
+	require.NotEqual(t, params.Fingerprint(), withOptions.Fingerprint())
+	tiling := true
+	require.Equal(t, params.Fingerprint(), params.WithOptions(DetectionOptions{Tiling: &tiling}).Fingerprint())
+	require.Nil(t, params.Options)
+
+	tiled := DetectionParams{ModelVersion: "yolov8n", Tiling: &TilingParams{TileSize: 2048, Overlap: 256, MergeThreshold: 0.5}}
+	require.NotEqual(t, params.Fingerprint(), tiled.Fingerprint())
+}
+
+func TestDetectionOptions(t *testing.T) {
+	require.True(t, DetectionOptions{}.IsZero())
+	require.False(t, DetectionOptions{Classes: []string{"nike"}}.IsZero())
+
+	require.Equal(t, "yolov8n", DetectionOptions{}.ModelVersion("yolov8n"))
+	require.Equal(t, "yolov8m", DetectionOptions{ModelVariant: "m"}.ModelVersion("yolov8n"))
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Geometry is the shape of a detected logo beyond its bounding box. All
+// coordinates are in pixels of the original image; every part is optional.
+type Geometry struct {
+	// Polygons outline the logo, one polygon per connected part
+	Polygons   [][]Point   `json:"polygons,omitempty"`
+	RotatedBox *RotatedBox `json:"rotated_box,omitempty"`
+	Mask       *RLEMask    `json:"mask,omitempty"`
+}
+
+// RotatedBox is a box turned clockwise by Angle degrees around its center
+type RotatedBox struct {
+	CenterX float64 `json:"cx"`
+	CenterY float64 `json:"cy"`
+	Width   float64 `json:"width"`
+	Height  float64 `json:"height"`
+	Angle   float64 `json:"angle"`
+}
+
+// RLEMask is a segmentation mask in COCO's uncompressed run-length encoding:
+// Size is [height, width] and Counts are alternating runs of background and
+// foreground pixels in column-major order, starting with background
+type RLEMask struct {
+	Size   [2]int `json:"size"`
+	Counts []int  `json:"counts"`
+}
+
+// IsZero reports whether the geometry carries no shape at all
+func (g Geometry) IsZero() bool {
+	return len(g.Polygons) == 0 && g.RotatedBox == nil && g.Mask == nil
+}
+
+// Validate checks that every shape in the geometry is well formed
+func (g Geometry) Validate() error {
+	for i, polygon := range g.Polygons {
+		if len(polygon) < 3 {
+			return fmt.Errorf("polygon %d has %d points, need at least 3", i, len(polygon))
+		}
+		for _, p := range polygon {
+			if !finite(p.X, p.Y) {
+				return fmt.Errorf("polygon %d has a non-finite point", i)
+			}
+		}
+	}
+
+	if box := g.RotatedBox; box != nil {
+		if !finite(box.CenterX, box.CenterY, box.Width, box.Height, box.Angle) {
+			return errors.New("rotated box has non-finite values")
+		}
+		if box.Width <= 0 || box.Height <= 0 {
+			return errors.New("rotated box must have a positive width and height")
+		}
+	}
+
+	if mask := g.Mask; mask != nil {
+		height, width := mask.Size[0], mask.Size[1]
+		if height <= 0 || width <= 0 {
+			return errors.New("mask size must be positive")
+		}
+		total := 0
+		for _, count := range mask.Counts {
+			if count < 0 {
+				return errors.New("mask counts must not be negative")
+			}
+			total += count
+		}
+		if total != height*width {
+			return fmt.Errorf("mask counts cover %d pixels, size is %d", total, height*width)
+		}
+	}
+	return nil
+}
+
+// Translate moves the geometry by dx, dy pixels into an image of the given
+// width and height, such as from a tile into the image it was cut from. The
+// mask is re-encoded at the new size and dropped if it does not fit.
+func (g Geometry) Translate(dx, dy, width, height int) Geometry {
+	moved := Geometry{}
+	for _, polygon := range g.Polygons {
+		points := make([]Point, len(polygon))
+		for i, p := range polygon {
+			points[i] = Point{X: p.X + float64(dx), Y: p.Y + float64(dy)}
+		}
+		moved.Polygons = append(moved.Polygons, points)
+	}
+
+	if box := g.RotatedBox; box != nil {
+		rotated := *box
+		rotated.CenterX += float64(dx)
+		rotated.CenterY += float64(dy)
+		moved.RotatedBox = &rotated
+	}
+
+	if mask := g.Mask; mask != nil {
+		moved.Mask = mask.translate(dx, dy, width, height)
+	}
+	return moved
+}
+
+// translate places the mask at dx, dy in a larger, empty mask
+func (m *RLEMask) translate(dx, dy, width, height int) *RLEMask {
+	rows, columns := m.Size[0], m.Size[1]
+	if dx < 0 || dy < 0 || dx+columns > width || dy+rows > height {
+		return nil
+	}
+
+	out := &rleBuilder{}
+	out.add(false, dx*height)
+	source := rleReader{counts: m.Counts}
+	for x := 0; x < columns; x++ {
+		out.add(false, dy)
+		source.copyTo(out, rows)
+		out.add(false, height-dy-rows)
+	}
+	out.add(false, (width-dx-columns)*height)
+	return &RLEMask{Size: [2]int{height, width}, Counts: out.counts}
+}
+
+// rleBuilder appends pixels to run-length counts, merging equal runs
+type rleBuilder struct {
+	counts     []int
+	foreground bool
+}
+
+func (b *rleBuilder) add(foreground bool, n int) {
+	if n <= 0 {
+		return
+	}
+	switch {
+	case len(b.counts) == 0 && foreground:
+		// Counts always start with background
+		b.counts = append(b.counts, 0, n)
+	case len(b.counts) == 0 || foreground != b.foreground:
+		b.counts = append(b.counts, n)
+	default:
+		b.counts[len(b.counts)-1] += n
+	}
+	b.foreground = foreground
+}
+
+// rleReader walks run-length counts pixel by pixel
+type rleReader struct {
+	counts     []int
+	run        int
+	used       int
+	foreground bool
+}
+
+// copyTo moves the next n pixels to out
+func (r *rleReader) copyTo(out *rleBuilder, n int) {
+	for n > 0 && r.run < len(r.counts) {
+		left := r.counts[r.run] - r.used
+		if left == 0 {
+			r.run++
+			r.used = 0
+			r.foreground = !r.foreground
+			continue
+		}
+		take := min(n, left)
+		out.add(r.foreground, take)
+		r.used += take
+		n -= take
+	}
+}
+
+func finite(values ...float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
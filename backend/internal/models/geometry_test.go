@@ -0,0 +1,56 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeometryValidate(t *testing.T) {
+	valid := Geometry{
+		Polygons:   [][]Point{{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 5}}},
+		RotatedBox: &RotatedBox{CenterX: 5, CenterY: 5, Width: 10, Height: 4, Angle: 30},
+		Mask:       &RLEMask{Size: [2]int{2, 3}, Counts: []int{1, 4, 1}},
+	}
+	require.NoError(t, valid.Validate())
+	require.False(t, valid.IsZero())
+	require.True(t, Geometry{}.IsZero())
+	require.NoError(t, Geometry{}.Validate())
+
+	invalid := []Geometry{
+		{Polygons: [][]Point{{{X: 0, Y: 0}, {X: 1, Y: 1}}}},
+		{Polygons: [][]Point{{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: math.NaN(), Y: 0}}}},
+		{RotatedBox: &RotatedBox{Width: 0, Height: 4}},
+		{Mask: &RLEMask{Size: [2]int{2, 3}, Counts: []int{1, 4}}},
+		{Mask: &RLEMask{Size: [2]int{2, 3}, Counts: []int{7, -1}}},
+		{Mask: &RLEMask{Size: [2]int{0, 3}}},
+	}
+	for _, geometry := range invalid {
+		require.Error(t, geometry.Validate(), "%+v", geometry)
+	}
+}
+
+func TestGeometryTranslate(t *testing.T) {
+	// A 2x2 mask with its right column set, column-major
+	geometry := Geometry{
+		Polygons:   [][]Point{{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}}},
+		RotatedBox: &RotatedBox{CenterX: 1, CenterY: 1, Width: 2, Height: 2, Angle: 10},
+		Mask:       &RLEMask{Size: [2]int{2, 2}, Counts: []int{2, 2}},
+	}
+
+	moved := geometry.Translate(1, 2, 4, 5)
+	require.Equal(t, []Point{{X: 1, Y: 2}, {X: 3, Y: 2}, {X: 3, Y: 4}}, moved.Polygons[0])
+	require.Equal(t, RotatedBox{CenterX: 2, CenterY: 3, Width: 2, Height: 2, Angle: 10}, *moved.RotatedBox)
+	// Columns 0 and 1 are empty, column 2 has rows 2-3 set
+	require.Equal(t, &RLEMask{Size: [2]int{5, 4}, Counts: []int{12, 2, 6}}, moved.Mask)
+	require.NoError(t, moved.Validate())
+	// The original is left alone
+	require.Equal(t, 1.0, geometry.RotatedBox.CenterX)
+
+	// A mask starting with foreground keeps its leading empty run
+	corner := Geometry{Mask: &RLEMask{Size: [2]int{1, 1}, Counts: []int{0, 1}}}
+	require.Equal(t, []int{0, 1, 1}, corner.Translate(0, 0, 1, 2).Mask.Counts)
+
+	require.Nil(t, geometry.Translate(3, 0, 4, 5).Mask)
+}
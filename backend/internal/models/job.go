@@ -1,10 +1,57 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	JobStatusPending    = "pending"
+	JobStatusProcessing = "processing"
+	JobStatusCompleted  = "completed"
+	JobStatusFailed     = "failed"
+	JobStatusCancelled  = "cancelled"
+)
+
+// JobStatuses lists every status a job can be in
+var JobStatuses = []string{JobStatusPending, JobStatusProcessing, JobStatusCompleted, JobStatusFailed, JobStatusCancelled}
+
+// jobTransitions maps each status to the statuses a job may move to from it.
+// Pending may go straight to a result because the worker does not report
+// processing; pending -> pending is a requeue. Completed and cancelled are final.
+var jobTransitions = map[string][]string{
+	JobStatusPending:    {JobStatusPending, JobStatusProcessing, JobStatusCompleted, JobStatusFailed, JobStatusCancelled},
+	JobStatusProcessing: {JobStatusPending, JobStatusCompleted, JobStatusFailed, JobStatusCancelled},
+	JobStatusFailed:     {JobStatusPending},
+	JobStatusCompleted:  {},
+	JobStatusCancelled:  {},
+}
+
+// CanTransition reports whether a job may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, next := range jobTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedFromStatuses returns every status a job may move to the given status from
+func AllowedFromStatuses(to string) []string {
+	from := []string{}
+	for _, status := range JobStatuses {
+		if CanTransition(status, to) {
+			from = append(from, status)
+		}
+	}
+	return from
+}
 
 type Job struct {
-	ID          string     `json:"id"`
-	Status      string     `json:"status"` // pending, processing, completed, failed
+	ID          uuid.UUID  `json:"id"`
+	Status      string     `json:"status"` // pending, processing, completed, failed, cancelled
 	S3Key       string     `json:"s3_key"`
 	UploadURL   string     `json:"upload_url"`
 	ResultURL   string     `json:"result_url,omitempty"`
@@ -13,15 +60,34 @@ type Job struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	Error       string     `json:"error,omitempty"`
+	// DetectionOptions are the job's overrides of the worker's detection settings
+	DetectionOptions *DetectionOptions `json:"detection_options,omitempty"`
+	// SchemaVersion is the message contract version, set by the publisher
+	SchemaVersion int `json:"schema_version,omitempty"`
 }
 
 type LogoDetection struct {
-	ID          string  `json:"id"`
-	JobID       string  `json:"job_id"`
-	BoundingBox BBox    `json:"bounding_box"`
-	Confidence  float64 `json:"confidence"`
-	LogoType    string  `json:"logo_type"`
-	S3Key       string  `json:"s3_key"`
+	ID          string    `json:"id"`
+	JobID       uuid.UUID `json:"job_id"`
+	BoundingBox BBox      `json:"bounding_box"`
+	Confidence  float64   `json:"confidence"`
+	LogoType    string    `json:"logo_type"`
+	S3Key       string    `json:"s3_key"`
+	// Corners optionally outline the logo in perspective, in top-left,
+	// top-right, bottom-right, bottom-left order, so it can be rectified
+	Corners []Point `json:"corners,omitempty"`
+	// MaskS3Key optionally points at a segmentation mask of the crop, such
+	// as SAM output, used instead of generating one
+	MaskS3Key string `json:"mask_s3_key,omitempty"`
+	// Geometry optionally describes the logo's shape more precisely than
+	// the bounding box, for detectors with rotated or segmentation output
+	Geometry *Geometry `json:"geometry,omitempty"`
+}
+
+// Point is a position in image pixels
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
 }
 
 type BBox struct {
@@ -32,9 +98,23 @@ type BBox struct {
 }
 
 type ProcessingResult struct {
-	JobID       string          `json:"job_id"`
+	JobID       uuid.UUID       `json:"job_id"`
 	Status      string          `json:"status"`
 	LogosFound  []LogoDetection `json:"logos_found"`
 	ResultURL   string          `json:"result_url"`
 	ProcessedAt time.Time       `json:"processed_at"`
+	// ModelVersion is the detector that produced the result, e.g. yolov8n
+	ModelVersion string `json:"model_version,omitempty"`
+	// Error is the worker's reason for a failed result
+	Error string `json:"error,omitempty"`
+	// SchemaVersion is the message contract version, set by the publisher
+	SchemaVersion int `json:"schema_version,omitempty"`
+}
+
+// SplitMessage is published for every tiled job, its image is cut into
+// tiles by the consumer instead of the upload request
+type SplitMessage struct {
+	JobID uuid.UUID `json:"job_id"`
+	// SchemaVersion is the message contract version, set by the publisher
+	SchemaVersion int `json:"schema_version,omitempty"`
 }
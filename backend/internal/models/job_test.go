@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(JobStatusPending, JobStatusProcessing))
+	assert.True(t, CanTransition(JobStatusProcessing, JobStatusCompleted))
+	assert.True(t, CanTransition(JobStatusProcessing, JobStatusFailed))
+	assert.True(t, CanTransition(JobStatusFailed, JobStatusPending))
+
+	// A late failure must never overwrite a completed job
+	assert.False(t, CanTransition(JobStatusCompleted, JobStatusFailed))
+	assert.False(t, CanTransition(JobStatusCancelled, JobStatusPending))
+	assert.False(t, CanTransition(JobStatusPending, "unknown"))
+}
+
+func TestAllowedFromStatuses(t *testing.T) {
+	require.ElementsMatch(t, []string{JobStatusPending, JobStatusProcessing}, AllowedFromStatuses(JobStatusCompleted))
+	require.ElementsMatch(t, []string{JobStatusPending, JobStatusProcessing, JobStatusFailed}, AllowedFromStatuses(JobStatusPending))
+	require.Empty(t, AllowedFromStatuses("unknown"))
+}
@@ -0,0 +1,10 @@
+package models
+
+// PaletteColor is one dominant color of a logo
+type PaletteColor struct {
+	Hex string `json:"hex"`
+	// Lab is the color in CIE L*a*b*, which distances are computed in
+	Lab [3]float64 `json:"lab"`
+	// Proportion is the share of the logo's visible pixels in this color
+	Proportion float64 `json:"proportion"`
+}
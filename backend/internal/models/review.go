@@ -0,0 +1,58 @@
+package models
+
+// Review status of a single logo
+const (
+	ReviewStatusPending   = "pending"
+	ReviewStatusAccepted  = "accepted"
+	ReviewStatusRejected  = "rejected"
+	ReviewStatusCorrected = "corrected"
+)
+
+// Decisions a reviewer can make about a logo
+const (
+	ReviewDecisionAccept  = "accept"
+	ReviewDecisionReject  = "reject"
+	ReviewDecisionRelabel = "relabel"
+	ReviewDecisionAdjust  = "adjust"
+)
+
+// Review state of a job, derived from the review status of its logos
+const (
+	JobReviewNotRequired = "not_required"
+	JobReviewPending     = "pending"
+	JobReviewInProgress  = "in_progress"
+	JobReviewCompleted   = "reviewed"
+)
+
+var reviewStatusByDecision = map[string]string{
+	ReviewDecisionAccept:  ReviewStatusAccepted,
+	ReviewDecisionReject:  ReviewStatusRejected,
+	ReviewDecisionRelabel: ReviewStatusCorrected,
+	ReviewDecisionAdjust:  ReviewStatusCorrected,
+}
+
+// ReviewStatusFor returns the logo review status a decision leads to
+func ReviewStatusFor(decision string) (string, bool) {
+	status, ok := reviewStatusByDecision[decision]
+	return status, ok
+}
+
+// JobReviewState derives a job's review state from the number of its logos
+// in each review status
+func JobReviewState(counts map[string]int64) string {
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+
+	switch pending := counts[ReviewStatusPending]; {
+	case total == 0:
+		return JobReviewNotRequired
+	case pending == total:
+		return JobReviewPending
+	case pending > 0:
+		return JobReviewInProgress
+	default:
+		return JobReviewCompleted
+	}
+}
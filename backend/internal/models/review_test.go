@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewStatusFor(t *testing.T) {
+	testCases := map[string]string{
+		ReviewDecisionAccept:  ReviewStatusAccepted,
+		ReviewDecisionReject:  ReviewStatusRejected,
+		ReviewDecisionRelabel: ReviewStatusCorrected,
+		ReviewDecisionAdjust:  ReviewStatusCorrected,
+	}
+	for decision, expected := range testCases {
+		status, ok := ReviewStatusFor(decision)
+		require.True(t, ok, decision)
+		require.Equal(t, expected, status, decision)
+	}
+
+	_, ok := ReviewStatusFor("approve")
+	require.False(t, ok)
+}
+
+func TestJobReviewState(t *testing.T) {
+	testCases := []struct {
+		name     string
+		counts   map[string]int64
+		expected string
+	}{
+		{"no logos", map[string]int64{}, JobReviewNotRequired},
+		{"nothing reviewed", map[string]int64{ReviewStatusPending: 3}, JobReviewPending},
+		{"partly reviewed", map[string]int64{ReviewStatusPending: 1, ReviewStatusAccepted: 2}, JobReviewInProgress},
+		{"all reviewed", map[string]int64{ReviewStatusAccepted: 1, ReviewStatusRejected: 1, ReviewStatusCorrected: 1}, JobReviewCompleted},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, JobReviewState(tc.counts))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package queue
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/streadway/amqp"
+)
+
+// SchemaVersion is the version of the message contracts this build speaks.
+// It is stamped on every published message and only messages of this
+// version are consumed.
+const SchemaVersion = 1
+
+// Message types, sent as the AMQP type property of every message
+const (
+	MessageTypeJob         = "detection.job"
+	MessageTypeResult      = "detection.result"
+	MessageTypeComposition = "composition.request"
+	MessageTypeSplit       = "tiling.split"
+)
+
+// schemaVersionHeader is the AMQP header carrying the schema version, so
+// consumers can route or reject a message without parsing its body
+const schemaVersionHeader = "schema_version"
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// schemaFileNames maps every message type to its JSON Schema in schemas/
+var schemaFileNames = map[string]string{
+	MessageTypeJob:         "detection-job.v1.json",
+	MessageTypeResult:      "detection-result.v1.json",
+	MessageTypeComposition: "composition.v1.json",
+	MessageTypeSplit:       "tiling-split.v1.json",
+}
+
+var schemas = compileSchemas()
+
+func compileSchemas() map[string]*jsonschema.Schema {
+	compiler := jsonschema.NewCompiler()
+	compiled := make(map[string]*jsonschema.Schema, len(schemaFileNames))
+	for messageType, name := range schemaFileNames {
+		data, err := schemaFiles.ReadFile("schemas/" + name)
+		if err != nil {
+			panic(fmt.Sprintf("failed to read schema %s: %v", name, err))
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		if err != nil {
+			panic(fmt.Sprintf("failed to parse schema %s: %v", name, err))
+		}
+		if err := compiler.AddResource(name, doc); err != nil {
+			panic(fmt.Sprintf("failed to add schema %s: %v", name, err))
+		}
+		schema, err := compiler.Compile(name)
+		if err != nil {
+			panic(fmt.Sprintf("failed to compile schema %s: %v", name, err))
+		}
+		compiled[messageType] = schema
+	}
+	return compiled
+}
+
+// Validate checks a message body against the schema of its type
+func Validate(messageType string, body []byte) error {
+	schema, ok := schemas[messageType]
+	if !ok {
+		return fmt.Errorf("unknown message type %q", messageType)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid %s message: %w", messageType, err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		return fmt.Errorf("invalid %s message: %w", messageType, err)
+	}
+	return nil
+}
+
+// encode marshals a message and validates it before it is published
+func encode(messageType string, message any) ([]byte, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s message: %w", messageType, err)
+	}
+	if err := Validate(messageType, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// decode validates a delivered message and unmarshals it. The type
+// property and schema version header are checked when present, the body
+// always has to match the schema.
+func decode(messageType string, delivery amqp.Delivery, message any) error {
+	if delivery.Type != "" && delivery.Type != messageType {
+		return fmt.Errorf("unexpected message type %q, want %q", delivery.Type, messageType)
+	}
+	if version, ok := delivery.Headers[schemaVersionHeader]; ok {
+		if fmt.Sprint(version) != fmt.Sprint(SchemaVersion) {
+			return fmt.Errorf("unsupported schema version %v of %s message", version, messageType)
+		}
+	}
+	if err := Validate(messageType, delivery.Body); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(delivery.Body, message); err != nil {
+		return fmt.Errorf("failed to unmarshal %s message: %w", messageType, err)
+	}
+	return nil
+}
+
+// publishing builds the AMQP message for a validated body
+func publishing(messageType string, body []byte) amqp.Publishing {
+	return amqp.Publishing{
+		ContentType:  "application/json",
+		Type:         messageType,
+		Headers:      amqp.Table{schemaVersionHeader: int32(SchemaVersion)},
+		Body:         body,
+		DeliveryMode: amqp.Persistent, // Make message persistent
+		Timestamp:    time.Now(),
+	}
+}
@@ -0,0 +1,253 @@
+package queue
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	dbtypes "github.com/Viczdera/ai-logo-preserve/backend/internal/db"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/require"
+)
+
+// The contract tests fail when a Go message struct and its schema drift
+// apart, so a field added on one side has to be added on the other
+
+func TestStructsMatchSchemas(t *testing.T) {
+	for messageType, message := range map[string]any{
+		MessageTypeJob:         models.Job{},
+		MessageTypeResult:      models.ProcessingResult{},
+		MessageTypeComposition: models.CompositionMessage{},
+		MessageTypeSplit:       models.SplitMessage{},
+	} {
+		t.Run(messageType, func(t *testing.T) {
+			data, err := schemaFiles.ReadFile("schemas/" + schemaFileNames[messageType])
+			require.NoError(t, err)
+			var schema map[string]any
+			require.NoError(t, json.Unmarshal(data, &schema))
+
+			compareFields(t, schema, schema, reflect.TypeOf(message), messageType)
+		})
+	}
+}
+
+// compareFields checks that a struct's JSON fields are exactly the
+// properties of its schema node, recursing into nested structs and slices
+func compareFields(t *testing.T, root, node map[string]any, typ reflect.Type, path string) {
+	node = resolve(t, root, node)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == reflect.TypeOf(time.Time{}) || typ == reflect.TypeOf(uuid.UUID{}):
+		return
+	case typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array:
+		items, ok := node["items"].(map[string]any)
+		require.True(t, ok, "%s: schema has no items for %s", path, typ)
+		compareFields(t, root, items, typ.Elem(), path+"[]")
+		return
+	case typ.Kind() != reflect.Struct:
+		return
+	}
+
+	properties, ok := node["properties"].(map[string]any)
+	require.True(t, ok, "%s: schema has no properties for %s", path, typ)
+
+	fields := map[string]reflect.Type{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+
+	require.Equal(t, sortedKeys(properties), sortedKeys(fields), "%s: fields of %s differ from the schema", path, typ)
+	for name, fieldType := range fields {
+		compareFields(t, root, properties[name].(map[string]any), fieldType, path+"."+name)
+	}
+}
+
+// resolve follows a local $ref such as #/$defs/point
+func resolve(t *testing.T, root, node map[string]any) map[string]any {
+	ref, ok := node["$ref"].(string)
+	if !ok {
+		return node
+	}
+	require.True(t, strings.HasPrefix(ref, "#/$defs/"), "unsupported $ref %s", ref)
+	definition, ok := root["$defs"].(map[string]any)[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+	require.True(t, ok, "missing definition %s", ref)
+	return definition
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestEncodeJob(t *testing.T) {
+	job := db.Job{
+		ID:           uuid.New(),
+		Status:       models.JobStatusPending,
+		S3Key:        "uploads/1/image.png",
+		UploadUrl:    "https://example.com/image.png",
+		ResultUrl:    sql.NullString{String: "https://example.com/result.png", Valid: true},
+		ErrorMessage: sql.NullString{String: "previous attempt failed", Valid: true},
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		CompletedAt:  time.Now(),
+		DetectionOptions: dbtypes.DetectionOptions{
+			ConfidenceThreshold: 0.4,
+			ModelVariant:        "s",
+			Classes:             []string{"nike"},
+			MaxDetections:       10,
+		},
+	}
+	message := NewJobMessage(job)
+	message.SchemaVersion = SchemaVersion
+
+	body, err := encode(MessageTypeJob, message)
+	require.NoError(t, err)
+
+	var decoded models.Job
+	require.NoError(t, decode(MessageTypeJob, amqp.Delivery{Type: MessageTypeJob, Body: body}, &decoded))
+	require.Equal(t, message.ID, decoded.ID)
+	require.Equal(t, message.DetectionOptions, decoded.DetectionOptions)
+
+	tile := NewTileMessage(job, db.JobTile{ID: uuid.New(), S3Key: "uploads/1/tiles/tile_0.png"})
+	tile.SchemaVersion = SchemaVersion
+	_, err = encode(MessageTypeJob, tile)
+	require.NoError(t, err)
+
+	// The schema version is required, publishers have to stamp it
+	_, err = encode(MessageTypeJob, NewJobMessage(job))
+	require.Error(t, err)
+
+	message.S3Key = ""
+	_, err = encode(MessageTypeJob, message)
+	require.Error(t, err)
+}
+
+func TestEncodeResult(t *testing.T) {
+	result := models.ProcessingResult{
+		SchemaVersion: SchemaVersion,
+		JobID:         uuid.New(),
+		Status:        models.JobStatusCompleted,
+		LogosFound: []models.LogoDetection{{
+			ID:          uuid.NewString(),
+			JobID:       uuid.New(),
+			BoundingBox: models.BBox{X: 1, Y: 2, Width: 30, Height: 40},
+			Confidence:  0.9,
+			LogoType:    "nike",
+			S3Key:       "extracted/1/logo_0.png",
+			Corners:     []models.Point{{X: 1, Y: 2}, {X: 31, Y: 2}, {X: 31, Y: 42}, {X: 1, Y: 42}},
+			MaskS3Key:   "extracted/1/mask_0.png",
+			Geometry: &models.Geometry{
+				Polygons:   [][]models.Point{{{X: 1, Y: 2}, {X: 31, Y: 2}, {X: 16, Y: 42}}},
+				RotatedBox: &models.RotatedBox{CenterX: 16, CenterY: 22, Width: 30, Height: 40, Angle: 10},
+				Mask:       &models.RLEMask{Size: [2]int{40, 30}, Counts: []int{0, 1200}},
+			},
+		}},
+		ProcessedAt:  time.Now(),
+		ModelVersion: "yolov8n",
+	}
+
+	body, err := encode(MessageTypeResult, &result)
+	require.NoError(t, err)
+
+	var decoded models.ProcessingResult
+	require.NoError(t, decode(MessageTypeResult, amqp.Delivery{Body: body}, &decoded))
+	require.Equal(t, result.LogosFound[0].Geometry, decoded.LogosFound[0].Geometry)
+
+	result.LogosFound[0].Confidence = 1.5
+	_, err = encode(MessageTypeResult, &result)
+	require.Error(t, err)
+}
+
+func TestEncodeComposition(t *testing.T) {
+	body, err := encode(MessageTypeComposition, &models.CompositionMessage{SchemaVersion: SchemaVersion, CompositionID: uuid.New()})
+	require.NoError(t, err)
+	require.NoError(t, decode(MessageTypeComposition, amqp.Delivery{Body: body}, &models.CompositionMessage{}))
+}
+
+func TestEncodeSplit(t *testing.T) {
+	body, err := encode(MessageTypeSplit, &models.SplitMessage{SchemaVersion: SchemaVersion, JobID: uuid.New()})
+	require.NoError(t, err)
+	require.NoError(t, decode(MessageTypeSplit, amqp.Delivery{Body: body}, &models.SplitMessage{}))
+}
+
+// Results as the Python worker publishes them
+func TestDecodeWorkerResults(t *testing.T) {
+	for name, body := range map[string]string{
+		"completed": `{
+			"schema_version": 1,
+			"job_id": "6f1c7c52-2b8e-4c1e-9a55-0f3f2b9c8d11",
+			"status": "completed",
+			"logos_found": [{
+				"id": "0b0e9f57-3f86-4a1c-8f4e-2b8b0a1d6c3e",
+				"job_id": "6f1c7c52-2b8e-4c1e-9a55-0f3f2b9c8d11",
+				"bounding_box": {"x": 10, "y": 20, "width": 100, "height": 50},
+				"confidence": 0.87,
+				"logo_type": "nike",
+				"s3_key": "extracted/6f1c7c52-2b8e-4c1e-9a55-0f3f2b9c8d11/logo_0.png"
+			}],
+			"result_url": "",
+			"processed_at": "2026-10-18T12:00:00.123456Z",
+			"model_version": "yolov8n"
+		}`,
+		"failed": `{
+			"schema_version": 1,
+			"job_id": "6f1c7c52-2b8e-4c1e-9a55-0f3f2b9c8d11",
+			"status": "failed",
+			"logos_found": [],
+			"result_url": "",
+			"processed_at": "2026-10-18T12:00:00.123456Z",
+			"error": "Processing failed after 3 attempts: image not found"
+		}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var result models.ProcessingResult
+			delivery := amqp.Delivery{
+				Type:    MessageTypeResult,
+				Headers: amqp.Table{schemaVersionHeader: int64(1)},
+				Body:    []byte(body),
+			}
+			require.NoError(t, decode(MessageTypeResult, delivery, &result))
+
+			// Every field the worker sends has a home in the struct
+			decoder := json.NewDecoder(bytes.NewReader(delivery.Body))
+			decoder.DisallowUnknownFields()
+			require.NoError(t, decoder.Decode(&models.ProcessingResult{}))
+			require.Equal(t, SchemaVersion, result.SchemaVersion)
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	body := []byte(`{"schema_version": 1, "composition_id": "6f1c7c52-2b8e-4c1e-9a55-0f3f2b9c8d11"}`)
+	var message models.CompositionMessage
+
+	require.Error(t, decode(MessageTypeComposition, amqp.Delivery{Type: MessageTypeJob, Body: body}, &message))
+	require.Error(t, decode(MessageTypeComposition, amqp.Delivery{Headers: amqp.Table{schemaVersionHeader: int32(2)}, Body: body}, &message))
+	require.Error(t, decode(MessageTypeComposition, amqp.Delivery{Body: []byte(`{"schema_version": 2, "composition_id": "6f1c7c52-2b8e-4c1e-9a55-0f3f2b9c8d11"}`)}, &message))
+	require.Error(t, decode(MessageTypeComposition, amqp.Delivery{Body: []byte(`{"schema_version": 1}`)}, &message))
+	require.Error(t, decode(MessageTypeComposition, amqp.Delivery{Body: []byte(`not json`)}, &message))
+	require.NoError(t, decode(MessageTypeComposition, amqp.Delivery{Type: MessageTypeComposition, Body: body}, &message))
+}
@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript takes the lock when it is free and renews it when this holder already owns it
+var acquireScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current == false then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+if current == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLock is a lease-based lock used for leader election between replicas.
+// Each instance has its own token, so only the holder can renew or release it.
+type RedisLock struct {
+	client *redis.Client
+	key    string
+	token  string
+}
+
+func NewRedisLock(client *redis.Client, name string) *RedisLock {
+	return &RedisLock{
+		client: client,
+		key:    "logo-preserve:lock:" + name,
+		token:  uuid.New().String(),
+	}
+}
+
+// Acquire takes or renews the lock for ttl and reports whether this instance is the leader
+func (l *RedisLock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	held, err := acquireScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock %s: %w", l.key, err)
+	}
+	return held == 1, nil
+}
+
+// Release gives up the lock if this instance still holds it
+func (l *RedisLock) Release(ctx context.Context) error {
+	if err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.key, err)
+	}
+	return nil
+}
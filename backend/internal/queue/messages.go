@@ -0,0 +1,54 @@
+package queue
+
+import (
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+)
+
+// NewJobMessage builds the message published to the detection workers from a job row
+func NewJobMessage(job db.Job) *models.Job {
+	jobModel := &models.Job{
+		ID:        job.ID,
+		Status:    job.Status,
+		S3Key:     job.S3Key,
+		UploadURL: job.UploadUrl,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+
+	// Set optional fields if they exist
+	if job.ResultUrl.Valid {
+		jobModel.ResultURL = job.ResultUrl.String
+	}
+	if !job.CompletedAt.IsZero() {
+		jobModel.CompletedAt = &job.CompletedAt
+	}
+	if job.ErrorMessage.Valid {
+		jobModel.Error = job.ErrorMessage.String
+	}
+	jobModel.DetectionOptions = detectionOptions(job)
+
+	return jobModel
+}
+
+// NewTileMessage builds the message for one tile of a tiled job. Workers see
+// an ordinary job whose ID is the tile's, so results come back per tile, and
+// every tile is detected with the options of its job.
+func NewTileMessage(job db.Job, tile db.JobTile) *models.Job {
+	return &models.Job{
+		ID:               tile.ID,
+		Status:           models.JobStatusPending,
+		S3Key:            tile.S3Key,
+		CreatedAt:        tile.CreatedAt,
+		UpdatedAt:        tile.UpdatedAt,
+		DetectionOptions: detectionOptions(job),
+	}
+}
+
+func detectionOptions(job db.Job) *models.DetectionOptions {
+	options := models.DetectionOptions(job.DetectionOptions)
+	if options.IsZero() {
+		return nil
+	}
+	return &options
+}
@@ -1,9 +1,8 @@
 package queue
 
 import (
-	"encoding/json"
 	"fmt"
-	"time"
+	"sync"
 
 	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
 	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
@@ -15,14 +14,32 @@ import (
 type Client interface {
 	PublishJob(job *models.Job) error
 	ConsumeJobs(handler func(*models.Job) error) error
+	ConsumeResults(handler func(*models.ProcessingResult) error) error
+	PublishResult(result *models.ProcessingResult) error
+	PublishComposition(message *models.CompositionMessage) error
+	ConsumeCompositions(handler func(*models.CompositionMessage) error) error
+	PublishSplit(message *models.SplitMessage) error
+	ConsumeSplits(handler func(*models.SplitMessage) error) error
 	Close() error
 }
 
 type RabbitMQClient struct {
-	conn      *amqp.Connection
-	channel   *amqp.Channel
-	exchange  string
-	queueName string
+	conn                  *amqp.Connection
+	exchange              string
+	queueName             string
+	resultsQueue          string
+	resultsRoutingKey     string
+	compositionQueue      string
+	compositionRoutingKey string
+	splitQueue            string
+	splitRoutingKey       string
+
+	// channel is used to publish, a channel is not safe for concurrent use so
+	// publishes are serialized by publishMu. Consumers have channels of their own.
+	channel     *amqp.Channel
+	publishMu   sync.Mutex
+	consumersMu sync.Mutex
+	consumers   []*amqp.Channel
 }
 
 func NewRabbitMQClient(cfg utils.RabbitMQConfig) (Client, error) {
@@ -82,35 +99,116 @@ func NewRabbitMQClient(cfg utils.RabbitMQConfig) (Client, error) {
 		return nil, fmt.Errorf("failed to bind queue: %w", err)
 	}
 
+	// Declare and bind the queue the detection workers publish results to
+	resultsQueue, err := channel.QueueDeclare(
+		cfg.ResultsQueue, // name
+		true,             // durable
+		false,            // delete when unused
+		false,            // exclusive
+		false,            // no-wait
+		nil,              // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare results queue: %w", err)
+	}
+
+	err = channel.QueueBind(
+		resultsQueue.Name,     // queue name
+		cfg.ResultsRoutingKey, // routing key
+		cfg.Exchange,          // exchange
+		false,
+		nil,
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to bind results queue: %w", err)
+	}
+
+	// Declare and bind the queue the compositor consumes
+	compositionQueue, err := channel.QueueDeclare(
+		cfg.CompositionQueue, // name
+		true,                 // durable
+		false,                // delete when unused
+		false,                // exclusive
+		false,                // no-wait
+		nil,                  // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare composition queue: %w", err)
+	}
+
+	err = channel.QueueBind(
+		compositionQueue.Name,     // queue name
+		cfg.CompositionRoutingKey, // routing key
+		cfg.Exchange,              // exchange
+		false,
+		nil,
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to bind composition queue: %w", err)
+	}
+
+	// Declare and bind the queue tiled uploads are split from
+	splitQueue, err := channel.QueueDeclare(
+		cfg.SplitQueue, // name
+		true,           // durable
+		false,          // delete when unused
+		false,          // exclusive
+		false,          // no-wait
+		nil,            // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare split queue: %w", err)
+	}
+
+	err = channel.QueueBind(
+		splitQueue.Name,     // queue name
+		cfg.SplitRoutingKey, // routing key
+		cfg.Exchange,        // exchange
+		false,
+		nil,
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to bind split queue: %w", err)
+	}
+
 	return &RabbitMQClient{
-		conn:      conn,
-		channel:   channel,
-		exchange:  cfg.Exchange,
-		queueName: queue.Name,
+		conn:                  conn,
+		channel:               channel,
+		exchange:              cfg.Exchange,
+		queueName:             queue.Name,
+		resultsQueue:          resultsQueue.Name,
+		resultsRoutingKey:     cfg.ResultsRoutingKey,
+		compositionQueue:      compositionQueue.Name,
+		compositionRoutingKey: cfg.CompositionRoutingKey,
+		splitQueue:            splitQueue.Name,
+		splitRoutingKey:       cfg.SplitRoutingKey,
 	}, nil
 }
 
 func (c *RabbitMQClient) PublishJob(job *models.Job) error {
-	// Serialize job to JSON
-	body, err := json.Marshal(job)
+	// Stamp the contract version on a copy, callers keep their job as is
+	message := *job
+	message.SchemaVersion = SchemaVersion
+	body, err := encode(MessageTypeJob, &message)
 	if err != nil {
-		return fmt.Errorf("failed to marshal job: %w", err)
+		return err
 	}
 	fmt.Printf("Published job: %s", string(body))
 
 	// Publish message
-	err = c.channel.Publish(
-		c.exchange,  // exchange
-		"detection", // routing key
-		false,       // mandatory
-		false,       // immediate
-		amqp.Publishing{
-			ContentType:  "application/json",
-			Body:         body,
-			DeliveryMode: amqp.Persistent, // Make message persistent
-			Timestamp:    time.Now(),
-		},
-	)
+	err = c.publish("detection", publishing(MessageTypeJob, body))
 	if err != nil {
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
@@ -119,14 +217,13 @@ func (c *RabbitMQClient) PublishJob(job *models.Job) error {
 }
 
 func (c *RabbitMQClient) ConsumeJobs(handler func(*models.Job) error) error {
-	// Set QoS to process one message at a time
-	err := c.channel.Qos(1, 0, false)
+	channel, err := c.consumerChannel()
 	if err != nil {
-		return fmt.Errorf("failed to set QoS: %w", err)
+		return err
 	}
 
 	// Start consuming messages
-	msgs, err := c.channel.Consume(
+	msgs, err := channel.Consume(
 		c.queueName, // queue
 		"",          // consumer
 		false,       // auto-ack
@@ -143,8 +240,8 @@ func (c *RabbitMQClient) ConsumeJobs(handler func(*models.Job) error) error {
 	go func() {
 		for msg := range msgs {
 			var job models.Job
-			if err := json.Unmarshal(msg.Body, &job); err != nil {
-				fmt.Printf("Failed to unmarshal job: %v\n", err)
+			if err := decode(MessageTypeJob, msg, &job); err != nil {
+				fmt.Printf("Failed to decode job: %v\n", err)
 				msg.Nack(false, false) // Reject message
 				continue
 			}
@@ -164,7 +261,220 @@ func (c *RabbitMQClient) ConsumeJobs(handler func(*models.Job) error) error {
 	return nil
 }
 
+func (c *RabbitMQClient) ConsumeResults(handler func(*models.ProcessingResult) error) error {
+	channel, err := c.consumerChannel()
+	if err != nil {
+		return err
+	}
+
+	msgs, err := channel.Consume(
+		c.resultsQueue, // queue
+		"",             // consumer
+		false,          // auto-ack
+		false,          // exclusive
+		false,          // no-local
+		false,          // no-wait
+		nil,            // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register results consumer: %w", err)
+	}
+
+	go func() {
+		for msg := range msgs {
+			var result models.ProcessingResult
+			if err := decode(MessageTypeResult, msg, &result); err != nil {
+				fmt.Printf("Failed to decode result: %v\n", err)
+				msg.Nack(false, false) // Reject message
+				continue
+			}
+
+			if err := handler(&result); err != nil {
+				fmt.Printf("Failed to process result for job %s: %v\n", result.JobID, err)
+				msg.Nack(false, true) // Reject and requeue
+				continue
+			}
+
+			msg.Ack(false)
+		}
+	}()
+
+	return nil
+}
+
+// PublishResult publishes a detection result the way the detection workers
+// do, for workers written in Go
+func (c *RabbitMQClient) PublishResult(result *models.ProcessingResult) error {
+	stamped := *result
+	stamped.SchemaVersion = SchemaVersion
+	body, err := encode(MessageTypeResult, &stamped)
+	if err != nil {
+		return err
+	}
+
+	err = c.publish(c.resultsRoutingKey, publishing(MessageTypeResult, body))
+	if err != nil {
+		return fmt.Errorf("failed to publish result: %w", err)
+	}
+
+	return nil
+}
+
+func (c *RabbitMQClient) PublishComposition(message *models.CompositionMessage) error {
+	stamped := *message
+	stamped.SchemaVersion = SchemaVersion
+	body, err := encode(MessageTypeComposition, &stamped)
+	if err != nil {
+		return err
+	}
+
+	err = c.publish(c.compositionRoutingKey, publishing(MessageTypeComposition, body))
+	if err != nil {
+		return fmt.Errorf("failed to publish composition: %w", err)
+	}
+
+	return nil
+}
+
+func (c *RabbitMQClient) ConsumeCompositions(handler func(*models.CompositionMessage) error) error {
+	channel, err := c.consumerChannel()
+	if err != nil {
+		return err
+	}
+
+	msgs, err := channel.Consume(
+		c.compositionQueue, // queue
+		"",                 // consumer
+		false,              // auto-ack
+		false,              // exclusive
+		false,              // no-local
+		false,              // no-wait
+		nil,                // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register composition consumer: %w", err)
+	}
+
+	go func() {
+		for msg := range msgs {
+			var message models.CompositionMessage
+			if err := decode(MessageTypeComposition, msg, &message); err != nil {
+				fmt.Printf("Failed to decode composition: %v\n", err)
+				msg.Nack(false, false) // Reject message
+				continue
+			}
+
+			if err := handler(&message); err != nil {
+				fmt.Printf("Failed to process composition %s: %v\n", message.CompositionID, err)
+				msg.Nack(false, true) // Reject and requeue
+				continue
+			}
+
+			msg.Ack(false)
+		}
+	}()
+
+	return nil
+}
+
+func (c *RabbitMQClient) PublishSplit(message *models.SplitMessage) error {
+	stamped := *message
+	stamped.SchemaVersion = SchemaVersion
+	body, err := encode(MessageTypeSplit, &stamped)
+	if err != nil {
+		return err
+	}
+
+	err = c.publish(c.splitRoutingKey, publishing(MessageTypeSplit, body))
+	if err != nil {
+		return fmt.Errorf("failed to publish split: %w", err)
+	}
+
+	return nil
+}
+
+func (c *RabbitMQClient) ConsumeSplits(handler func(*models.SplitMessage) error) error {
+	channel, err := c.consumerChannel()
+	if err != nil {
+		return err
+	}
+
+	msgs, err := channel.Consume(
+		c.splitQueue, // queue
+		"",           // consumer
+		false,        // auto-ack
+		false,        // exclusive
+		false,        // no-local
+		false,        // no-wait
+		nil,          // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register split consumer: %w", err)
+	}
+
+	go func() {
+		for msg := range msgs {
+			var message models.SplitMessage
+			if err := decode(MessageTypeSplit, msg, &message); err != nil {
+				fmt.Printf("Failed to decode split: %v\n", err)
+				msg.Nack(false, false) // Reject message
+				continue
+			}
+
+			if err := handler(&message); err != nil {
+				fmt.Printf("Failed to split job %s: %v\n", message.JobID, err)
+				msg.Nack(false, true) // Reject and requeue
+				continue
+			}
+
+			msg.Ack(false)
+		}
+	}()
+
+	return nil
+}
+
+// consumerChannel opens the channel of a consumer, so its deliveries and
+// prefetch limit are not shared with other consumers or with publishing
+func (c *RabbitMQClient) consumerChannel() (*amqp.Channel, error) {
+	channel, err := c.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	// Set QoS to process one message at a time
+	if err := channel.Qos(1, 0, false); err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("failed to set QoS: %w", err)
+	}
+
+	c.consumersMu.Lock()
+	c.consumers = append(c.consumers, channel)
+	c.consumersMu.Unlock()
+	return channel, nil
+}
+
+// publish sends a message on the publishing channel. Handlers publish from
+// their delivery goroutines, so publishes are serialized.
+func (c *RabbitMQClient) publish(routingKey string, msg amqp.Publishing) error {
+	c.publishMu.Lock()
+	defer c.publishMu.Unlock()
+	return c.channel.Publish(
+		c.exchange, // exchange
+		routingKey, // routing key
+		false,      // mandatory
+		false,      // immediate
+		msg,
+	)
+}
+
 func (c *RabbitMQClient) Close() error {
+	c.consumersMu.Lock()
+	for _, channel := range c.consumers {
+		channel.Close()
+	}
+	c.consumers = nil
+	c.consumersMu.Unlock()
 	if c.channel != nil {
 		c.channel.Close()
 	}
@@ -0,0 +1,179 @@
+package reaper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/tiling"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	actionRequeued = "requeued"
+	actionFailed   = "failed"
+)
+
+var jobsRecovered = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "logo_preserve_reaper_jobs_recovered_total",
+	Help: "Stuck jobs recovered by the reaper, by original status and action taken.",
+}, []string{"status", "action"})
+
+// Reaper recovers jobs that stayed pending or processing for longer than the
+// configured timeout, e.g. because the worker crashed after acking or the
+// result message was lost. Depending on policy it re-enqueues or fails them.
+type Reaper struct {
+	config      utils.ReaperConfig
+	store       db.Store
+	queueClient queue.Client
+	lock        *queue.RedisLock
+}
+
+func NewReaper(config utils.ReaperConfig, store db.Store, queueClient queue.Client, lock *queue.RedisLock) *Reaper {
+	return &Reaper{
+		config:      config,
+		store:       store,
+		queueClient: queueClient,
+		lock:        lock,
+	}
+}
+
+// Run reaps on every interval until ctx is cancelled
+func (r *Reaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			leader, err := r.lock.Acquire(ctx, r.config.Interval)
+			if err != nil {
+				logrus.WithError(err).Error("Reaper failed to acquire lock")
+				continue
+			}
+			if leader {
+				r.Reap(ctx)
+			}
+		}
+	}
+}
+
+// Reap handles one batch of stale jobs for every status with a timeout
+func (r *Reaper) Reap(ctx context.Context) {
+	statuses := make([]string, 0, len(r.config.Timeouts))
+	for status := range r.config.Timeouts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	for _, status := range statuses {
+		timeout := r.config.Timeouts[status]
+		cutoff := time.Now().Add(-timeout)
+
+		jobs, err := r.store.ListStaleJobs(ctx, db.ListStaleJobsParams{
+			Status:    status,
+			UpdatedAt: cutoff,
+			Limit:     r.config.BatchSize,
+		})
+		if err != nil {
+			logrus.WithError(err).WithField("status", status).Error("Failed to list stale jobs")
+			continue
+		}
+
+		for _, job := range jobs {
+			if err := r.recover(ctx, job, cutoff, timeout); err != nil {
+				logrus.WithError(err).WithField("job_id", job.ID).Error("Failed to recover stale job")
+			}
+		}
+	}
+}
+
+func (r *Reaper) recover(ctx context.Context, job db.Job, cutoff time.Time, timeout time.Duration) error {
+	if r.config.Policy == utils.ReaperPolicyRequeue && job.RequeueCount < r.config.MaxRequeues {
+		return r.requeue(ctx, job, cutoff)
+	}
+
+	reason := fmt.Sprintf("Job stuck in %s for more than %s", job.Status, timeout)
+	if r.config.Policy == utils.ReaperPolicyRequeue {
+		reason = fmt.Sprintf("%s after %d requeues", reason, job.RequeueCount)
+	}
+	return r.fail(ctx, job, cutoff, reason)
+}
+
+func (r *Reaper) requeue(ctx context.Context, job db.Job, cutoff time.Time) error {
+	requeued, err := r.store.TransitionJobTx(ctx, db.TransitionJobTxParams{
+		JobID:       job.ID,
+		ToStatus:    models.JobStatusPending,
+		Actor:       "reaper",
+		Reason:      fmt.Sprintf("Requeued after being stuck in %s", job.Status),
+		Requeue:     true,
+		StaleBefore: cutoff,
+	})
+	if err != nil {
+		// The job moved on since it was listed, nothing to recover
+		if errors.Is(err, db.ErrJobNotStale) || errors.Is(err, db.ErrInvalidTransition) {
+			return nil
+		}
+		return err
+	}
+
+	if err := r.republish(ctx, requeued); err != nil {
+		return err
+	}
+
+	jobsRecovered.WithLabelValues(job.Status, actionRequeued).Inc()
+	logrus.WithFields(logrus.Fields{
+		"job_id":        job.ID,
+		"status":        job.Status,
+		"requeue_count": requeued.RequeueCount,
+	}).Warn("Requeued stuck job")
+	return nil
+}
+
+// republish queues the job again, or for a tiled job its split or every
+// tile that has not completed yet
+func (r *Reaper) republish(ctx context.Context, job db.Job) error {
+	if job.Tiled {
+		return tiling.Requeue(ctx, r.store, r.queueClient, job)
+	}
+	if err := r.queueClient.PublishJob(queue.NewJobMessage(job)); err != nil {
+		return fmt.Errorf("failed to republish job: %w", err)
+	}
+	return nil
+}
+
+func (r *Reaper) fail(ctx context.Context, job db.Job, cutoff time.Time, reason string) error {
+	_, err := r.store.TransitionJobTx(ctx, db.TransitionJobTxParams{
+		JobID:        job.ID,
+		ToStatus:     models.JobStatusFailed,
+		Actor:        "reaper",
+		Reason:       reason,
+		ErrorMessage: sql.NullString{String: reason, Valid: true},
+		StaleBefore:  cutoff,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrJobNotStale) || errors.Is(err, db.ErrInvalidTransition) {
+			return nil
+		}
+		return err
+	}
+
+	jobsRecovered.WithLabelValues(job.Status, actionFailed).Inc()
+	logrus.WithFields(logrus.Fields{
+		"job_id": job.ID,
+		"status": job.Status,
+		"reason": reason,
+	}).Warn("Failed stuck job")
+	return nil
+}
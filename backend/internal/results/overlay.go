@@ -0,0 +1,76 @@
+package results
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"image"
+	"image/png"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/google/uuid"
+)
+
+// OverlayKey is where the overlay of a job is stored
+func OverlayKey(jobID uuid.UUID) string {
+	return fmt.Sprintf("results/%s/overlay.png", jobID)
+}
+
+// Renderer draws the detections of a job on its original image, so users get
+// a visual summary of the result
+type Renderer struct {
+	store         db.Store
+	storageClient storage.Client
+}
+
+func NewRenderer(store db.Store, storageClient storage.Client) *Renderer {
+	return &Renderer{
+		store:         store,
+		storageClient: storageClient,
+	}
+}
+
+// Render uploads an overlay of the logos on the job's original image and
+// stores its key as the job's result URL
+func (r *Renderer) Render(ctx context.Context, job db.Job, logos []models.LogoDetection) (string, error) {
+	body, err := r.storageClient.DownloadFile(ctx, job.S3Key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	img, err := imaging.Decode(body)
+	if err != nil {
+		return "", err
+	}
+
+	boxes := make([]imaging.OverlayBox, 0, len(logos))
+	for _, logo := range logos {
+		box := logo.BoundingBox
+		boxes = append(boxes, imaging.OverlayBox{
+			Rect:       image.Rect(box.X, box.Y, box.X+box.Width, box.Y+box.Height),
+			Label:      logo.LogoType,
+			Confidence: logo.Confidence,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, imaging.DrawOverlay(img, boxes)); err != nil {
+		return "", fmt.Errorf("failed to encode overlay: %w", err)
+	}
+
+	key := OverlayKey(job.ID)
+	if _, err := r.storageClient.UploadFile(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		return "", err
+	}
+
+	err = r.store.UpdateJobResultUrl(ctx, db.UpdateJobResultUrlParams{
+		ID:        job.ID,
+		ResultUrl: sql.NullString{String: key, Valid: true},
+	})
+	return key, err
+}
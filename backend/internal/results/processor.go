@@ -0,0 +1,196 @@
+package results
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/brands"
+	dbtypes "github.com/Viczdera/ai-logo-preserve/backend/internal/db"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/extraction"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const actor = "worker"
+
+// Processor applies detection results published by the workers to the jobs
+// they belong to. Results that the job state machine rejects, such as a late
+// failure for a job that already completed, are logged and dropped.
+type Processor struct {
+	store   db.Store
+	matcher *brands.Matcher
+	// renderer is nil when overlays are disabled
+	renderer  *Renderer
+	rectifier *extraction.Rectifier
+	// masker is nil when background removal is disabled
+	masker *extraction.Masker
+	// palettes is nil when palette extraction is disabled
+	palettes *extraction.PaletteExtractor
+	// vectorizer is nil when vectorization is disabled
+	vectorizer *extraction.Vectorizer
+	// mergeThreshold is the overlap above which detections of neighboring tiles are duplicates
+	mergeThreshold float64
+}
+
+func NewProcessor(store db.Store, matcher *brands.Matcher, renderer *Renderer, rectifier *extraction.Rectifier, masker *extraction.Masker, palettes *extraction.PaletteExtractor, vectorizer *extraction.Vectorizer, tiling utils.TilingConfig) *Processor {
+	return &Processor{
+		store:          store,
+		matcher:        matcher,
+		renderer:       renderer,
+		rectifier:      rectifier,
+		masker:         masker,
+		palettes:       palettes,
+		vectorizer:     vectorizer,
+		mergeThreshold: tiling.MergeThreshold,
+	}
+}
+
+// Handle is the queue handler for ProcessingResult messages. Returning an
+// error requeues the message, so only transient failures are returned.
+func (p *Processor) Handle(result *models.ProcessingResult) error {
+	ctx := context.Background()
+
+	jobID := result.JobID
+	if jobID == uuid.Nil {
+		logrus.Error("Dropping result without job ID")
+		return nil
+	}
+
+	// Tiles of a tiled job are detected as jobs of their own
+	tile, err := p.store.GetJobTile(ctx, jobID)
+	switch {
+	case err == nil:
+		err = p.handleTile(ctx, tile, result)
+	case errors.Is(err, sql.ErrNoRows):
+		err = p.handleJob(ctx, jobID, result)
+	}
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, db.ErrInvalidTransition):
+		logrus.WithError(err).WithField("job_id", jobID).Warn("Ignoring result rejected by job state machine")
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		logrus.WithField("job_id", jobID).Warn("Ignoring result for unknown job")
+		return nil
+	default:
+		return err
+	}
+}
+
+func (p *Processor) handleJob(ctx context.Context, jobID uuid.UUID, result *models.ProcessingResult) error {
+	switch result.Status {
+	case models.JobStatusCompleted:
+		return p.complete(ctx, jobID, result)
+	case models.JobStatusFailed, models.JobStatusProcessing:
+		_, err := p.store.TransitionJobTx(ctx, db.TransitionJobTxParams{
+			JobID:        jobID,
+			ToStatus:     result.Status,
+			Actor:        actor,
+			Reason:       fmt.Sprintf("Detection worker reported %s", result.Status),
+			ErrorMessage: failureMessage(result),
+		})
+		return err
+	default:
+		logrus.WithFields(logrus.Fields{
+			"job_id": jobID,
+			"status": result.Status,
+		}).Error("Dropping result with unknown status")
+		return nil
+	}
+}
+
+func (p *Processor) complete(ctx context.Context, jobID uuid.UUID, result *models.ProcessingResult) error {
+	logos := make([]db.CreateLogoParams, 0, len(result.LogosFound))
+	for _, logo := range result.LogosFound {
+		// Bad geometry from the detector should not cost us the detection itself
+		var geometry models.Geometry
+		if logo.Geometry != nil {
+			if err := logo.Geometry.Validate(); err != nil {
+				logrus.WithError(err).WithField("job_id", jobID).Warn("Dropping invalid logo geometry")
+			} else {
+				geometry = *logo.Geometry
+			}
+		}
+
+		logos = append(logos, db.CreateLogoParams{
+			JobID:       jobID,
+			BoundingBox: dbtypes.BoundingBox(logo.BoundingBox),
+			Confidence:  float32(logo.Confidence),
+			LogoType:    logo.LogoType,
+			S3Key:       logo.S3Key,
+			Geometry:    dbtypes.Geometry(geometry),
+		})
+	}
+
+	completed, err := p.store.CompleteJobTx(ctx, db.CompleteJobTxParams{
+		JobID:        jobID,
+		Actor:        actor,
+		ResultUrl:    sql.NullString{String: result.ResultURL, Valid: result.ResultURL != ""},
+		Logos:        logos,
+		ModelVersion: result.ModelVersion,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The job is done either way, a failed brand match must not requeue the result
+	if err := p.matcher.MatchLogos(ctx, completed.Logos); err != nil {
+		logrus.WithError(err).WithField("job_id", jobID).Error("Failed to match logos to brands")
+	}
+
+	// Logos are created in the order of the result
+	for i, logo := range completed.Logos {
+		detection := result.LogosFound[i]
+		if p.masker != nil {
+			masked, err := p.masker.Mask(ctx, logo, detection.MaskS3Key)
+			if err != nil {
+				logrus.WithError(err).WithField("logo_id", logo.ID).Warn("Failed to mask logo background")
+			} else {
+				logo = masked
+			}
+		}
+		// After masking, so the background does not count towards the palette
+		if p.palettes != nil {
+			if _, err := p.palettes.Extract(ctx, logo); err != nil {
+				logrus.WithError(err).WithField("logo_id", logo.ID).Warn("Failed to extract logo palette")
+			}
+		}
+		if p.vectorizer != nil {
+			if _, err := p.vectorizer.Vectorize(ctx, logo); err != nil {
+				logrus.WithError(err).WithField("logo_id", logo.ID).Warn("Failed to vectorize logo")
+			}
+		}
+		if len(detection.Corners) > 0 {
+			if _, err := p.rectifier.Rectify(ctx, logo, detection.Corners); err != nil {
+				logrus.WithError(err).WithField("logo_id", logo.ID).Warn("Failed to rectify logo")
+			}
+		}
+	}
+
+	// Keep a result URL the worker provided
+	if p.renderer != nil && result.ResultURL == "" {
+		if _, err := p.renderer.Render(ctx, completed.Job, result.LogosFound); err != nil {
+			logrus.WithError(err).WithField("job_id", jobID).Error("Failed to render result overlay")
+		}
+	}
+	return nil
+}
+
+// failureMessage is the error stored on a job failed by a result, the
+// worker's own reason when it sent one
+func failureMessage(result *models.ProcessingResult) sql.NullString {
+	if result.Status != models.JobStatusFailed {
+		return sql.NullString{}
+	}
+	if result.Error != "" {
+		return sql.NullString{String: result.Error, Valid: true}
+	}
+	return sql.NullString{String: "Detection worker failed to process the image", Valid: true}
+}
@@ -0,0 +1,93 @@
+package results
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/tiling"
+	"github.com/sirupsen/logrus"
+)
+
+// handleTile records the result of one tile. The first tile to start moves
+// the job to processing, a failed tile fails the job, and the last tile to
+// complete merges all tiles into the job's result.
+func (p *Processor) handleTile(ctx context.Context, tile db.JobTile, result *models.ProcessingResult) error {
+	switch result.Status {
+	case models.JobStatusProcessing:
+		job, err := p.store.GetJob(ctx, tile.JobID)
+		if err != nil || job.Status != models.JobStatusPending {
+			return err
+		}
+		_, err = p.store.TransitionJobTx(ctx, db.TransitionJobTxParams{
+			JobID:    tile.JobID,
+			ToStatus: models.JobStatusProcessing,
+			Actor:    actor,
+			Reason:   fmt.Sprintf("Detection worker started tile %d", tile.TileIndex),
+		})
+		return err
+	case models.JobStatusCompleted, models.JobStatusFailed:
+	default:
+		logrus.WithFields(logrus.Fields{
+			"job_id":  tile.JobID,
+			"tile_id": tile.ID,
+			"status":  result.Status,
+		}).Error("Dropping tile result with unknown status")
+		return nil
+	}
+
+	detections, err := json.Marshal(result.LogosFound)
+	if err != nil {
+		return fmt.Errorf("failed to encode tile detections: %w", err)
+	}
+	updated, err := p.store.UpdateJobTileTx(ctx, db.UpdateJobTileResultParams{
+		ID:           tile.ID,
+		Status:       result.Status,
+		Detections:   detections,
+		ModelVersion: sql.NullString{String: result.ModelVersion, Valid: result.ModelVersion != ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Status == models.JobStatusFailed {
+		_, err = p.store.TransitionJobTx(ctx, db.TransitionJobTxParams{
+			JobID:        tile.JobID,
+			ToStatus:     models.JobStatusFailed,
+			Actor:        actor,
+			Reason:       fmt.Sprintf("Detection worker failed tile %d", tile.TileIndex),
+			ErrorMessage: failureMessage(result),
+		})
+		return err
+	}
+
+	for _, other := range updated.Tiles {
+		if other.Status != models.JobStatusCompleted {
+			return nil
+		}
+	}
+
+	logos, err := tiling.MergeTiles(tile.JobID, updated.Tiles, p.mergeThreshold)
+	if err != nil {
+		// Stored detections that do not decode will not decode on a retry either
+		logrus.WithError(err).WithField("job_id", tile.JobID).Error("Failed to merge tile detections")
+		return nil
+	}
+	logrus.WithFields(logrus.Fields{
+		"job_id": tile.JobID,
+		"tiles":  len(updated.Tiles),
+		"logos":  len(logos),
+	}).Info("Merged tile detections")
+
+	return p.complete(ctx, tile.JobID, &models.ProcessingResult{
+		JobID:        tile.JobID,
+		Status:       models.JobStatusCompleted,
+		LogosFound:   logos,
+		ProcessedAt:  time.Now(),
+		ModelVersion: result.ModelVersion,
+	})
+}
@@ -0,0 +1,207 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/cleanup"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// maxBatchesPerSweep bounds how much work one sweep does per status
+const maxBatchesPerSweep = 50
+
+var errLostLease = errors.New("retention sweeper lost its lock")
+
+// Sweeper periodically deletes jobs, originals and crops that are older than
+// the retention rule for their status. Only the replica holding the lock sweeps.
+type Sweeper struct {
+	config utils.RetentionConfig
+	store  db.Store
+	purger *cleanup.Purger
+	lock   *queue.RedisLock
+}
+
+// Report describes what a sweep deleted, or would delete in dry-run mode.
+// Failed lists the jobs that could not be purged; they are retried on the
+// next sweep and do not hold up the jobs after them.
+type Report struct {
+	Status string
+	Cutoff time.Time
+	Count  int64
+	JobIDs []uuid.UUID
+	Failed []uuid.UUID
+}
+
+func NewSweeper(config utils.RetentionConfig, store db.Store, purger *cleanup.Purger, lock *queue.RedisLock) *Sweeper {
+	return &Sweeper{
+		config: config,
+		store:  store,
+		purger: purger,
+		lock:   lock,
+	}
+}
+
+// Run sweeps on every interval until ctx is cancelled
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.sweepIfLeader(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sweeper) sweepIfLeader(ctx context.Context) {
+	// Hold the lease for a whole interval so other replicas skip this round,
+	// purging renews it for as long as the sweep runs
+	leader, err := s.lock.Acquire(ctx, s.config.Interval)
+	if err != nil {
+		logrus.WithError(err).Error("Retention sweeper failed to acquire lock")
+		return
+	}
+	if !leader {
+		return
+	}
+
+	for _, report := range s.Sweep(ctx) {
+		entry := logrus.WithFields(logrus.Fields{
+			"status":  report.Status,
+			"cutoff":  report.Cutoff.Format(time.RFC3339),
+			"count":   report.Count,
+			"job_ids": report.JobIDs,
+		})
+		if len(report.Failed) > 0 {
+			entry.WithField("failed_job_ids", report.Failed).Warn("Retention sweep failed to purge some jobs")
+		}
+		if s.config.DryRun {
+			entry.Info("Retention dry run: jobs that would be deleted")
+		} else if report.Count > 0 {
+			entry.Info("Retention sweep deleted expired jobs")
+		}
+	}
+}
+
+// Sweep applies the retention rule of every job status once
+func (s *Sweeper) Sweep(ctx context.Context) []Report {
+	reports := []Report{}
+	now := time.Now()
+
+	for _, status := range models.JobStatuses {
+		cutoff := now.Add(-s.config.TTLFor(status))
+		importedCutoff := now.Add(-s.config.ImportedTTLFor(status))
+
+		var (
+			report Report
+			err    error
+		)
+		if s.config.DryRun {
+			report, err = s.preview(ctx, status, cutoff, importedCutoff)
+		} else {
+			report, err = s.purge(ctx, status, cutoff, importedCutoff)
+		}
+		if err != nil {
+			logrus.WithError(err).WithField("status", status).Error("Retention sweep failed")
+		}
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
+// renew extends the lease and reports whether this replica still holds it.
+// Sweepers built without a lock always sweep.
+func (s *Sweeper) renew(ctx context.Context) bool {
+	if s.lock == nil {
+		return true
+	}
+	leader, err := s.lock.Acquire(ctx, s.config.Interval)
+	if err != nil {
+		logrus.WithError(err).Error("Retention sweeper failed to renew lock")
+		return false
+	}
+	return leader
+}
+
+func (s *Sweeper) preview(ctx context.Context, status string, cutoff, importedCutoff time.Time) (Report, error) {
+	report := Report{Status: status, Cutoff: cutoff, JobIDs: []uuid.UUID{}}
+
+	count, err := s.store.CountExpiredJobs(ctx, db.CountExpiredJobsParams{
+		Status:            status,
+		CreatedAt:         cutoff,
+		ImportedCreatedAt: importedCutoff,
+	})
+	if err != nil {
+		return report, err
+	}
+	report.Count = count
+
+	jobs, err := s.store.ListExpiredJobs(ctx, db.ListExpiredJobsParams{
+		Status:            status,
+		CreatedAt:         cutoff,
+		ImportedCreatedAt: importedCutoff,
+		ResultLimit:       s.config.BatchSize,
+	})
+	if err != nil {
+		return report, err
+	}
+	for _, job := range jobs {
+		report.JobIDs = append(report.JobIDs, job.ID)
+	}
+
+	return report, nil
+}
+
+func (s *Sweeper) purge(ctx context.Context, status string, cutoff, importedCutoff time.Time) (Report, error) {
+	report := Report{Status: status, Cutoff: cutoff, JobIDs: []uuid.UUID{}, Failed: []uuid.UUID{}}
+
+	// Page by (created_at, id) so jobs that fail to purge are stepped over
+	// instead of being listed first in every batch
+	params := db.ListExpiredJobsParams{
+		Status:            status,
+		CreatedAt:         cutoff,
+		ImportedCreatedAt: importedCutoff,
+		ResultLimit:       s.config.BatchSize,
+	}
+	for batch := 0; batch < maxBatchesPerSweep; batch++ {
+		// Renew the lease per page so a long sweep is not taken over midway
+		if !s.renew(ctx) {
+			return report, errLostLease
+		}
+
+		jobs, err := s.store.ListExpiredJobs(ctx, params)
+		if err != nil {
+			return report, err
+		}
+
+		for _, job := range jobs {
+			if _, err := s.purger.PurgeJob(ctx, job.ID); err != nil {
+				logrus.WithError(err).WithField("job_id", job.ID).Error("Retention sweep failed to purge job")
+				report.Failed = append(report.Failed, job.ID)
+				continue
+			}
+			report.Count++
+			report.JobIDs = append(report.JobIDs, job.ID)
+		}
+
+		if len(jobs) < int(s.config.BatchSize) {
+			break
+		}
+		last := jobs[len(jobs)-1]
+		params.AfterCreatedAt, params.AfterID = last.CreatedAt, last.ID
+	}
+
+	return report, nil
+}
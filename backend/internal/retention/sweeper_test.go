@@ -0,0 +1,124 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/cleanup"
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// expiredStore lists its jobs in (created_at, id) order like the query and
+// fails to delete the broken ones
+type expiredStore struct {
+	db.Store
+	jobs   []db.Job
+	broken map[uuid.UUID]bool
+}
+
+func (s *expiredStore) ListExpiredJobs(ctx context.Context, arg db.ListExpiredJobsParams) ([]db.Job, error) {
+	jobs := []db.Job{}
+	for _, job := range s.jobs {
+		after := job.CreatedAt.After(arg.AfterCreatedAt) ||
+			(job.CreatedAt.Equal(arg.AfterCreatedAt) && job.ID.String() > arg.AfterID.String())
+		if job.Status == arg.Status && after && len(jobs) < int(arg.ResultLimit) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (s *expiredStore) DeleteJobTx(ctx context.Context, jobID uuid.UUID) (db.DeleteJobTxResult, error) {
+	if s.broken[jobID] {
+		return db.DeleteJobTxResult{}, errors.New("constraint violation")
+	}
+	for i, job := range s.jobs {
+		if job.ID == jobID {
+			s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+			return db.DeleteJobTxResult{Job: job}, nil
+		}
+	}
+	return db.DeleteJobTxResult{}, errors.New("not found")
+}
+
+type emptyStorage struct {
+	storage.Client
+}
+
+func (emptyStorage) ListFiles(ctx context.Context, prefix string) ([]string, error) {
+	return nil, nil
+}
+
+func TestPurgeContinuesPastFailures(t *testing.T) {
+	created := time.Now().Add(-48 * time.Hour)
+	store := &expiredStore{broken: map[uuid.UUID]bool{}}
+	for i := 0; i < 5; i++ {
+		store.jobs = append(store.jobs, db.Job{
+			ID:        uuid.New(),
+			Status:    models.JobStatusFailed,
+			S3Key:     "original/job/image.png",
+			CreatedAt: created.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	// The oldest jobs fail every time, they must not block the rest
+	broken := []uuid.UUID{store.jobs[0].ID, store.jobs[1].ID}
+	for _, id := range broken {
+		store.broken[id] = true
+	}
+
+	config := utils.RetentionConfig{BatchSize: 2}
+	sweeper := NewSweeper(config, store, cleanup.NewPurger(store, emptyStorage{}, nil), nil)
+	report, err := sweeper.purge(context.Background(), models.JobStatusFailed, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	require.Equal(t, int64(3), report.Count)
+	require.ElementsMatch(t, broken, report.Failed)
+	require.Len(t, store.jobs, 2)
+}
+
+func TestPurgeStopsWhenLeaseIsLost(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	lock := queue.NewRedisLock(redisClient, "retention-sweeper")
+	other := queue.NewRedisLock(redisClient, "retention-sweeper")
+
+	store := &expiredStore{broken: map[uuid.UUID]bool{}}
+	for i := 0; i < 3; i++ {
+		store.jobs = append(store.jobs, db.Job{
+			ID:        uuid.New(),
+			Status:    models.JobStatusFailed,
+			S3Key:     "original/job/image.png",
+			CreatedAt: time.Now().Add(-48*time.Hour + time.Duration(i)*time.Minute),
+		})
+	}
+	config := utils.RetentionConfig{BatchSize: 2, Interval: time.Hour}
+	sweeper := NewSweeper(config, store, cleanup.NewPurger(store, emptyStorage{}, nil), lock)
+
+	// Another replica took the lock after this one's lease ran out
+	held, err := other.Acquire(context.Background(), time.Hour)
+	require.NoError(t, err)
+	require.True(t, held)
+	_, err = sweeper.purge(context.Background(), models.JobStatusFailed, time.Now(), time.Now())
+	require.ErrorIs(t, err, errLostLease)
+	require.Len(t, store.jobs, 3)
+
+	// The leader renews its lease on every page
+	require.NoError(t, other.Release(context.Background()))
+	held, err = lock.Acquire(context.Background(), time.Minute)
+	require.NoError(t, err)
+	require.True(t, held)
+	report, err := sweeper.purge(context.Background(), models.JobStatusFailed, time.Now(), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, int64(3), report.Count)
+	require.Equal(t, time.Hour, redisServer.TTL("logo-preserve:lock:retention-sweeper"))
+}
@@ -0,0 +1,72 @@
+package similarity
+
+import (
+	"image"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+)
+
+const (
+	AlgorithmPHash = "phash"
+	AlgorithmDHash = "dhash"
+)
+
+// Hashes holds the perceptual hashes indexed for every logo
+type Hashes struct {
+	DHash uint64
+	PHash uint64
+}
+
+// HashImage computes every hash used for near-duplicate search
+func HashImage(img image.Image) Hashes {
+	return Hashes{
+		DHash: imaging.DHash(img),
+		PHash: imaging.PHash(img),
+	}
+}
+
+// For returns the hash of the given algorithm. Postgres has no unsigned
+// integers, so hashes are stored as int64 with the same bits.
+func (h Hashes) For(algorithm string) int64 {
+	if algorithm == AlgorithmDHash {
+		return int64(h.DHash)
+	}
+	return int64(h.PHash)
+}
+
+const (
+	// bands is how many 16-bit bands the index splits each hash into, it
+	// must match the generated band columns of logo_hashes
+	bands    = 4
+	bandBits = 16
+	// MaxSearchDistance bounds searches, past it the band keys cover so
+	// much of each band that the index no longer narrows anything
+	MaxSearchDistance = 16
+)
+
+// BandKeys returns the index keys of every band value within maxDistance/4
+// bits of the hash's own. Hashes within maxDistance of each other differ by
+// at most that much in one of their four bands, so any match shares a key.
+func BandKeys(hash int64, maxDistance int32) []int32 {
+	radius := int(min(max(maxDistance, 0), MaxSearchDistance)) / bands
+	var keys []int32
+	for band := 0; band < bands; band++ {
+		value := int32(uint64(hash)>>(band*bandBits)) & (1<<bandBits - 1)
+		offset := int32(band << bandBits)
+		keys = appendNeighbors(keys, offset, value, 0, radius)
+	}
+	return keys
+}
+
+// appendNeighbors appends value and every value reached by flipping up to
+// radius of its bits from position bit up, each exactly once
+func appendNeighbors(keys []int32, offset, value int32, bit, radius int) []int32 {
+	keys = append(keys, offset+value)
+	if radius == 0 {
+		return keys
+	}
+	for ; bit < bandBits; bit++ {
+		keys = appendNeighbors(keys, offset, value^(1<<bit), bit+1, radius-1)
+	}
+	return keys
+}
@@ -0,0 +1,48 @@
+package similarity
+
+import (
+	"math/bits"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// bandKeysOf mirrors the generated band columns of logo_hashes
+func bandKeysOf(hash int64) []int32 {
+	keys := make([]int32, 0, bands)
+	for band := 0; band < bands; band++ {
+		keys = append(keys, int32(band<<bandBits)+int32(uint64(hash)>>(band*bandBits))&(1<<bandBits-1))
+	}
+	return keys
+}
+
+func TestBandKeys(t *testing.T) {
+	// 1 + 16 + 120 values within two bits, in each of the four bands
+	keys := BandKeys(-1, 10)
+	require.Len(t, keys, 4*137)
+	sorted := slices.Clone(keys)
+	slices.Sort(sorted)
+	require.Len(t, slices.Compact(sorted), len(keys))
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		hash := int64(random.Uint64())
+		keys := BandKeys(hash, 10)
+
+		// Any hash within the distance shares a band key
+		near := hash
+		for _, bit := range random.Perm(64)[:10] {
+			near ^= 1 << bit
+		}
+		require.LessOrEqual(t, bits.OnesCount64(uint64(hash^near)), 10)
+		require.True(t, slices.ContainsFunc(bandKeysOf(near), func(key int32) bool {
+			return slices.Contains(keys, key)
+		}))
+	}
+
+	// Distances past the maximum search the same keys as the maximum
+	require.Equal(t, BandKeys(42, MaxSearchDistance), BandKeys(42, 64))
+	require.Len(t, BandKeys(42, 0), 4)
+}
@@ -0,0 +1,114 @@
+package similarity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Indexer computes perceptual hashes for extracted logo crops that have not
+// been indexed yet. Only the replica holding the lock indexes.
+type Indexer struct {
+	config        utils.SimilarityConfig
+	store         db.Store
+	storageClient storage.Client
+	lock          *queue.RedisLock
+	// cursor is the last logo ID tried in the current pass, so crops that
+	// fail to index do not block the rest until the next pass
+	cursor int64
+}
+
+func NewIndexer(config utils.SimilarityConfig, store db.Store, storageClient storage.Client, lock *queue.RedisLock) *Indexer {
+	return &Indexer{
+		config:        config,
+		store:         store,
+		storageClient: storageClient,
+		lock:          lock,
+	}
+}
+
+// Run indexes new logos on every interval until ctx is cancelled
+func (i *Indexer) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.config.IndexInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			leader, err := i.lock.Acquire(ctx, i.config.IndexInterval)
+			if err != nil {
+				logrus.WithError(err).Error("Logo indexer failed to acquire lock")
+				continue
+			}
+			if leader {
+				i.IndexBatch(ctx)
+			}
+		}
+	}
+}
+
+// IndexBatch hashes one batch of unindexed logos and returns how many succeeded
+func (i *Indexer) IndexBatch(ctx context.Context) int {
+	logos, err := i.store.ListUnhashedLogos(ctx, db.ListUnhashedLogosParams{
+		ID:    i.cursor,
+		Limit: i.config.IndexBatchSize,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list unindexed logos")
+		return 0
+	}
+	if len(logos) < int(i.config.IndexBatchSize) {
+		// End of the pass, start over to retry logos that failed
+		i.cursor = 0
+	} else {
+		i.cursor = logos[len(logos)-1].ID
+	}
+
+	indexed := 0
+	for _, logo := range logos {
+		if err := i.IndexLogo(ctx, logo); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"logo_id": logo.ID,
+				"s3_key":  logo.S3Key,
+			}).Warn("Failed to index logo")
+			continue
+		}
+		indexed++
+	}
+
+	return indexed
+}
+
+// IndexLogo downloads a logo crop and stores its hashes
+func (i *Indexer) IndexLogo(ctx context.Context, logo db.Logo) error {
+	body, err := i.storageClient.DownloadFile(ctx, logo.S3Key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	img, err := imaging.Decode(body)
+	if err != nil {
+		return err
+	}
+
+	hashes := HashImage(img)
+	_, err = i.store.UpsertLogoHash(ctx, db.UpsertLogoHashParams{
+		LogoID: logo.ID,
+		Dhash:  hashes.For(AlgorithmDHash),
+		Phash:  hashes.For(AlgorithmPHash),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store logo hash: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"time"
@@ -11,13 +14,25 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
+// ErrNotFound is returned by DownloadFile when no object has the key
+var ErrNotFound = errors.New("object not found")
+
 type Client interface {
-	UploadFile(ctx context.Context, key string, file io.Reader, size int64) (*s3.PutObjectOutput, error)
+	UploadFile(ctx context.Context, key string, file io.Reader, size int64) (*UploadOutput, error)
 	GetPresignedURL(ctx context.Context, key string, expiration time.Duration) (string, error)
 	GetPresignedGetURL(ctx context.Context, key string, expiration time.Duration) (string, error)
+	DownloadFile(ctx context.Context, key string) (io.ReadCloser, error)
 	DeleteFile(ctx context.Context, key string) error
+	ListFiles(ctx context.Context, prefix string) ([]string, error)
+}
+
+// UploadOutput is the result of an upload, including the SHA-256 of the uploaded content
+type UploadOutput struct {
+	*s3.PutObjectOutput
+	SHA256 string
 }
 
 type S3Client struct {
@@ -44,17 +59,27 @@ func NewS3Client(envVar utils.CloudflareConfig) (*S3Client, error) {
 	}, nil
 }
 
-func (c *S3Client) UploadFile(ctx context.Context, key string, file io.Reader, size int64) (*s3.PutObjectOutput, error) {
-	output, err := c.client.PutObject(ctx, &s3.PutObjectInput{
+func (c *S3Client) UploadFile(ctx context.Context, key string, file io.Reader, size int64) (*UploadOutput, error) {
+	// Hash the content while it streams to the bucket
+	hash := sha256.New()
+	input := &s3.PutObjectInput{
 		Bucket: aws.String(c.bucketName),
 		Key:    aws.String(key),
-		Body:   file,
-	})
+		Body:   io.TeeReader(file, hash),
+	}
+	if size > 0 {
+		input.ContentLength = aws.Int64(size)
+	}
+
+	output, err := c.client.PutObject(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload file to S3: %w", err)
 	}
 
-	return output, nil
+	return &UploadOutput{
+		PutObjectOutput: output,
+		SHA256:          hex.EncodeToString(hash.Sum(nil)),
+	}, nil
 
 }
 
@@ -91,6 +116,22 @@ func (c *S3Client) GetPresignedGetURL(ctx context.Context, key string, expiratio
 	return presignResult.URL, nil
 }
 
+func (c *S3Client) DownloadFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	output, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucketName),
+		Key:    aws.String(key),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, fmt.Errorf("failed to download %s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file from S3: %w", err)
+	}
+
+	return output.Body, nil
+}
+
 func (c *S3Client) DeleteFile(ctx context.Context, key string) error {
 	_, err := c.client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(c.bucketName),
@@ -102,3 +143,23 @@ func (c *S3Client) DeleteFile(ctx context.Context, key string) error {
 
 	return nil
 }
+
+func (c *S3Client) ListFiles(ctx context.Context, prefix string) ([]string, error) {
+	keys := []string{}
+
+	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.bucketName),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files in S3: %w", err)
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+
+	return keys, nil
+}
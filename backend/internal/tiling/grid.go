@@ -0,0 +1,34 @@
+// Package tiling detects logos in images too large for the detector. YOLO
+// downscales its input to a few hundred pixels, so small logos in print
+// artwork vanish. Such images are cut into overlapping tiles that are
+// detected as separate jobs, and the tile results are merged back into one.
+package tiling
+
+import "image"
+
+// Grid splits an image into tiles of at most size pixels square. The tiles
+// are spread evenly, so neighbors overlap by at least overlap pixels and
+// none is cut short at the image edge.
+func Grid(width, height, size, overlap int) []image.Rectangle {
+	tiles := []image.Rectangle{}
+	for _, y := range offsets(height, size, overlap) {
+		for _, x := range offsets(width, size, overlap) {
+			tiles = append(tiles, image.Rect(x, y, min(x+size, width), min(y+size, height)))
+		}
+	}
+	return tiles
+}
+
+// offsets returns where tiles start along one side of the image
+func offsets(length, size, overlap int) []int {
+	if length <= size {
+		return []int{0}
+	}
+	stride := max(size-overlap, 1)
+	count := (length - overlap + stride - 1) / stride
+	starts := make([]int, count)
+	for i := range starts {
+		starts[i] = i * (length - size) / (count - 1)
+	}
+	return starts
+}
@@ -0,0 +1,38 @@
+package tiling
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrid(t *testing.T) {
+	// Fits in one tile
+	require.Equal(t, []image.Rectangle{image.Rect(0, 0, 300, 200)}, Grid(300, 200, 512, 64))
+
+	tiles := Grid(1000, 500, 512, 64)
+	require.Equal(t, []image.Rectangle{
+		image.Rect(0, 0, 512, 500),
+		image.Rect(244, 0, 756, 500),
+		image.Rect(488, 0, 1000, 500),
+	}, tiles)
+
+	// Every pixel is covered and neighbors overlap by at least the overlap
+	tiles = Grid(9000, 7000, 2048, 256)
+	require.Len(t, tiles, 5*4)
+	covered := image.Rectangle{}
+	for _, tile := range tiles {
+		require.Equal(t, 2048, tile.Dx())
+		require.Equal(t, 2048, tile.Dy())
+		covered = covered.Union(tile)
+	}
+	require.Equal(t, image.Rect(0, 0, 9000, 7000), covered)
+
+	starts := offsets(9000, 2048, 256)
+	require.Equal(t, []int{0, 1738, 3476, 5214, 6952}, starts)
+	for i := 1; i < len(starts); i++ {
+		require.GreaterOrEqual(t, starts[i-1]+2048-starts[i], 256)
+	}
+	require.Equal(t, []int{0, 1792}, offsets(3840, 2048, 256))
+}
@@ -0,0 +1,102 @@
+package tiling
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"sort"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// MergeTiles maps the detections of every tile of a job to the coordinates
+// of the whole image and merges the duplicates found where tiles overlap
+func MergeTiles(jobID uuid.UUID, tiles []db.JobTile, threshold float64) ([]models.LogoDetection, error) {
+	// The image ends where its last tiles do
+	size := image.Point{}
+	for _, tile := range tiles {
+		size.X = max(size.X, int(tile.X+tile.Width))
+		size.Y = max(size.Y, int(tile.Y+tile.Height))
+	}
+
+	detections := []models.LogoDetection{}
+	for _, tile := range tiles {
+		var found []models.LogoDetection
+		if err := json.Unmarshal(tile.Detections, &found); err != nil {
+			return nil, fmt.Errorf("failed to decode detections of tile %d: %w", tile.TileIndex, err)
+		}
+		offset := image.Pt(int(tile.X), int(tile.Y))
+		for _, detection := range found {
+			detection.JobID = jobID
+			detections = append(detections, Translate(detection, offset, size))
+		}
+	}
+	return Merge(detections, threshold), nil
+}
+
+// Translate moves a detection made on a tile at offset into an image of
+// the given size
+func Translate(detection models.LogoDetection, offset, size image.Point) models.LogoDetection {
+	detection.BoundingBox.X += offset.X
+	detection.BoundingBox.Y += offset.Y
+
+	if len(detection.Corners) > 0 {
+		corners := make([]models.Point, len(detection.Corners))
+		for i, corner := range detection.Corners {
+			corners[i] = models.Point{X: corner.X + float64(offset.X), Y: corner.Y + float64(offset.Y)}
+		}
+		detection.Corners = corners
+	}
+
+	if detection.Geometry != nil {
+		geometry := detection.Geometry.Translate(offset.X, offset.Y, size.X, size.Y)
+		detection.Geometry = &geometry
+	}
+	return detection
+}
+
+// Merge is class-aware non-maximum suppression: detections are kept from the
+// most confident down, dropping any that overlaps a kept detection of the
+// same logo type by more than threshold. Overlap is measured against the
+// smaller box, so a logo cut off at a tile edge is a duplicate of the whole
+// logo in the neighboring tile even though their union is much larger.
+func Merge(detections []models.LogoDetection, threshold float64) []models.LogoDetection {
+	sorted := make([]models.LogoDetection, len(detections))
+	copy(sorted, detections)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Confidence > sorted[j].Confidence
+	})
+
+	kept := []models.LogoDetection{}
+	for _, detection := range sorted {
+		duplicate := false
+		for _, other := range kept {
+			if other.LogoType == detection.LogoType && Overlap(other.BoundingBox, detection.BoundingBox) > threshold {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			kept = append(kept, detection)
+		}
+	}
+	return kept
+}
+
+// Overlap returns the intersection of two boxes over the area of the smaller one
+func Overlap(a, b models.BBox) float64 {
+	ra := image.Rect(a.X, a.Y, a.X+a.Width, a.Y+a.Height)
+	rb := image.Rect(b.X, b.Y, b.X+b.Width, b.Y+b.Height)
+	intersection := ra.Intersect(rb)
+	if intersection.Empty() {
+		return 0
+	}
+	smaller := min(area(ra), area(rb))
+	return float64(area(intersection)) / float64(smaller)
+}
+
+func area(r image.Rectangle) int {
+	return r.Dx() * r.Dy()
+}
@@ -0,0 +1,84 @@
+package tiling
+
+import (
+	"encoding/json"
+	"image"
+	"testing"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlap(t *testing.T) {
+	box := models.BBox{X: 0, Y: 0, Width: 100, Height: 50}
+
+	require.Equal(t, 1.0, Overlap(box, box))
+	require.Equal(t, 0.0, Overlap(box, models.BBox{X: 100, Y: 0, Width: 10, Height: 10}))
+	// A logo cut in half by a tile edge is fully inside the whole logo
+	require.Equal(t, 1.0, Overlap(box, models.BBox{X: 50, Y: 0, Width: 50, Height: 50}))
+	require.InDelta(t, 0.25, Overlap(box, models.BBox{X: 75, Y: 0, Width: 100, Height: 50}), 1e-9)
+	require.Equal(t, 0.0, Overlap(box, models.BBox{}))
+}
+
+func TestMerge(t *testing.T) {
+	detections := []models.LogoDetection{
+		{ID: "partial", LogoType: "nike", Confidence: 0.7, BoundingBox: models.BBox{X: 40, Y: 0, Width: 60, Height: 50}},
+		{ID: "whole", LogoType: "nike", Confidence: 0.9, BoundingBox: models.BBox{X: 0, Y: 0, Width: 100, Height: 50}},
+		{ID: "other-class", LogoType: "adidas", Confidence: 0.8, BoundingBox: models.BBox{X: 10, Y: 0, Width: 90, Height: 50}},
+		{ID: "elsewhere", LogoType: "nike", Confidence: 0.6, BoundingBox: models.BBox{X: 500, Y: 500, Width: 100, Height: 50}},
+	}
+
+	merged := Merge(detections, 0.5)
+	ids := []string{}
+	for _, detection := range merged {
+		ids = append(ids, detection.ID)
+	}
+	require.Equal(t, []string{"whole", "other-class", "elsewhere"}, ids)
+	// The input order is left alone
+	require.Equal(t, "partial", detections[0].ID)
+}
+
+func TestMergeTiles(t *testing.T) {
+	jobID := uuid.New()
+	tile := func(index, x int, found ...models.LogoDetection) db.JobTile {
+		data, err := json.Marshal(found)
+		require.NoError(t, err)
+		return db.JobTile{TileIndex: int32(index), X: int32(x), Width: 600, Height: 400, Detections: data}
+	}
+
+	// The same logo seen by both tiles across their overlap at x 500-600
+	tiles := []db.JobTile{
+		tile(0, 0, models.LogoDetection{ID: "left", LogoType: "nike", Confidence: 0.6, BoundingBox: models.BBox{X: 520, Y: 10, Width: 80, Height: 40}}),
+		tile(1, 500, models.LogoDetection{
+			ID: "right", LogoType: "nike", Confidence: 0.9,
+			BoundingBox: models.BBox{X: 20, Y: 10, Width: 100, Height: 40},
+			Corners:     []models.Point{{X: 20, Y: 10}, {X: 120, Y: 10}, {X: 120, Y: 50}, {X: 20, Y: 50}},
+			Geometry:    &models.Geometry{RotatedBox: &models.RotatedBox{CenterX: 70, CenterY: 30, Width: 100, Height: 40}},
+		}),
+	}
+
+	merged, err := MergeTiles(jobID, tiles, 0.5)
+	require.NoError(t, err)
+	require.Len(t, merged, 1)
+	require.Equal(t, "right", merged[0].ID)
+	require.Equal(t, jobID, merged[0].JobID)
+	require.Equal(t, models.BBox{X: 520, Y: 10, Width: 100, Height: 40}, merged[0].BoundingBox)
+	require.Equal(t, models.Point{X: 520, Y: 10}, merged[0].Corners[0])
+	require.Equal(t, 570.0, merged[0].Geometry.RotatedBox.CenterX)
+
+	_, err = MergeTiles(jobID, []db.JobTile{{Detections: []byte("{")}}, 0.5)
+	require.Error(t, err)
+}
+
+func TestTranslateMask(t *testing.T) {
+	detection := models.LogoDetection{
+		BoundingBox: models.BBox{X: 0, Y: 0, Width: 1, Height: 1},
+		Geometry:    &models.Geometry{Mask: &models.RLEMask{Size: [2]int{1, 1}, Counts: []int{0, 1}}},
+	}
+
+	moved := Translate(detection, image.Pt(1, 1), image.Pt(2, 2))
+	require.Equal(t, []int{3, 1}, moved.Geometry.Mask.Counts)
+	require.Equal(t, []int{0, 1}, detection.Geometry.Mask.Counts)
+}
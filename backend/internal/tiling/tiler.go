@@ -0,0 +1,217 @@
+package tiling
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"path"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// TileKey is where a tile image is stored, next to the job's original so it
+// is purged with it
+func TileKey(originalKey string, index int) string {
+	return path.Join(path.Dir(originalKey), "tiles", fmt.Sprintf("tile_%d.png", index))
+}
+
+// Tiler cuts oversized uploads into tiles and queues one detection per tile.
+// Uploads only publish a split message, the image is decoded and cut by
+// whichever replica consumes it.
+type Tiler struct {
+	config        utils.TilingConfig
+	store         db.Store
+	storageClient storage.Client
+	queueClient   queue.Client
+}
+
+func NewTiler(config utils.TilingConfig, store db.Store, storageClient storage.Client, queueClient queue.Client) *Tiler {
+	return &Tiler{
+		config:        config,
+		store:         store,
+		storageClient: storageClient,
+		queueClient:   queueClient,
+	}
+}
+
+// NeedsTiling reports whether an image of the given size is detected in
+// tiles. Unless a job requests otherwise, only images above the configured
+// size are; a job requesting tiling gets it for anything larger than a tile.
+func (t *Tiler) NeedsTiling(width, height int, requested *bool) bool {
+	switch {
+	case requested == nil:
+		return max(width, height) > t.config.MaxDimension
+	case *requested:
+		return max(width, height) > t.config.TileSize
+	default:
+		return false
+	}
+}
+
+// Params returns the tiling settings that become part of a job's detection parameters
+func (t *Tiler) Params() *models.TilingParams {
+	return &models.TilingParams{
+		TileSize:       t.config.TileSize,
+		Overlap:        t.config.Overlap,
+		MergeThreshold: t.config.MergeThreshold,
+	}
+}
+
+// TileCount is how many tiles an image of the given size is cut into
+func (t *Tiler) TileCount(width, height int) int {
+	return len(Grid(width, height, t.config.TileSize, t.config.Overlap))
+}
+
+// Handle splits the job of a split message. Storage and database errors are
+// returned so the message is requeued; an image that does not decode fails
+// the job. A job that already has tiles was split by an earlier delivery,
+// the reaper republishes tiles that never ran.
+func (t *Tiler) Handle(message *models.SplitMessage) error {
+	ctx := context.Background()
+	log := logrus.WithField("job_id", message.JobID)
+
+	job, err := t.store.GetJob(ctx, message.JobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Warn("Ignoring split of unknown job")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if job.Status != models.JobStatusPending {
+		log.WithField("status", job.Status).Warn("Ignoring split of job that is no longer pending")
+		return nil
+	}
+	tiles, err := t.store.ListJobTiles(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	if len(tiles) > 0 {
+		return nil
+	}
+
+	body, err := t.storageClient.DownloadFile(ctx, job.S3Key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	img, err := imaging.Decode(body)
+	if err != nil {
+		log.WithError(err).Warn("Failed to decode image to split")
+		_, err = t.store.TransitionJobTx(ctx, db.TransitionJobTxParams{
+			JobID:        job.ID,
+			ToStatus:     models.JobStatusFailed,
+			Actor:        "tiler",
+			Reason:       "Failed to split image into tiles",
+			ErrorMessage: sql.NullString{String: err.Error(), Valid: true},
+		})
+		// The job moved on since it was read, nothing left to fail
+		if errors.Is(err, db.ErrInvalidTransition) {
+			return nil
+		}
+		return err
+	}
+
+	tiles, err = t.Split(ctx, job, img)
+	if err != nil {
+		return err
+	}
+	log.WithField("tiles", len(tiles)).Info("Split job into tiles")
+	return nil
+}
+
+// Split uploads the tiles of a job's image, records them and publishes one
+// message per tile. The tiles are stored as PNG so detection sees the same
+// pixels as the original.
+func (t *Tiler) Split(ctx context.Context, job db.Job, img image.Image) ([]db.JobTile, error) {
+	bounds := img.Bounds()
+	rects := Grid(bounds.Dx(), bounds.Dy(), t.config.TileSize, t.config.Overlap)
+
+	params := make([]db.CreateJobTileParams, 0, len(rects))
+	for i, rect := range rects {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, crop(img, rect.Add(bounds.Min))); err != nil {
+			return nil, fmt.Errorf("failed to encode tile %d: %w", i, err)
+		}
+		key := TileKey(job.S3Key, i)
+		if _, err := t.storageClient.UploadFile(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+			return nil, fmt.Errorf("failed to upload tile %d: %w", i, err)
+		}
+
+		params = append(params, db.CreateJobTileParams{
+			ID:        uuid.New(),
+			JobID:     job.ID,
+			TileIndex: int32(i),
+			X:         int32(rect.Min.X),
+			Y:         int32(rect.Min.Y),
+			Width:     int32(rect.Dx()),
+			Height:    int32(rect.Dy()),
+			S3Key:     key,
+		})
+	}
+
+	tiles, err := t.store.CreateJobTilesTx(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tiles: %w", err)
+	}
+	return tiles, Publish(t.queueClient, job, tiles)
+}
+
+// Requeue queues a tiled job that is pending again: its split when it was
+// never split, otherwise every tile that has not completed
+func Requeue(ctx context.Context, store db.Store, queueClient queue.Client, job db.Job) error {
+	tiles, err := store.ListJobTiles(ctx, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list job tiles: %w", err)
+	}
+	if len(tiles) == 0 {
+		if err := queueClient.PublishSplit(&models.SplitMessage{JobID: job.ID}); err != nil {
+			return fmt.Errorf("failed to republish split: %w", err)
+		}
+		return nil
+	}
+
+	if err := store.ResetJobTiles(ctx, job.ID); err != nil {
+		return fmt.Errorf("failed to reset job tiles: %w", err)
+	}
+	unfinished := []db.JobTile{}
+	for _, tile := range tiles {
+		if tile.Status != models.JobStatusCompleted {
+			unfinished = append(unfinished, tile)
+		}
+	}
+	return Publish(queueClient, job, unfinished)
+}
+
+// Publish queues a detection for every tile of a job
+func Publish(queueClient queue.Client, job db.Job, tiles []db.JobTile) error {
+	for _, tile := range tiles {
+		if err := queueClient.PublishJob(queue.NewTileMessage(job, tile)); err != nil {
+			return fmt.Errorf("failed to publish tile %d: %w", tile.TileIndex, err)
+		}
+	}
+	return nil
+}
+
+func crop(img image.Image, rect image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	cropped := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), img, rect.Min, draw.Src)
+	return cropped
+}
@@ -0,0 +1,80 @@
+package tiling
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// splitStore holds a single job without tiles
+type splitStore struct {
+	db.Store
+	job db.Job
+}
+
+func (s *splitStore) GetJob(ctx context.Context, id uuid.UUID) (db.Job, error) {
+	return s.job, nil
+}
+
+func (s *splitStore) ListJobTiles(ctx context.Context, jobID uuid.UUID) ([]db.JobTile, error) {
+	return nil, nil
+}
+
+func (s *splitStore) TransitionJobTx(ctx context.Context, arg db.TransitionJobTxParams) (db.Job, error) {
+	s.job.Status = arg.ToStatus
+	return s.job, nil
+}
+
+// originalStorage serves the same original for every key
+type originalStorage struct {
+	storage.Client
+	body string
+	err  error
+}
+
+func (s originalStorage) DownloadFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return io.NopCloser(strings.NewReader(s.body)), nil
+}
+
+func TestHandleSplit(t *testing.T) {
+	config := utils.TilingConfig{MaxDimension: 8192, TileSize: 2048}
+	message := &models.SplitMessage{JobID: uuid.New()}
+
+	// A storage outage is returned so the message is requeued
+	store := &splitStore{job: db.Job{ID: message.JobID, Status: models.JobStatusPending}}
+	tiler := NewTiler(config, store, originalStorage{err: errors.New("connection reset")}, nil)
+	require.Error(t, tiler.Handle(message))
+	require.Equal(t, models.JobStatusPending, store.job.Status)
+
+	// An original that is not an image fails the job
+	tiler = NewTiler(config, store, originalStorage{body: "not an image"}, nil)
+	require.NoError(t, tiler.Handle(message))
+	require.Equal(t, models.JobStatusFailed, store.job.Status)
+
+	// Jobs that are no longer pending are left alone
+	require.NoError(t, tiler.Handle(message))
+}
+
+func TestNeedsTiling(t *testing.T) {
+	tiler := NewTiler(utils.TilingConfig{MaxDimension: 8192, TileSize: 2048}, nil, nil, nil)
+	on, off := true, false
+
+	require.False(t, tiler.NeedsTiling(8192, 4000, nil))
+	require.True(t, tiler.NeedsTiling(4000, 9000, nil))
+
+	require.True(t, tiler.NeedsTiling(3000, 1000, &on))
+	require.False(t, tiler.NeedsTiling(2048, 2048, &on))
+	require.False(t, tiler.NeedsTiling(12000, 9000, &off))
+}
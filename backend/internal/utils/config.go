@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -9,18 +12,37 @@ import (
 var DBDriver = "postgres"
 
 type Config struct {
-	Server     ServerConfig
-	Cloudflare CloudflareConfig
-	RabbitMQ   RabbitMQConfig
-	Redis      RedisConfig
-	Database   DatabaseConfig
+	Server      ServerConfig
+	Cloudflare  CloudflareConfig
+	RabbitMQ    RabbitMQConfig
+	Redis       RedisConfig
+	Database    DatabaseConfig
+	Retention   RetentionConfig
+	Reaper      ReaperConfig
+	Idempotency IdempotencyConfig
+	Detection   DetectionConfig
+	Similarity  SimilarityConfig
+	Brands      BrandConfig
+	Export      ExportConfig
+	Import      ImportConfig
+	Overlay     OverlayConfig
+	Composition CompositionConfig
+	Rectify     RectifyConfig
+	Mask        MaskConfig
+	Palette     PaletteConfig
+	Vector      VectorConfig
+	Tiling      TilingConfig
+	Worker      WorkerConfig
 }
 
+// ServerConfig bounds what the API accepts. MaxImagePixels is the largest
+// width x height an uploaded image may declare, checked before it is stored.
 type ServerConfig struct {
-	Port         string   `mapstructure:"PORT"`
-	MaxFileSize  int64    `mapstructure:"MAX_FILE_SIZE"`
-	AllowedTypes []string `mapstructure:"ALLOWED_TYPES"`
-	RateLimit    RateLimitConfig
+	Port           string   `mapstructure:"PORT"`
+	MaxFileSize    int64    `mapstructure:"MAX_FILE_SIZE"`
+	MaxImagePixels int64    `mapstructure:"MAX_IMAGE_PIXELS"`
+	AllowedTypes   []string `mapstructure:"ALLOWED_TYPES"`
+	RateLimit      RateLimitConfig
 }
 
 type RateLimitConfig struct {
@@ -37,9 +59,17 @@ type CloudflareConfig struct {
 }
 
 type RabbitMQConfig struct {
-	URL      string
-	Exchange string
-	Queue    string
+	URL               string
+	Exchange          string
+	Queue             string
+	ResultsQueue      string
+	ResultsRoutingKey string
+	// CompositionQueue receives composition work published on CompositionRoutingKey
+	CompositionQueue      string
+	CompositionRoutingKey string
+	// SplitQueue receives tiled uploads to cut into tiles, published on SplitRoutingKey
+	SplitQueue      string
+	SplitRoutingKey string
 }
 
 type RedisConfig struct {
@@ -58,6 +88,170 @@ type DatabaseConfig struct {
 	SSLMode  string `mapstructure:"POSTGRES_SSLMODE"`
 }
 
+// RetentionConfig controls how long job data is kept before the sweeper deletes it.
+// Rules are keyed by job status; statuses without a rule fall back to DefaultTTL.
+// ImportedTTL, when set, replaces the rules for jobs imported as ground truth.
+type RetentionConfig struct {
+	Enabled     bool          `mapstructure:"RETENTION_ENABLED"`
+	DryRun      bool          `mapstructure:"RETENTION_DRY_RUN"`
+	Interval    time.Duration `mapstructure:"RETENTION_INTERVAL"`
+	BatchSize   int32         `mapstructure:"RETENTION_BATCH_SIZE"`
+	DefaultTTL  time.Duration `mapstructure:"RETENTION_DEFAULT_TTL"`
+	ImportedTTL time.Duration `mapstructure:"RETENTION_IMPORTED_TTL"`
+	Rules       map[string]time.Duration
+}
+
+// TTLFor returns how long jobs with the given status are retained
+func (c RetentionConfig) TTLFor(status string) time.Duration {
+	if ttl, ok := c.Rules[status]; ok {
+		return ttl
+	}
+	return c.DefaultTTL
+}
+
+// ImportedTTLFor returns how long imported jobs with the given status are retained
+func (c RetentionConfig) ImportedTTLFor(status string) time.Duration {
+	if c.ImportedTTL > 0 {
+		return c.ImportedTTL
+	}
+	return c.TTLFor(status)
+}
+
+const (
+	ReaperPolicyRequeue = "requeue"
+	ReaperPolicyFail    = "fail"
+)
+
+// ReaperConfig controls recovery of jobs that stopped making progress.
+// Timeouts are keyed by job status and measured from the job's updated_at.
+type ReaperConfig struct {
+	Enabled     bool          `mapstructure:"REAPER_ENABLED"`
+	Interval    time.Duration `mapstructure:"REAPER_INTERVAL"`
+	BatchSize   int32         `mapstructure:"REAPER_BATCH_SIZE"`
+	Policy      string        `mapstructure:"REAPER_POLICY"`
+	MaxRequeues int32         `mapstructure:"REAPER_MAX_REQUEUES"`
+	Timeouts    map[string]time.Duration
+}
+
+// IdempotencyConfig controls how long Idempotency-Key responses are kept.
+// LockTTL bounds how long a key stays reserved by a request that never finishes.
+// Request bodies up to MaxBodySize are fingerprinted in memory, larger ones
+// are spooled to a temp file.
+type IdempotencyConfig struct {
+	TTL         time.Duration `mapstructure:"IDEMPOTENCY_TTL"`
+	LockTTL     time.Duration `mapstructure:"IDEMPOTENCY_LOCK_TTL"`
+	MaxBodySize int64         `mapstructure:"IDEMPOTENCY_MAX_BODY_SIZE"`
+}
+
+// DetectionConfig describes the detector the workers run. Cached results are
+// only reused for jobs detected with the same ModelVersion.
+type DetectionConfig struct {
+	ModelVersion string `mapstructure:"DETECTION_MODEL_VERSION"`
+}
+
+// SimilarityConfig controls perceptual-hash indexing of logo crops and the
+// limits of near-duplicate searches. MaxDistance is capped at 16 bits, the
+// widest search the hash band index still narrows.
+type SimilarityConfig struct {
+	IndexEnabled   bool          `mapstructure:"LOGO_INDEX_ENABLED"`
+	IndexInterval  time.Duration `mapstructure:"LOGO_INDEX_INTERVAL"`
+	IndexBatchSize int32         `mapstructure:"LOGO_INDEX_BATCH_SIZE"`
+	MaxDistance    int32         `mapstructure:"SIMILARITY_MAX_DISTANCE"`
+	MaxResults     int32         `mapstructure:"SIMILARITY_MAX_RESULTS"`
+}
+
+// BrandConfig controls automatic matching of detected logos to brands.
+// MatchMinScore is the lowest score, from 0 to 1, that assigns a brand.
+type BrandConfig struct {
+	MatchMinScore float64 `mapstructure:"BRAND_MATCH_MIN_SCORE"`
+}
+
+// OverlayConfig controls the annotated overlay rendered for completed jobs
+type OverlayConfig struct {
+	Enabled bool `mapstructure:"OVERLAY_ENABLED"`
+}
+
+// RectifyConfig bounds perspective rectification. MaxPixels is the largest
+// width x height a logo is unwarped into, whoever sent the corners.
+type RectifyConfig struct {
+	MaxPixels int64 `mapstructure:"RECTIFY_MAX_PIXELS"`
+}
+
+// MaskConfig controls background removal from extracted crops
+type MaskConfig struct {
+	Enabled bool `mapstructure:"MASK_ENABLED"`
+}
+
+// PaletteConfig controls dominant color extraction. Colors is the number of
+// k-means clusters per logo; DeltaETolerance is the largest CIEDE2000
+// difference a palette comparison still accepts.
+type PaletteConfig struct {
+	Enabled         bool    `mapstructure:"PALETTE_ENABLED"`
+	Colors          int     `mapstructure:"PALETTE_COLORS"`
+	DeltaETolerance float64 `mapstructure:"PALETTE_DELTA_E_TOLERANCE"`
+}
+
+// VectorConfig controls SVG tracing of logos. Enabled vectorizes every new
+// logo; GET /logos/:id/vector traces on demand either way. Tolerance is the
+// largest curve fitting error and MinArea the smallest shape kept, in pixels.
+type VectorConfig struct {
+	Enabled   bool    `mapstructure:"VECTOR_ENABLED"`
+	Colors    int     `mapstructure:"VECTOR_COLORS"`
+	Tolerance float64 `mapstructure:"VECTOR_TOLERANCE"`
+	MinArea   float64 `mapstructure:"VECTOR_MIN_AREA"`
+}
+
+// TilingConfig controls tiled detection of large images. Uploads whose
+// longest side exceeds MaxDimension are cut into TileSize squares that
+// overlap by Overlap pixels. MergeThreshold is the overlap, as intersection
+// over the smaller box, above which detections of one class are duplicates.
+type TilingConfig struct {
+	Enabled        bool    `mapstructure:"TILING_ENABLED"`
+	MaxDimension   int     `mapstructure:"TILING_MAX_DIMENSION"`
+	TileSize       int     `mapstructure:"TILING_TILE_SIZE"`
+	Overlap        int     `mapstructure:"TILING_OVERLAP"`
+	MergeThreshold float64 `mapstructure:"TILING_MERGE_THRESHOLD"`
+}
+
+// WorkerConfig controls the Go detection worker in cmd/worker. It matches
+// the images in TemplateDir and reports results as ModelVersion; matches
+// scoring below ConfidenceThreshold are dropped unless a job sets its own.
+type WorkerConfig struct {
+	TemplateDir         string  `mapstructure:"WORKER_TEMPLATE_DIR"`
+	ModelVersion        string  `mapstructure:"WORKER_MODEL_VERSION"`
+	ConfidenceThreshold float64 `mapstructure:"WORKER_CONFIDENCE_THRESHOLD"`
+}
+
+// CompositionConfig controls the compositor. Enabled consumes composition
+// work in this process; Feather is the default soft edge width in pixels.
+type CompositionConfig struct {
+	Enabled bool    `mapstructure:"COMPOSITION_ENABLED"`
+	Feather float64 `mapstructure:"COMPOSITION_DEFAULT_FEATHER"`
+}
+
+// ExportConfig controls the background builder of dataset exports. Exports
+// still processing after Timeout are picked up again.
+type ExportConfig struct {
+	Enabled     bool          `mapstructure:"DATASET_EXPORT_ENABLED"`
+	Interval    time.Duration `mapstructure:"DATASET_EXPORT_INTERVAL"`
+	Timeout     time.Duration `mapstructure:"DATASET_EXPORT_TIMEOUT"`
+	ValFraction float64       `mapstructure:"DATASET_EXPORT_VAL_FRACTION"`
+	URLExpiry   time.Duration `mapstructure:"DATASET_EXPORT_URL_EXPIRY"`
+}
+
+// ImportConfig controls the background importer of uploaded dataset
+// archives. Imports still processing after Timeout are picked up again.
+// MaxImageSize bounds the uncompressed size of every image in an archive and
+// MaxImagePixels is the upload limit, shared with the API.
+type ImportConfig struct {
+	Enabled        bool          `mapstructure:"DATASET_IMPORT_ENABLED"`
+	Interval       time.Duration `mapstructure:"DATASET_IMPORT_INTERVAL"`
+	Timeout        time.Duration `mapstructure:"DATASET_IMPORT_TIMEOUT"`
+	MaxArchiveSize int64         `mapstructure:"DATASET_IMPORT_MAX_ARCHIVE_SIZE"`
+	MaxImageSize   int64         `mapstructure:"DATASET_IMPORT_MAX_IMAGE_SIZE"`
+	MaxImagePixels int64         `mapstructure:"MAX_IMAGE_PIXELS"`
+}
+
 func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigName("app")
 	viper.SetConfigType("env")
@@ -78,6 +272,10 @@ func LoadConfig(path string) (config Config, err error) {
 	// Server configuration
 	config.Server.Port = viper.GetString("PORT")
 	config.Server.MaxFileSize = viper.GetInt64("MAX_FILE_SIZE")
+	config.Server.MaxImagePixels = viper.GetInt64("MAX_IMAGE_PIXELS")
+	if config.Server.MaxImagePixels <= 0 {
+		config.Server.MaxImagePixels = 100_000_000
+	}
 	config.Server.AllowedTypes = []string{"image/jpeg", "image/png"}
 	config.Server.RateLimit.RequestsPerHour = viper.GetInt("RATE_LIMIT_REQUESTS_PER_HOUR")
 	config.Server.RateLimit.Burst = viper.GetInt("RATE_LIMIT_BURST")
@@ -86,6 +284,30 @@ func LoadConfig(path string) (config Config, err error) {
 	config.RabbitMQ.URL = viper.GetString("RABBITMQ_URL")
 	config.RabbitMQ.Exchange = viper.GetString("RABBITMQ_EXCHANGE")
 	config.RabbitMQ.Queue = viper.GetString("RABBITMQ_QUEUE")
+	config.RabbitMQ.ResultsQueue = viper.GetString("RABBITMQ_RESULTS_QUEUE")
+	if config.RabbitMQ.ResultsQueue == "" {
+		config.RabbitMQ.ResultsQueue = "results-queue"
+	}
+	config.RabbitMQ.ResultsRoutingKey = viper.GetString("RABBITMQ_RESULTS_ROUTING_KEY")
+	if config.RabbitMQ.ResultsRoutingKey == "" {
+		config.RabbitMQ.ResultsRoutingKey = "job.result"
+	}
+	config.RabbitMQ.CompositionQueue = viper.GetString("RABBITMQ_COMPOSITION_QUEUE")
+	if config.RabbitMQ.CompositionQueue == "" {
+		config.RabbitMQ.CompositionQueue = "composition-queue"
+	}
+	config.RabbitMQ.CompositionRoutingKey = viper.GetString("RABBITMQ_COMPOSITION_ROUTING_KEY")
+	if config.RabbitMQ.CompositionRoutingKey == "" {
+		config.RabbitMQ.CompositionRoutingKey = "composition"
+	}
+	config.RabbitMQ.SplitQueue = viper.GetString("RABBITMQ_SPLIT_QUEUE")
+	if config.RabbitMQ.SplitQueue == "" {
+		config.RabbitMQ.SplitQueue = "split-queue"
+	}
+	config.RabbitMQ.SplitRoutingKey = viper.GetString("RABBITMQ_SPLIT_ROUTING_KEY")
+	if config.RabbitMQ.SplitRoutingKey == "" {
+		config.RabbitMQ.SplitRoutingKey = "split"
+	}
 
 	// Redis configuration
 	config.Redis.Addr = viper.GetString("REDIS_ADDR")
@@ -101,5 +323,244 @@ func LoadConfig(path string) (config Config, err error) {
 	config.Database.DBName = viper.GetString("POSTGRES_DB")
 	config.Database.SSLMode = viper.GetString("POSTGRES_SSLMODE")
 
+	// Retention configuration
+	config.Retention.Enabled = viper.GetBool("RETENTION_ENABLED")
+	config.Retention.DryRun = viper.GetBool("RETENTION_DRY_RUN")
+	config.Retention.Interval = viper.GetDuration("RETENTION_INTERVAL")
+	if config.Retention.Interval <= 0 {
+		config.Retention.Interval = time.Hour
+	}
+	config.Retention.BatchSize = viper.GetInt32("RETENTION_BATCH_SIZE")
+	if config.Retention.BatchSize <= 0 {
+		config.Retention.BatchSize = 100
+	}
+	config.Retention.DefaultTTL = viper.GetDuration("RETENTION_DEFAULT_TTL")
+	if config.Retention.DefaultTTL <= 0 {
+		config.Retention.DefaultTTL = 30 * 24 * time.Hour
+	}
+	config.Retention.ImportedTTL = viper.GetDuration("RETENTION_IMPORTED_TTL")
+	config.Retention.Rules, err = parseStatusDurations(viper.GetString("RETENTION_RULES"))
+	if err != nil {
+		return
+	}
+
+	// Stuck-job reaper configuration
+	config.Reaper.Enabled = viper.GetBool("REAPER_ENABLED")
+	config.Reaper.Interval = viper.GetDuration("REAPER_INTERVAL")
+	if config.Reaper.Interval <= 0 {
+		config.Reaper.Interval = time.Minute
+	}
+	config.Reaper.BatchSize = viper.GetInt32("REAPER_BATCH_SIZE")
+	if config.Reaper.BatchSize <= 0 {
+		config.Reaper.BatchSize = 100
+	}
+	config.Reaper.Policy = viper.GetString("REAPER_POLICY")
+	switch config.Reaper.Policy {
+	case "":
+		config.Reaper.Policy = ReaperPolicyRequeue
+	case ReaperPolicyRequeue, ReaperPolicyFail:
+	default:
+		err = fmt.Errorf("invalid REAPER_POLICY %q", config.Reaper.Policy)
+		return
+	}
+	config.Reaper.MaxRequeues = viper.GetInt32("REAPER_MAX_REQUEUES")
+	if config.Reaper.MaxRequeues <= 0 {
+		config.Reaper.MaxRequeues = 3
+	}
+	config.Reaper.Timeouts, err = parseStatusDurations(viper.GetString("REAPER_TIMEOUTS"))
+	if err != nil {
+		return
+	}
+	if len(config.Reaper.Timeouts) == 0 {
+		config.Reaper.Timeouts = map[string]time.Duration{
+			"pending":    30 * time.Minute,
+			"processing": 15 * time.Minute,
+		}
+	}
+
+	// Idempotency configuration
+	config.Idempotency.TTL = viper.GetDuration("IDEMPOTENCY_TTL")
+	if config.Idempotency.TTL <= 0 {
+		config.Idempotency.TTL = 24 * time.Hour
+	}
+	config.Idempotency.LockTTL = viper.GetDuration("IDEMPOTENCY_LOCK_TTL")
+	if config.Idempotency.LockTTL <= 0 {
+		config.Idempotency.LockTTL = 5 * time.Minute
+	}
+	config.Idempotency.MaxBodySize = viper.GetInt64("IDEMPOTENCY_MAX_BODY_SIZE")
+	if config.Idempotency.MaxBodySize <= 0 {
+		config.Idempotency.MaxBodySize = 20 << 20
+	}
+
+	// Detection configuration
+	config.Detection.ModelVersion = viper.GetString("DETECTION_MODEL_VERSION")
+	if config.Detection.ModelVersion == "" {
+		config.Detection.ModelVersion = "yolov8n"
+	}
+
+	// Similarity search configuration
+	config.Similarity.IndexEnabled = viper.GetBool("LOGO_INDEX_ENABLED")
+	config.Similarity.IndexInterval = viper.GetDuration("LOGO_INDEX_INTERVAL")
+	if config.Similarity.IndexInterval <= 0 {
+		config.Similarity.IndexInterval = 30 * time.Second
+	}
+	config.Similarity.IndexBatchSize = viper.GetInt32("LOGO_INDEX_BATCH_SIZE")
+	if config.Similarity.IndexBatchSize <= 0 {
+		config.Similarity.IndexBatchSize = 50
+	}
+	config.Similarity.MaxDistance = viper.GetInt32("SIMILARITY_MAX_DISTANCE")
+	if config.Similarity.MaxDistance <= 0 {
+		config.Similarity.MaxDistance = 10
+	}
+	config.Similarity.MaxResults = viper.GetInt32("SIMILARITY_MAX_RESULTS")
+	if config.Similarity.MaxResults <= 0 {
+		config.Similarity.MaxResults = 100
+	}
+
+	// Brand matching configuration
+	config.Brands.MatchMinScore = viper.GetFloat64("BRAND_MATCH_MIN_SCORE")
+	if config.Brands.MatchMinScore <= 0 {
+		config.Brands.MatchMinScore = 0.8
+	}
+
+	// Dataset export configuration
+	config.Export.Enabled = viper.GetBool("DATASET_EXPORT_ENABLED")
+	config.Export.Interval = viper.GetDuration("DATASET_EXPORT_INTERVAL")
+	if config.Export.Interval <= 0 {
+		config.Export.Interval = 10 * time.Second
+	}
+	config.Export.Timeout = viper.GetDuration("DATASET_EXPORT_TIMEOUT")
+	if config.Export.Timeout <= 0 {
+		config.Export.Timeout = 30 * time.Minute
+	}
+	config.Export.ValFraction = viper.GetFloat64("DATASET_EXPORT_VAL_FRACTION")
+	if config.Export.ValFraction <= 0 || config.Export.ValFraction >= 1 {
+		config.Export.ValFraction = 0.2
+	}
+	config.Export.URLExpiry = viper.GetDuration("DATASET_EXPORT_URL_EXPIRY")
+	if config.Export.URLExpiry <= 0 {
+		config.Export.URLExpiry = time.Hour
+	}
+
+	// Dataset import configuration
+	config.Import.Enabled = viper.GetBool("DATASET_IMPORT_ENABLED")
+	config.Import.Interval = viper.GetDuration("DATASET_IMPORT_INTERVAL")
+	if config.Import.Interval <= 0 {
+		config.Import.Interval = 10 * time.Second
+	}
+	config.Import.Timeout = viper.GetDuration("DATASET_IMPORT_TIMEOUT")
+	if config.Import.Timeout <= 0 {
+		config.Import.Timeout = time.Hour
+	}
+	config.Import.MaxArchiveSize = viper.GetInt64("DATASET_IMPORT_MAX_ARCHIVE_SIZE")
+	if config.Import.MaxArchiveSize <= 0 {
+		config.Import.MaxArchiveSize = 1 << 30
+	}
+	config.Import.MaxImageSize = viper.GetInt64("DATASET_IMPORT_MAX_IMAGE_SIZE")
+	if config.Import.MaxImageSize <= 0 {
+		config.Import.MaxImageSize = 50 << 20
+	}
+	config.Import.MaxImagePixels = config.Server.MaxImagePixels
+
+	// Overlay configuration
+	config.Overlay.Enabled = viper.GetBool("OVERLAY_ENABLED")
+
+	// Rectification configuration
+	config.Rectify.MaxPixels = viper.GetInt64("RECTIFY_MAX_PIXELS")
+	if config.Rectify.MaxPixels <= 0 {
+		config.Rectify.MaxPixels = 16_000_000
+	}
+
+	// Mask configuration
+	config.Mask.Enabled = viper.GetBool("MASK_ENABLED")
+
+	// Palette configuration
+	config.Palette.Enabled = viper.GetBool("PALETTE_ENABLED")
+	config.Palette.Colors = viper.GetInt("PALETTE_COLORS")
+	if config.Palette.Colors <= 0 {
+		config.Palette.Colors = 5
+	}
+	config.Palette.DeltaETolerance = viper.GetFloat64("PALETTE_DELTA_E_TOLERANCE")
+	if config.Palette.DeltaETolerance <= 0 {
+		config.Palette.DeltaETolerance = 5
+	}
+
+	// Vector configuration
+	config.Vector.Enabled = viper.GetBool("VECTOR_ENABLED")
+	config.Vector.Colors = viper.GetInt("VECTOR_COLORS")
+	if config.Vector.Colors <= 0 {
+		config.Vector.Colors = 4
+	}
+	config.Vector.Tolerance = viper.GetFloat64("VECTOR_TOLERANCE")
+	if config.Vector.Tolerance <= 0 {
+		config.Vector.Tolerance = 1
+	}
+	config.Vector.MinArea = viper.GetFloat64("VECTOR_MIN_AREA")
+	if config.Vector.MinArea < 0 {
+		config.Vector.MinArea = 0
+	}
+
+	// Tiling configuration
+	config.Tiling.Enabled = viper.GetBool("TILING_ENABLED")
+	config.Tiling.MaxDimension = viper.GetInt("TILING_MAX_DIMENSION")
+	if config.Tiling.MaxDimension <= 0 {
+		config.Tiling.MaxDimension = 8192
+	}
+	config.Tiling.TileSize = viper.GetInt("TILING_TILE_SIZE")
+	if config.Tiling.TileSize <= 0 {
+		config.Tiling.TileSize = 2048
+	}
+	config.Tiling.Overlap = viper.GetInt("TILING_OVERLAP")
+	if config.Tiling.Overlap < 0 || config.Tiling.Overlap >= config.Tiling.TileSize {
+		config.Tiling.Overlap = config.Tiling.TileSize / 8
+	}
+	config.Tiling.MergeThreshold = viper.GetFloat64("TILING_MERGE_THRESHOLD")
+	if config.Tiling.MergeThreshold <= 0 || config.Tiling.MergeThreshold > 1 {
+		config.Tiling.MergeThreshold = 0.5
+	}
+
+	// Go worker configuration
+	config.Worker.TemplateDir = viper.GetString("WORKER_TEMPLATE_DIR")
+	if config.Worker.TemplateDir == "" {
+		config.Worker.TemplateDir = "templates"
+	}
+	config.Worker.ModelVersion = viper.GetString("WORKER_MODEL_VERSION")
+	if config.Worker.ModelVersion == "" {
+		config.Worker.ModelVersion = "template-ncc"
+	}
+	config.Worker.ConfidenceThreshold = viper.GetFloat64("WORKER_CONFIDENCE_THRESHOLD")
+	if config.Worker.ConfidenceThreshold <= 0 || config.Worker.ConfidenceThreshold > 1 {
+		config.Worker.ConfidenceThreshold = 0.8
+	}
+
+	// Composition configuration
+	config.Composition.Enabled = viper.GetBool("COMPOSITION_ENABLED")
+	config.Composition.Feather = viper.GetFloat64("COMPOSITION_DEFAULT_FEATHER")
+	if config.Composition.Feather < 0 {
+		config.Composition.Feather = 0
+	}
+
 	return
 }
+
+// parseStatusDurations parses "status=duration" pairs, e.g. "completed=720h,failed=168h"
+func parseStatusDurations(value string) (map[string]time.Duration, error) {
+	rules := map[string]time.Duration{}
+	if strings.TrimSpace(value) == "" {
+		return rules, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		status, ttl, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || status == "" {
+			return nil, fmt.Errorf("invalid status duration %q", pair)
+		}
+		duration, err := time.ParseDuration(ttl)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid duration for status %q: %q", status, ttl)
+		}
+		rules[status] = duration
+	}
+
+	return rules, nil
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -53,3 +54,35 @@ func TestLoadConfig_WithFile(t *testing.T) {
 	assert.Equal(t, "8083", config.Server.Port)
 	assert.Equal(t, int64(1002688), config.Server.MaxFileSize)
 }
+
+func TestParseStatusDurations(t *testing.T) {
+	rules, err := parseStatusDurations("completed=720h, failed=168h")
+	require.NoError(t, err)
+	assert.Equal(t, 720*time.Hour, rules["completed"])
+	assert.Equal(t, 168*time.Hour, rules["failed"])
+
+	rules, err = parseStatusDurations("")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	_, err = parseStatusDurations("completed")
+	require.Error(t, err)
+
+	_, err = parseStatusDurations("completed=forever")
+	require.Error(t, err)
+}
+
+func TestRetentionTTLFor(t *testing.T) {
+	cfg := RetentionConfig{
+		DefaultTTL: 30 * 24 * time.Hour,
+		Rules:      map[string]time.Duration{"failed": 7 * 24 * time.Hour},
+	}
+
+	assert.Equal(t, 7*24*time.Hour, cfg.TTLFor("failed"))
+	assert.Equal(t, 30*24*time.Hour, cfg.TTLFor("completed"))
+
+	// Imported jobs follow the rules unless they have their own TTL
+	assert.Equal(t, 7*24*time.Hour, cfg.ImportedTTLFor("failed"))
+	cfg.ImportedTTL = 365 * 24 * time.Hour
+	assert.Equal(t, 365*24*time.Hour, cfg.ImportedTTLFor("failed"))
+}
@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+)
+
+// CropExtractor crops the bounding box of a detection, clamped to the image
+type CropExtractor struct{}
+
+func (CropExtractor) Extract(img image.Image, detection models.LogoDetection) (image.Image, error) {
+	bounds := img.Bounds()
+	box := detection.BoundingBox
+	rect := image.Rect(box.X, box.Y, box.X+box.Width, box.Y+box.Height).Add(bounds.Min).Intersect(bounds)
+	if rect.Empty() {
+		return nil, fmt.Errorf("bounding box %v is outside the image", box)
+	}
+
+	crop := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(crop, crop.Bounds(), img, rect.Min, draw.Src)
+	return crop, nil
+}
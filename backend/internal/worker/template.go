@@ -0,0 +1,242 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/tiling"
+)
+
+const (
+	// templateWorkSize is the longest side images are scaled down to for matching
+	templateWorkSize = 320
+	// templateStride is the step in work pixels between matched positions
+	templateStride = 2
+	// templateMinSize is the smallest template side in work pixels worth matching
+	templateMinSize = 8
+	// templateMinScore drops matches too weak for any threshold to accept
+	templateMinScore = 0.3
+	// templateMergeThreshold is the overlap above which matches of one
+	// template at different scales are the same logo
+	templateMergeThreshold = 0.5
+)
+
+// templateScales are the sizes, relative to the template, logos are searched at
+var templateScales = []float64{0.5, 0.75, 1, 1.5, 2}
+
+// Template is a reference image of a logo, matched as LogoType Class
+type Template struct {
+	Class string
+	Image image.Image
+}
+
+// LoadTemplates reads the PNG and JPEG files of a directory as templates,
+// named after their files without the extension
+func LoadTemplates(dir string) ([]Template, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	var templates []Template
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".png" && ext != ".jpg" && ext != ".jpeg") {
+			continue
+		}
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		img, err := imaging.Decode(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", entry.Name(), err)
+		}
+		templates = append(templates, Template{Class: strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())), Image: img})
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no templates in %s", dir)
+	}
+	return templates, nil
+}
+
+// TemplateDetector finds logos by normalized cross-correlation with
+// reference images, at a few scales around their own size. The confidence
+// of a match is its correlation, so it ignores brightness and contrast but
+// not rotation or perspective.
+type TemplateDetector struct {
+	templates    []Template
+	modelVersion string
+}
+
+func NewTemplateDetector(templates []Template, modelVersion string) *TemplateDetector {
+	return &TemplateDetector{
+		templates:    templates,
+		modelVersion: modelVersion,
+	}
+}
+
+// ModelVersion is the configured version, there are no model variants
+func (d *TemplateDetector) ModelVersion(models.DetectionOptions) string {
+	return d.modelVersion
+}
+
+func (d *TemplateDetector) Detect(ctx context.Context, img image.Image, options models.DetectionOptions) ([]models.LogoDetection, error) {
+	bounds := img.Bounds()
+	// Images are only ever scaled down, factor maps image to work pixels
+	factor := math.Min(1, templateWorkSize/float64(max(bounds.Dx(), bounds.Dy())))
+	width := max(1, int(math.Round(float64(bounds.Dx())*factor)))
+	height := max(1, int(math.Round(float64(bounds.Dy())*factor)))
+	search := newIntegralImage(imaging.Grayscale(img, width, height), width, height)
+
+	var detections []models.LogoDetection
+	for _, template := range d.templates {
+		if len(options.Classes) > 0 && !slices.Contains(options.Classes, template.Class) {
+			continue
+		}
+		for _, scale := range templateScales {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			size := template.Image.Bounds().Size()
+			tw := int(math.Round(float64(size.X) * factor * scale))
+			th := int(math.Round(float64(size.Y) * factor * scale))
+			if tw < templateMinSize || th < templateMinSize || tw > width || th > height {
+				continue
+			}
+
+			for _, match := range search.match(imaging.Grayscale(template.Image, tw, th), tw, th) {
+				detections = append(detections, models.LogoDetection{
+					BoundingBox: models.BBox{
+						X:      int(math.Round(float64(match.X) / factor)),
+						Y:      int(math.Round(float64(match.Y) / factor)),
+						Width:  int(math.Round(float64(tw) / factor)),
+						Height: int(math.Round(float64(th) / factor)),
+					},
+					Confidence: match.score,
+					LogoType:   template.Class,
+				})
+			}
+		}
+	}
+	return tiling.Merge(detections, templateMergeThreshold), nil
+}
+
+type templateMatch struct {
+	image.Point
+	score float64
+}
+
+// integralImage holds running sums of the pixels and their squares, so the
+// mean and variance under a template are read in constant time
+type integralImage struct {
+	pixels        []float64
+	width, height int
+	sum, squares  []float64
+}
+
+func newIntegralImage(pixels []float64, width, height int) *integralImage {
+	stride := width + 1
+	sum := make([]float64, stride*(height+1))
+	squares := make([]float64, stride*(height+1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := pixels[y*width+x]
+			i := (y+1)*stride + x + 1
+			sum[i] = p + sum[i-1] + sum[i-stride] - sum[i-stride-1]
+			squares[i] = p*p + squares[i-1] + squares[i-stride] - squares[i-stride-1]
+		}
+	}
+	return &integralImage{pixels: pixels, width: width, height: height, sum: sum, squares: squares}
+}
+
+func (g *integralImage) window(values []float64, x, y, w, h int) float64 {
+	stride := g.width + 1
+	return values[(y+h)*stride+x+w] - values[y*stride+x+w] - values[(y+h)*stride+x] + values[y*stride+x]
+}
+
+// match correlates a template with every position and returns the local
+// maxima of the correlation above templateMinScore
+func (g *integralImage) match(template []float64, tw, th int) []templateMatch {
+	n := float64(tw * th)
+	mean := 0.0
+	for _, p := range template {
+		mean += p
+	}
+	mean /= n
+	// A zero-mean template makes the correlation ignore the window's mean
+	centered := make([]float64, len(template))
+	norm := 0.0
+	for i, p := range template {
+		centered[i] = p - mean
+		norm += centered[i] * centered[i]
+	}
+	if norm == 0 {
+		return nil
+	}
+
+	cols := (g.width-tw)/templateStride + 1
+	rows := (g.height-th)/templateStride + 1
+	scores := make([]float64, cols*rows)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			x, y := col*templateStride, row*templateStride
+			sum := g.window(g.sum, x, y, tw, th)
+			variance := g.window(g.squares, x, y, tw, th) - sum*sum/n
+			if variance <= 1e-6 {
+				continue
+			}
+			correlation := 0.0
+			for ty := 0; ty < th; ty++ {
+				line := g.pixels[(y+ty)*g.width+x : (y+ty)*g.width+x+tw]
+				for tx, p := range line {
+					correlation += p * centered[ty*tw+tx]
+				}
+			}
+			scores[row*cols+col] = correlation / math.Sqrt(variance*norm)
+		}
+	}
+
+	var matches []templateMatch
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			score := scores[row*cols+col]
+			if score < templateMinScore || !isPeak(scores, cols, rows, col, row) {
+				continue
+			}
+			matches = append(matches, templateMatch{
+				Point: image.Point{X: col * templateStride, Y: row * templateStride},
+				score: math.Min(score, 1),
+			})
+		}
+	}
+	return matches
+}
+
+// isPeak reports whether a score is the largest of its 3x3 neighborhood,
+// ties going to the first in row order
+func isPeak(scores []float64, cols, rows, col, row int) bool {
+	score := scores[row*cols+col]
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			x, y := col+dx, row+dy
+			if (dx == 0 && dy == 0) || x < 0 || y < 0 || x >= cols || y >= rows {
+				continue
+			}
+			other := scores[y*cols+x]
+			if other > score || (other == score && (dy < 0 || (dy == 0 && dx < 0))) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+// testNoise fills an image with random gray blocks, which no template matches well
+func testNoise(random *rand.Rand, width, height, block int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y += block {
+		for x := 0; x < width; x += block {
+			v := uint8(random.Intn(256))
+			draw.Draw(img, image.Rect(x, y, x+block, y+block), image.NewUniform(color.RGBA{R: v, G: v, B: v, A: 255}), image.Point{}, draw.Src)
+		}
+	}
+	return img
+}
+
+func TestTemplateDetector(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	logo := testNoise(random, 40, 30, 5)
+	other := testNoise(random, 40, 30, 5)
+	scene := testNoise(random, 200, 150, 10)
+	draw.Draw(scene, image.Rect(60, 40, 100, 70), logo, image.Point{}, draw.Src)
+
+	detector := NewTemplateDetector([]Template{{Class: "acme", Image: logo}, {Class: "other", Image: other}}, "template-ncc")
+	detections, err := detector.Detect(context.Background(), scene, models.DetectionOptions{})
+	require.NoError(t, err)
+
+	detections = ApplyOptions(detections, models.DetectionOptions{}, 0.8)
+	require.Len(t, detections, 1)
+	require.Equal(t, "acme", detections[0].LogoType)
+	require.Equal(t, models.BBox{X: 60, Y: 40, Width: 40, Height: 30}, detections[0].BoundingBox)
+	require.InDelta(t, 1, detections[0].Confidence, 1e-6)
+
+	// Templates of other classes are not searched
+	detections, err = detector.Detect(context.Background(), scene, models.DetectionOptions{Classes: []string{"other"}})
+	require.NoError(t, err)
+	for _, detection := range detections {
+		require.Equal(t, "other", detection.LogoType)
+		require.Less(t, detection.Confidence, 0.8)
+	}
+
+	require.Equal(t, "template-ncc", detector.ModelVersion(models.DetectionOptions{ModelVariant: "x"}))
+}
+
+func TestTemplateDetectorScalesDown(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	logo := testNoise(random, 100, 100, 20)
+	scene := testNoise(random, 1280, 960, 40)
+	draw.Draw(scene, image.Rect(400, 320, 500, 420), logo, image.Point{}, draw.Src)
+
+	detections, err := NewTemplateDetector([]Template{{Class: "acme", Image: logo}}, "template-ncc").
+		Detect(context.Background(), scene, models.DetectionOptions{})
+	require.NoError(t, err)
+
+	detections = ApplyOptions(detections, models.DetectionOptions{}, 0.8)
+	require.Len(t, detections, 1)
+	// Boxes are in the coordinates of the full image
+	box := detections[0].BoundingBox
+	require.InDelta(t, 400, box.X, 8)
+	require.InDelta(t, 320, box.Y, 8)
+	require.InDelta(t, 100, box.Width, 8)
+	require.InDelta(t, 100, box.Height, 8)
+}
@@ -0,0 +1,200 @@
+// Package worker runs detection jobs in Go. It consumes the same messages
+// as the Python worker and publishes the same results, so any Detector can
+// serve the detection queue alongside or instead of it.
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/imaging"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Detector finds logos in an image. Detections only need their bounding
+// box, confidence and logo type; Corners and Geometry are passed on when
+// set. The worker applies the job's threshold, classes and limit itself.
+type Detector interface {
+	Detect(ctx context.Context, img image.Image, options models.DetectionOptions) ([]models.LogoDetection, error)
+	// ModelVersion names the model that runs for the options, e.g. yolov8n
+	ModelVersion(options models.DetectionOptions) string
+}
+
+// Extractor cuts the image of a detected logo out of the full image
+type Extractor interface {
+	Extract(img image.Image, detection models.LogoDetection) (image.Image, error)
+}
+
+// LogoKey is where the crop of the i-th detection of a job is stored, the
+// same key the Python worker uses
+func LogoKey(jobID uuid.UUID, i int) string {
+	return fmt.Sprintf("extracted/%s/logo_%d.png", jobID, i)
+}
+
+// Worker processes detection jobs with a Detector
+type Worker struct {
+	config        utils.WorkerConfig
+	detector      Detector
+	extractor     Extractor
+	storageClient storage.Client
+	queueClient   queue.Client
+}
+
+func NewWorker(config utils.WorkerConfig, detector Detector, extractor Extractor, storageClient storage.Client, queueClient queue.Client) *Worker {
+	return &Worker{
+		config:        config,
+		detector:      detector,
+		extractor:     extractor,
+		storageClient: storageClient,
+		queueClient:   queueClient,
+	}
+}
+
+// Handle is the queue handler for detection jobs. A job whose image is
+// missing or broken is reported as failed; storage errors and failures to
+// publish the result are returned, which requeues the job.
+func (w *Worker) Handle(job *models.Job) error {
+	result, err := w.Process(context.Background(), job)
+	if err != nil {
+		logrus.WithError(err).WithField("job_id", job.ID).Warn("Requeueing detection job")
+		return err
+	}
+	if err := w.queueClient.PublishResult(result); err != nil {
+		return err
+	}
+	logrus.WithFields(logrus.Fields{
+		"job_id":      job.ID,
+		"status":      result.Status,
+		"logos_found": len(result.LogosFound),
+	}).Info("Published detection result")
+	return nil
+}
+
+// Process detects the logos of a job and uploads their crops. Like the
+// Python worker, a crop that fails is skipped and the other detections are
+// still reported. Errors are transient, e.g. the image could not be
+// downloaded; a job that can never succeed gets a failed result instead.
+func (w *Worker) Process(ctx context.Context, job *models.Job) (*models.ProcessingResult, error) {
+	var options models.DetectionOptions
+	if job.DetectionOptions != nil {
+		options = *job.DetectionOptions
+	}
+
+	data, err := w.download(ctx, job.S3Key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return failed(job.ID, err), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	img, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return failed(job.ID, fmt.Errorf("failed to decode image: %w", err)), nil
+	}
+
+	detections, err := w.detector.Detect(ctx, img, options)
+	if err != nil {
+		return failed(job.ID, fmt.Errorf("detection failed: %w", err)), nil
+	}
+	detections = ApplyOptions(detections, options, w.config.ConfidenceThreshold)
+
+	logos := []models.LogoDetection{}
+	for i, detection := range detections {
+		key := LogoKey(job.ID, i)
+		if err := w.upload(ctx, key, img, detection); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{"job_id": job.ID, "detection": i}).Error("Failed to extract logo")
+			continue
+		}
+		detection.ID = uuid.NewString()
+		detection.JobID = job.ID
+		detection.S3Key = key
+		logos = append(logos, detection)
+	}
+
+	return &models.ProcessingResult{
+		JobID:        job.ID,
+		Status:       models.JobStatusCompleted,
+		LogosFound:   logos,
+		ProcessedAt:  time.Now().UTC(),
+		ModelVersion: w.detector.ModelVersion(options),
+	}, nil
+}
+
+// ApplyOptions drops detections below the confidence threshold or outside
+// the requested classes and keeps the most confident MaxDetections. The
+// job's threshold replaces defaultThreshold when set.
+func ApplyOptions(detections []models.LogoDetection, options models.DetectionOptions, defaultThreshold float64) []models.LogoDetection {
+	threshold := defaultThreshold
+	if options.ConfidenceThreshold > 0 {
+		threshold = options.ConfidenceThreshold
+	}
+	classes := make(map[string]bool, len(options.Classes))
+	for _, class := range options.Classes {
+		classes[class] = true
+	}
+
+	kept := make([]models.LogoDetection, 0, len(detections))
+	for _, detection := range detections {
+		if detection.Confidence < threshold || (len(classes) > 0 && !classes[detection.LogoType]) {
+			continue
+		}
+		kept = append(kept, detection)
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Confidence > kept[j].Confidence })
+	if options.MaxDetections > 0 && len(kept) > options.MaxDetections {
+		kept = kept[:options.MaxDetections]
+	}
+	return kept
+}
+
+func failed(jobID uuid.UUID, err error) *models.ProcessingResult {
+	logrus.WithError(err).WithField("job_id", jobID).Error("Detection job failed")
+	return &models.ProcessingResult{
+		JobID:       jobID,
+		Status:      models.JobStatusFailed,
+		LogosFound:  []models.LogoDetection{},
+		ProcessedAt: time.Now().UTC(),
+		Error:       err.Error(),
+	}
+}
+
+// download reads the whole image first, so a connection that drops midway
+// is told apart from an image that does not decode
+func (w *Worker) download(ctx context.Context, key string) ([]byte, error) {
+	reader, err := w.storageClient.DownloadFile(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	return data, nil
+}
+
+func (w *Worker) upload(ctx context.Context, key string, img image.Image, detection models.LogoDetection) error {
+	logo, err := w.extractor.Extract(img, detection)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, logo); err != nil {
+		return fmt.Errorf("failed to encode logo: %w", err)
+	}
+	_, err = w.storageClient.UploadFile(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	return err
+}
@@ -0,0 +1,177 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStorage struct {
+	storage.Client
+	files map[string][]byte
+	// err is returned by every download when set
+	err error
+}
+
+func (s *memoryStorage) UploadFile(ctx context.Context, key string, file io.Reader, size int64) (*storage.UploadOutput, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	s.files[key] = data
+	return &storage.UploadOutput{}, nil
+}
+
+func (s *memoryStorage) DownloadFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	data, ok := s.files[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+type recordingQueue struct {
+	queue.Client
+	results []*models.ProcessingResult
+}
+
+func (q *recordingQueue) PublishResult(result *models.ProcessingResult) error {
+	q.results = append(q.results, result)
+	return nil
+}
+
+type fixedDetector struct {
+	detections []models.LogoDetection
+	options    models.DetectionOptions
+}
+
+func (d *fixedDetector) Detect(ctx context.Context, img image.Image, options models.DetectionOptions) ([]models.LogoDetection, error) {
+	d.options = options
+	return d.detections, nil
+}
+
+func (d *fixedDetector) ModelVersion(options models.DetectionOptions) string {
+	return options.ModelVersion("fixed")
+}
+
+func TestApplyOptions(t *testing.T) {
+	detections := []models.LogoDetection{
+		{LogoType: "nike", Confidence: 0.6},
+		{LogoType: "adidas", Confidence: 0.95},
+		{LogoType: "nike", Confidence: 0.9},
+		{LogoType: "nike", Confidence: 0.85},
+	}
+
+	kept := ApplyOptions(detections, models.DetectionOptions{}, 0.8)
+	require.Equal(t, []float64{0.95, 0.9, 0.85}, confidences(kept))
+
+	kept = ApplyOptions(detections, models.DetectionOptions{ConfidenceThreshold: 0.5, Classes: []string{"nike"}, MaxDetections: 2}, 0.8)
+	require.Equal(t, []float64{0.9, 0.85}, confidences(kept))
+	// The input order is left alone
+	require.Equal(t, 0.6, detections[0].Confidence)
+}
+
+func confidences(detections []models.LogoDetection) []float64 {
+	values := []float64{}
+	for _, detection := range detections {
+		values = append(values, detection.Confidence)
+	}
+	return values
+}
+
+func TestHandle(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 80))
+	img.Set(15, 25, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	files := &memoryStorage{files: map[string][]byte{"original/1/image.png": buf.Bytes()}}
+	queueClient := &recordingQueue{}
+	detector := &fixedDetector{detections: []models.LogoDetection{
+		{LogoType: "nike", Confidence: 0.9, BoundingBox: models.BBox{X: 10, Y: 20, Width: 30, Height: 20}},
+		{LogoType: "nike", Confidence: 0.3, BoundingBox: models.BBox{X: 50, Y: 50, Width: 10, Height: 10}},
+		// Nothing of this one is inside the image, its crop fails
+		{LogoType: "adidas", Confidence: 0.8, BoundingBox: models.BBox{X: 200, Y: 200, Width: 10, Height: 10}},
+	}}
+	w := NewWorker(utils.WorkerConfig{ConfidenceThreshold: 0.5}, detector, CropExtractor{}, files, queueClient)
+
+	job := &models.Job{
+		ID:               uuid.New(),
+		S3Key:            "original/1/image.png",
+		DetectionOptions: &models.DetectionOptions{ModelVariant: "s"},
+	}
+	require.NoError(t, w.Handle(job))
+	require.Len(t, queueClient.results, 1)
+	result := queueClient.results[0]
+
+	require.Equal(t, job.ID, result.JobID)
+	require.Equal(t, models.JobStatusCompleted, result.Status)
+	require.Equal(t, "yolov8s", result.ModelVersion)
+	require.Equal(t, "s", detector.options.ModelVariant)
+	require.WithinDuration(t, time.Now(), result.ProcessedAt, time.Minute)
+	require.Len(t, result.LogosFound, 1)
+
+	logo := result.LogosFound[0]
+	require.Equal(t, job.ID, logo.JobID)
+	require.Equal(t, LogoKey(job.ID, 0), logo.S3Key)
+	require.NotEmpty(t, logo.ID)
+	crop, err := png.Decode(bytes.NewReader(files.files[logo.S3Key]))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 30, 20), crop.Bounds())
+	require.Equal(t, color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(crop.At(5, 5)))
+
+	requireValidResult(t, result)
+}
+
+func TestHandleFailure(t *testing.T) {
+	queueClient := &recordingQueue{}
+	files := &memoryStorage{files: map[string][]byte{"original/1/broken.png": []byte("not an image")}}
+	w := NewWorker(utils.WorkerConfig{}, &fixedDetector{}, CropExtractor{}, files, queueClient)
+
+	job := &models.Job{ID: uuid.New(), S3Key: "original/1/missing.png"}
+	require.NoError(t, w.Handle(job))
+	result := queueClient.results[0]
+
+	require.Equal(t, models.JobStatusFailed, result.Status)
+	require.Contains(t, result.Error, "failed to download image")
+	require.NotNil(t, result.LogosFound)
+	require.Empty(t, result.LogosFound)
+	requireValidResult(t, result)
+
+	job = &models.Job{ID: uuid.New(), S3Key: "original/1/broken.png"}
+	require.NoError(t, w.Handle(job))
+	result = queueClient.results[1]
+	require.Equal(t, models.JobStatusFailed, result.Status)
+	require.Contains(t, result.Error, "failed to decode image")
+
+	// A storage outage is returned so the job is requeued, not failed
+	files.err = errors.New("connection reset")
+	require.Error(t, w.Handle(job))
+	require.Len(t, queueClient.results, 2)
+}
+
+// requireValidResult checks a result against the contract the backend consumes
+func requireValidResult(t *testing.T, result *models.ProcessingResult) {
+	stamped := *result
+	stamped.SchemaVersion = queue.SchemaVersion
+	body, err := json.Marshal(&stamped)
+	require.NoError(t, err)
+	require.NoError(t, queue.Validate(queue.MessageTypeResult, body))
+}
@@ -1,14 +1,29 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Viczdera/ai-logo-preserve/backend/internal/api"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/brands"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/cleanup"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/composition"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/dataset"
 	db "github.com/Viczdera/ai-logo-preserve/backend/internal/db/sqlc"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/extraction"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/models"
 	"github.com/Viczdera/ai-logo-preserve/backend/internal/queue"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/reaper"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/results"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/retention"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/similarity"
 	"github.com/Viczdera/ai-logo-preserve/backend/internal/storage"
+	"github.com/Viczdera/ai-logo-preserve/backend/internal/tiling"
 	"github.com/Viczdera/ai-logo-preserve/backend/internal/utils"
 	_ "github.com/lib/pq"
 )
@@ -52,8 +67,88 @@ func main() {
 	}
 	defer queueClient.Close()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Retry failed storage deletions in the background
+	deleteRetrier := cleanup.NewDeleteRetrier(redisClient, storageClient)
+	go deleteRetrier.Run(ctx)
+	purger := cleanup.NewPurger(queries, storageClient, deleteRetrier)
+
+	// Sweep expired job data on the replica that holds the retention lock
+	if config.Retention.Enabled {
+		sweeper := retention.NewSweeper(config.Retention, queries, purger, queue.NewRedisLock(redisClient, "retention-sweeper"))
+		go sweeper.Run(ctx)
+	}
+
+	// Apply detection results published by the workers
+	brandMatcher := brands.NewMatcher(config.Brands, queries, storageClient)
+	var overlayRenderer *results.Renderer
+	if config.Overlay.Enabled {
+		overlayRenderer = results.NewRenderer(queries, storageClient)
+	}
+	var masker *extraction.Masker
+	if config.Mask.Enabled {
+		masker = extraction.NewMasker(queries, storageClient)
+	}
+	var palettes *extraction.PaletteExtractor
+	if config.Palette.Enabled {
+		palettes = extraction.NewPaletteExtractor(queries, storageClient, config.Palette.Colors)
+	}
+	var vectorizer *extraction.Vectorizer
+	if config.Vector.Enabled {
+		vectorizer = extraction.NewVectorizer(config.Vector, queries, storageClient)
+	}
+	rectifier := extraction.NewRectifier(config.Rectify, queries, storageClient)
+	resultsProcessor := results.NewProcessor(queries, brandMatcher, overlayRenderer, rectifier, masker, palettes, vectorizer, config.Tiling)
+	if err := queueClient.ConsumeResults(resultsProcessor.Handle); err != nil {
+		log.Fatal("Failed to consume detection results:", err)
+	}
+
+	// Render compositions queued through the API
+	if config.Composition.Enabled {
+		compositor := composition.NewCompositor(queries, storageClient)
+		if err := queueClient.ConsumeCompositions(compositor.Handle); err != nil {
+			log.Fatal("Failed to consume compositions:", err)
+		}
+	}
+
+	// Split tiled uploads into tiles
+	var tiler *tiling.Tiler
+	if config.Tiling.Enabled {
+		tiler = tiling.NewTiler(config.Tiling, queries, storageClient, queueClient)
+		if err := queueClient.ConsumeSplits(tiler.Handle); err != nil {
+			log.Fatal("Failed to consume splits:", err)
+		}
+	}
+
+	// Recover jobs that stopped making progress
+	if config.Reaper.Enabled {
+		jobReaper := reaper.NewReaper(config.Reaper, queries, queueClient, queue.NewRedisLock(redisClient, "stuck-job-reaper"))
+		go jobReaper.Run(ctx)
+	}
+
+	// Hash extracted logo crops for near-duplicate search
+	if config.Similarity.IndexEnabled {
+		indexer := similarity.NewIndexer(config.Similarity, queries, storageClient, queue.NewRedisLock(redisClient, "logo-indexer"))
+		go indexer.Run(ctx)
+	}
+
+	// Build dataset exports requested through the API
+	if config.Export.Enabled {
+		exporter := dataset.NewExporter(config.Export, queries, storageClient)
+		go exporter.Run(ctx)
+	}
+
+	// Import labeled dataset archives uploaded through the API
+	if config.Import.Enabled {
+		detectionParams := models.DetectionParams{ModelVersion: config.Detection.ModelVersion}
+		importer := dataset.NewImporter(config.Import, detectionParams, queries, storageClient, queueClient, tiler)
+		go importer.Run(ctx)
+	}
+
 	// Initialize server with all dependencies
-	server := api.NewServer(config, storageClient, queries, redisClient, queueClient)
+	server := api.NewServer(config, storageClient, queries, redisClient, queueClient, purger)
 
 	log.Printf("Starting server on port %s", config.Server.Port)
 	if err := server.Start(); err != nil {
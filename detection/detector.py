@@ -1,7 +1,7 @@
 """YOLOv8 detection wrapper for logo detection."""
 import logging
 from pathlib import Path
-from typing import List, Dict, Any
+from typing import List, Dict, Any, Optional, Iterable
 from ultralytics import YOLO
 import cv2
 import numpy as np
@@ -30,21 +30,33 @@ class LogoDetector:
             logger.error(f"Failed to load YOLOv8 model: {e}")
             raise
 
-    def detect(self, image_path: str) -> List[Dict[str, Any]]:
+    def detect(
+        self,
+        image_path: str,
+        confidence_threshold: Optional[float] = None,
+        classes: Optional[Iterable[str]] = None,
+        max_detections: Optional[int] = None,
+    ) -> List[Dict[str, Any]]:
         """Detect objects/logos in image.
         
         Args:
             image_path: Path to image file
+            confidence_threshold: Overrides the detector's threshold for this image
+            classes: Keeps only detections of these class names
+            max_detections: Keeps at most this many detections, most confident first
             
         Returns:
             List of detections with keys: bbox (dict with x, y, width, height),
             confidence (float), class_name (str), class_id (int)
         """
+        threshold = confidence_threshold or self.confidence_threshold
+        allowed = set(classes) if classes else None
+        
         try:
             logger.info(f"Running detection on {image_path}")
             
             # Run YOLOv8 inference
-            results = self.model(image_path, conf=self.confidence_threshold)
+            results = self.model(image_path, conf=threshold)
             
             detections = []
             
@@ -75,8 +87,11 @@ class LogoDetector:
                     class_id = int(box.cls[0].cpu().numpy())
                     class_name = self.model.names[class_id] if hasattr(self.model, 'names') else "unknown"
                     
+                    if allowed is not None and class_name not in allowed:
+                        continue
+                    
                     # Filter by confidence threshold
-                    if confidence >= self.confidence_threshold:
+                    if confidence >= threshold:
                         detections.append({
                             "bbox": {
                                 "x": x,
@@ -93,6 +108,10 @@ class LogoDetector:
                             f"with confidence {confidence:.2f}"
                         )
             
+            if max_detections:
+                detections.sort(key=lambda d: d["confidence"], reverse=True)
+                detections = detections[:max_detections]
+            
             logger.info(f"Found {len(detections)} detections in {image_path}")
             return detections
             
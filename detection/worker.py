@@ -28,6 +28,11 @@ logging.basicConfig(
 )
 logger = logging.getLogger(__name__)
 
+# Message contract version, see backend/internal/queue/schemas
+SCHEMA_VERSION = 1
+JOB_MESSAGE_TYPE = "detection.job"
+RESULT_MESSAGE_TYPE = "detection.result"
+
 
 class DetectionWorker:
     """Worker that consumes detection jobs from RabbitMQ and processes them."""
@@ -44,11 +49,12 @@ class DetectionWorker:
             endpoint_url=config.aws_endpoint_url,
         )
         
-        # Initialize detector
+        # Initialize detector; other variants are loaded when a job asks for them
         self.detector = LogoDetector(
             model_size=config.model_size,
             confidence_threshold=config.confidence_threshold,
         )
+        self.detectors = {config.model_size: self.detector}
         
         # RabbitMQ connection
         self.connection = None
@@ -105,19 +111,33 @@ class DetectionWorker:
             logger.error(f"Failed to connect to RabbitMQ: {e}")
             raise
 
+    def get_detector(self, model_variant: str) -> LogoDetector:
+        """Return the detector for a YOLOv8 variant, loading it on first use."""
+        if model_variant not in self.detectors:
+            self.detectors[model_variant] = LogoDetector(
+                model_size=model_variant,
+                confidence_threshold=self.config.confidence_threshold,
+            )
+        return self.detectors[model_variant]
+
     def process_job(self, job: Dict[str, Any]) -> Dict[str, Any]:
         """Process a single detection job.
         
         Args:
-            job: Job dictionary with keys: id, s3_key, status, etc.
+            job: Job dictionary with keys: id, s3_key, status, etc. and the
+                optional detection_options overriding the worker's defaults
             
         Returns:
             ProcessingResult dictionary
         """
         job_id = job.get("id")
         s3_key = job.get("s3_key")
+        options = job.get("detection_options") or {}
+        model_variant = options.get("model_variant") or self.config.model_size
+        # Reported with every result so detections can be compared per model
+        model_version = f"yolov8{model_variant}"
         
-        logger.info(f"Processing job {job_id} with S3 key {s3_key}")
+        logger.info(f"Processing job {job_id} with S3 key {s3_key} and options {options}")
         
         temp_image_path = None
         temp_logo_paths = []
@@ -130,7 +150,13 @@ class DetectionWorker:
             logger.info(f"Downloaded image to {temp_image_path}")
             
             # Run detection
-            detections = self.detector.detect(str(temp_image_path))
+            detector = self.get_detector(model_variant)
+            detections = detector.detect(
+                str(temp_image_path),
+                confidence_threshold=options.get("confidence_threshold"),
+                classes=options.get("classes"),
+                max_detections=options.get("max_detections"),
+            )
             logger.info(f"Found {len(detections)} detections")
             
             # Extract and upload logos
@@ -138,7 +164,7 @@ class DetectionWorker:
             for idx, detection in enumerate(detections):
                 try:
                     # Extract logo region
-                    logo_image = self.detector.extract_logo(
+                    logo_image = detector.extract_logo(
                         str(temp_image_path),
                         detection["bbox"],
                     )
@@ -170,11 +196,13 @@ class DetectionWorker:
             
             # Build processing result
             result = {
+                "schema_version": SCHEMA_VERSION,
                 "job_id": job_id,
                 "status": "completed",
                 "logos_found": logos_found,
                 "result_url": "",  # Optional, can be added later
                 "processed_at": datetime.utcnow().isoformat() + "Z",
+                "model_version": model_version,
             }
             
             logger.info(
@@ -187,6 +215,7 @@ class DetectionWorker:
             
             # Return failed result
             return {
+                "schema_version": SCHEMA_VERSION,
                 "job_id": job_id,
                 "status": "failed",
                 "logos_found": [],
@@ -219,6 +248,8 @@ class DetectionWorker:
                 properties=pika.BasicProperties(
                     delivery_mode=2,  # Make message persistent
                     content_type="application/json",
+                    type=RESULT_MESSAGE_TYPE,
+                    headers={"schema_version": SCHEMA_VERSION},
                 ),
             )
             
@@ -240,6 +271,17 @@ class DetectionWorker:
         job = None
         attempts = 0
         max_attempts = self.config.max_retries
+
+        # Reject messages of another contract, retrying cannot fix them
+        headers = properties.headers or {}
+        if (properties.type not in (None, JOB_MESSAGE_TYPE)
+                or headers.get("schema_version", SCHEMA_VERSION) != SCHEMA_VERSION):
+            logger.error(
+                f"Rejecting message of type {properties.type} "
+                f"version {headers.get('schema_version')}"
+            )
+            ch.basic_nack(delivery_tag=method.delivery_tag, requeue=False)
+            return
         
         while attempts < max_attempts:
             try:
@@ -283,6 +325,7 @@ class DetectionWorker:
                     # Final attempt failed - publish failed result
                     if job:
                         result = {
+                            "schema_version": SCHEMA_VERSION,
                             "job_id": job.get("id", "unknown"),
                             "status": "failed",
                             "logos_found": [],